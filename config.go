@@ -37,6 +37,7 @@ type Config struct {
 	CertPath             string `json:"cert_path"`               // 证书文件路径
 	CertContent          string `json:"cert_content"`            // 证书内容
 	AppID                string `json:"app_id"`                  // 应用ID(默认AppID)
+	MerchantAPIv2Key     string `json:"merchant_api_v2_key"`     // 商户APIv2密钥, 仅v2接口(如付款码支付)使用
 }
 
 func LoadConfigFromEnv() (*Config, error) {
@@ -50,6 +51,7 @@ func LoadConfigFromEnv() (*Config, error) {
 		PrivateKeyContent: vos.EnvString("WECHAT_PAY_PRIVATE_KEY_CONTENT"),
 		CertPath:          vos.EnvString("WECHAT_PAY_CERT_PATH"),
 		CertContent:       vos.EnvString("WECHAT_PAY_CERT_CONTENT"),
+		MerchantAPIv2Key:  vos.EnvString("WECHAT_PAY_MERCHANT_APIV2_KEY"),
 	}
 
 	if cfg.PrivateKeyContent == "" && cfg.PrivateKeyPath == "" {
@@ -43,9 +43,9 @@ func main() {
 			fmt.Printf("query balance error: %v\n", err)
 		}
 	} else {
-		fmt.Printf("available amount: %d\n", resp.AvailableAmount)
+		fmt.Printf("available amount: %s\n", resp.AvailableAmount)
 		if resp.PendingAmount != nil {
-			fmt.Printf("pending amount: %d\n", *resp.PendingAmount)
+			fmt.Printf("pending amount: %s\n", resp.PendingAmount)
 		}
 	}
 }
@@ -20,13 +20,20 @@ package vwechatpay
 import (
 	"context"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 
 	"github.com/vogo/vogo/vlog"
 	"github.com/vogo/vogo/vsync/vrun"
 	"github.com/vogo/vwechatpay/vwxplat"
+	"github.com/vogo/vwechatpay/vwxutils"
 	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/auth/validators"
 	"github.com/wechatpay-apiv3/wechatpay-go/core/option"
 	"github.com/wechatpay-apiv3/wechatpay-go/utils"
 )
@@ -39,6 +46,7 @@ type Manager struct {
 	merchantCert       *x509.Certificate
 	PlatManager        *vwxplat.PlatManager
 	Client             *core.Client
+	downloadClient     *core.Client // 下载账单等文件使用, 文件内容不含微信支付签名, 不做应答验签
 }
 
 func NewManager(cfg *Config) (*Manager, error) {
@@ -66,6 +74,7 @@ func NewManager(cfg *Config) (*Manager, error) {
 		return nil, err
 	}
 
+	mgr.downloadClient = core.NewClientWithValidator(mgr.Client, &validators.NullValidator{})
 	mgr.PlatManager = vwxplat.NewPlatManager(mgr.Client, cfg.MerchantAPIv3Key)
 
 	return mgr, nil
@@ -103,6 +112,63 @@ func buildWechatPayClient(cfg *Config, key *rsa.PrivateKey) (*core.Client, error
 	return core.NewClient(ctx, opts...)
 }
 
+// Runner 后台任务运行器, 调用 Stop 后所有基于它的后台任务都会停止
+func (mgr *Manager) Runner() *vrun.Runner {
+	return mgr.runner
+}
+
+// Stop 停止所有后台任务
+func (mgr *Manager) Stop() {
+	mgr.runner.Stop()
+}
+
 func (mgr *Manager) Sign(message string) (string, error) {
 	return utils.SignSHA256WithRSA(message, mgr.merchantPrivateKey)
 }
+
+// Decrypt 使用商户私钥解密微信支付以商户公钥加密的数据, 如特约商户资金账单的加密密钥
+func (mgr *Manager) Decrypt(ciphertext string) (string, error) {
+	return vwxutils.DecryptRSA(ciphertext, mgr.merchantPrivateKey)
+}
+
+// TLSCertificate 商户API证书及私钥, 用于v2接口的双向TLS认证(如撤销订单)
+func (mgr *Manager) TLSCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{mgr.merchantCert.Raw},
+		PrivateKey:  mgr.merchantPrivateKey,
+		Leaf:        mgr.merchantCert,
+	}
+}
+
+// Download 下载账单、电子回单等文件
+// 请求仍使用商户私钥签名, 但文件内容不含微信支付签名, 调用方需根据申请接口返回的摘要校验文件完整性.
+// 调用方负责关闭返回的 io.ReadCloser.
+func (mgr *Manager) Download(ctx context.Context, downloadURL string) (io.ReadCloser, error) {
+	result, err := mgr.downloadClient.Get(ctx, downloadURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Response.Body, nil
+}
+
+// RequestJSON 发送请求并将 JSON 响应解析到 resp, name 用于日志中区分业务
+func (mgr *Manager) RequestJSON(ctx context.Context, name, method, requestURL string, header http.Header, query url.Values, body, resp any) error {
+	result, err := mgr.Client.Request(ctx, method, requestURL, header, query, body, "")
+	if err != nil {
+		return err
+	}
+
+	respBody, err := io.ReadAll(result.Response.Body)
+	if err != nil {
+		return fmt.Errorf("read response body error: %w", err)
+	}
+
+	vlog.Infof("%s response | url: %s | body: %s", name, requestURL, respBody)
+
+	if err := json.Unmarshal(respBody, resp); err != nil {
+		return fmt.Errorf("unmarshal response error: %w", err)
+	}
+
+	return nil
+}
@@ -18,11 +18,11 @@
 package vwxapply4sub
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/vogo/vogo/vlog"
 )
@@ -37,12 +37,12 @@ const (
 
 // ModifySettlementRequest 修改结算账户请求
 type ModifySettlementRequest struct {
-	AccountType   string `json:"account_type"`             // 账户类型，ACCOUNT_TYPE_BUSINESS：对公银行账户，ACCOUNT_TYPE_PRIVATE：经营者个人银行卡
-	AccountBank   string `json:"account_bank"`             // 开户银行，如"工商银行"
-	BankName      string `json:"bank_name,omitempty"`      // 开户银行全称（含支行），如"中国工商银行股份有限公司北京市分行营业部"
-	BankBranchID  string `json:"bank_branch_id,omitempty"` // 开户银行联行号
-	AccountNumber string `json:"account_number"`           // 银行账号，数字，长度遵循系统支持的对公/对私卡号长度标准
-	AccountName   string `json:"account_name,omitempty"`   // 开户名称
+	AccountType   string `json:"account_type"`                                 // 账户类型，ACCOUNT_TYPE_BUSINESS：对公银行账户，ACCOUNT_TYPE_PRIVATE：经营者个人银行卡
+	AccountBank   string `json:"account_bank"`                                 // 开户银行，如"工商银行"
+	BankName      string `json:"bank_name,omitempty"`                          // 开户银行全称（含支行），如"中国工商银行股份有限公司北京市分行营业部"
+	BankBranchID  string `json:"bank_branch_id,omitempty"`                     // 开户银行联行号
+	AccountNumber string `json:"account_number" encryption:"EM_APIV3"`         // 银行账号，数字，长度遵循系统支持的对公/对私卡号长度标准，传明文，请求时自动加密
+	AccountName   string `json:"account_name,omitempty" encryption:"EM_APIV3"` // 开户名称，传明文，请求时自动加密
 }
 
 // ModifySettlementResponse 修改结算账户响应
@@ -71,27 +71,14 @@ func (c *Apply4SubClient) ModifySettlement(ctx context.Context, subMchID string,
 	// 构建URL
 	url := fmt.Sprintf(ModifySettlementURL, subMchID)
 
-	encryptAccountNumer, err := c.mgr.PlatManager.Encrypt(req.AccountNumber)
-	if err != nil {
-		return nil, fmt.Errorf("encrypt account number error: %w", err)
-	}
-	req.AccountNumber = encryptAccountNumer
-
-	encryptAccountName, err := c.mgr.PlatManager.Encrypt(req.AccountName)
-	if err != nil {
-		return nil, fmt.Errorf("encrypt account name error: %w", err)
-	}
-	req.AccountName = encryptAccountName
+	vlog.Infof("modify settlement | sub_mchid: %s | account_type: %s | account_bank: %s", subMchID, req.AccountType, req.AccountBank)
 
-	// 准备请求体
-	reqBody, err := json.Marshal(req)
+	body, header, err := c.mgr.PlatManager.EncryptRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("marshal request body error: %w", err)
+		return nil, fmt.Errorf("encrypt modify settlement request error: %w", err)
 	}
 
-	vlog.Infof("modify settlement | body: %s", reqBody)
-
-	result, err := c.mgr.Client.Post(ctx, url, bytes.NewBuffer(reqBody))
+	result, err := c.mgr.Client.Request(ctx, http.MethodPost, url, header, nil, body, "")
 	if err != nil {
 		return nil, err
 	}
@@ -18,11 +18,11 @@
 package vwxapply4sub
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/vogo/vogo/vlog"
 )
@@ -34,15 +34,14 @@ const (
 
 // SubmitApplyment 提交申请单
 func (c *Apply4SubClient) SubmitApplyment(ctx context.Context, req *ApplymentRequest) (*ApplymentResponse, error) {
-	// 准备请求体
-	reqBody, err := json.Marshal(req)
+	vlog.Infof("submit applyment | business_code: %s", req.BusinessCode)
+
+	body, header, err := c.mgr.PlatManager.EncryptRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("marshal request body error: %w", err)
+		return nil, fmt.Errorf("encrypt applyment request error: %w", err)
 	}
 
-	vlog.Infof("submit applyment | body: %s", reqBody)
-
-	result, err := c.mgr.Client.Post(ctx, ApplymentURL, bytes.NewBuffer(reqBody))
+	result, err := c.mgr.Client.Request(ctx, http.MethodPost, ApplymentURL, header, nil, body, "")
 	if err != nil {
 		return nil, err
 	}
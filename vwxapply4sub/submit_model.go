@@ -48,17 +48,17 @@ type ApplymentResponse struct {
 // ContactInfo 超级管理员信息
 type ContactInfo struct {
 	ContactType                 string `json:"contact_type"`                            // 超级管理员类型，LEGAL：法人，SUPER：经办人
-	ContactName                 string `json:"contact_name"`                            // 超级管理员姓名
+	ContactName                 string `json:"contact_name" encryption:"EM_APIV3"`      // 超级管理员姓名
 	ContactIDDocType            string `json:"contact_id_doc_type,omitempty"`           // 超级管理员证件类型
-	ContactIDNumber             string `json:"contact_id_number"`                       // 超级管理员身份证件号码
+	ContactIDNumber             string `json:"contact_id_number" encryption:"EM_APIV3"` // 超级管理员身份证件号码
 	ContactIDDocCopy            string `json:"contact_id_doc_copy,omitempty"`           // 超级管理员证件正面照片
 	ContactIDDocCopyBack        string `json:"contact_id_doc_copy_back,omitempty"`      // 超级管理员证件反面照片
 	ContactPeriodBegin          string `json:"contact_period_begin,omitempty"`          // 超级管理员证件有效期开始时间
 	ContactPeriodEnd            string `json:"contact_period_end,omitempty"`            // 超级管理员证件有效期结束时间
 	BusinessAuthorizationLetter string `json:"business_authorization_letter,omitempty"` // 业务办理授权函
-	OpenID                      string `json:"openid"`                                  // 超级管理员微信openid
-	MobilePhone                 string `json:"mobile_phone"`                            // 联系手机
-	ContactEmail                string `json:"contact_email"`                           // 联系邮箱
+	OpenID                      string `json:"openid" encryption:"EM_APIV3"`            // 超级管理员微信openid
+	MobilePhone                 string `json:"mobile_phone" encryption:"EM_APIV3"`      // 联系手机
+	ContactEmail                string `json:"contact_email" encryption:"EM_APIV3"`     // 联系邮箱
 }
 
 // SubjectInfo 主体资料
@@ -76,14 +76,14 @@ type SubjectInfo struct {
 
 // UboInfo 最终受益人信息
 type UboInfo struct {
-	UboIDDocType     string `json:"ubo_id_doc_type"`      // 证件类型
-	UboIDDocCopy     string `json:"ubo_id_doc_copy"`      // 证件正面照片
-	UboIDDocCopyBack string `json:"ubo_id_doc_copy_back"` // 证件反面照片
-	UboIDDocName     string `json:"ubo_id_doc_name"`      // 受益人姓名
-	UboIDDocNumber   string `json:"ubo_id_doc_number"`    // 证件号码
-	UboIDDocAddress  string `json:"ubo_id_doc_address"`   // 证件地址
-	UboPeriodBegin   string `json:"ubo_period_begin"`     // 证件有效期开始时间
-	UboPeriodEnd     string `json:"ubo_period_end"`       // 证件有效期结束时间
+	UboIDDocType     string `json:"ubo_id_doc_type"`                          // 证件类型
+	UboIDDocCopy     string `json:"ubo_id_doc_copy"`                          // 证件正面照片
+	UboIDDocCopyBack string `json:"ubo_id_doc_copy_back"`                     // 证件反面照片
+	UboIDDocName     string `json:"ubo_id_doc_name" encryption:"EM_APIV3"`    // 受益人姓名
+	UboIDDocNumber   string `json:"ubo_id_doc_number" encryption:"EM_APIV3"`  // 证件号码
+	UboIDDocAddress  string `json:"ubo_id_doc_address" encryption:"EM_APIV3"` // 证件地址
+	UboPeriodBegin   string `json:"ubo_period_begin"`                         // 证件有效期开始时间
+	UboPeriodEnd     string `json:"ubo_period_end"`                           // 证件有效期结束时间
 }
 
 // BusinessLicenseInfo 营业执照信息
@@ -127,24 +127,24 @@ type IdentityInfo struct {
 
 // IDCardInfo 身份证信息
 type IDCardInfo struct {
-	IDCardCopy      string `json:"id_card_copy"`      // 身份证人像面照片
-	IDCardNational  string `json:"id_card_national"`  // 身份证国徽面照片
-	IDCardName      string `json:"id_card_name"`      // 身份证姓名
-	IDCardNumber    string `json:"id_card_number"`    // 身份证号码
-	IDCardAddress   string `json:"id_card_address"`   // 身份证地址
-	CardPeriodBegin string `json:"card_period_begin"` // 身份证有效期开始时间
-	CardPeriodEnd   string `json:"card_period_end"`   // 身份证有效期结束时间
+	IDCardCopy      string `json:"id_card_copy"`                          // 身份证人像面照片
+	IDCardNational  string `json:"id_card_national"`                      // 身份证国徽面照片
+	IDCardName      string `json:"id_card_name" encryption:"EM_APIV3"`    // 身份证姓名
+	IDCardNumber    string `json:"id_card_number" encryption:"EM_APIV3"`  // 身份证号码
+	IDCardAddress   string `json:"id_card_address" encryption:"EM_APIV3"` // 身份证地址
+	CardPeriodBegin string `json:"card_period_begin"`                     // 身份证有效期开始时间
+	CardPeriodEnd   string `json:"card_period_end"`                       // 身份证有效期结束时间
 }
 
 // IDDocInfo 其他类型证件信息
 type IDDocInfo struct {
-	IDDocCopy      string `json:"id_doc_copy"`      // 证件照片
-	IDDocCopyBack  string `json:"id_doc_copy_back"` // 证件反面照片
-	IDDocName      string `json:"id_doc_name"`      // 证件姓名
-	IDDocNumber    string `json:"id_doc_number"`    // 证件号码
-	IDDocAddress   string `json:"id_doc_address"`   // 证件地址
-	DocPeriodBegin string `json:"doc_period_begin"` // 证件有效期开始时间
-	DocPeriodEnd   string `json:"doc_period_end"`   // 证件有效期结束时间
+	IDDocCopy      string `json:"id_doc_copy"`                          // 证件照片
+	IDDocCopyBack  string `json:"id_doc_copy_back"`                     // 证件反面照片
+	IDDocName      string `json:"id_doc_name" encryption:"EM_APIV3"`    // 证件姓名
+	IDDocNumber    string `json:"id_doc_number" encryption:"EM_APIV3"`  // 证件号码
+	IDDocAddress   string `json:"id_doc_address" encryption:"EM_APIV3"` // 证件地址
+	DocPeriodBegin string `json:"doc_period_begin"`                     // 证件有效期开始时间
+	DocPeriodEnd   string `json:"doc_period_end"`                       // 证件有效期结束时间
 }
 
 // MicroBizInfo 小微商户经营者/法人身份证件
@@ -266,13 +266,13 @@ type SettlementInfo struct {
 
 // BankAccountInfo 结算银行账户
 type BankAccountInfo struct {
-	BankAccountType string `json:"bank_account_type"` // 账户类型，小微商户固定为 BANK_ACCOUNT_TYPE_PERSONAL
-	AccountName     string `json:"account_name"`      // 开户名称
-	AccountBank     string `json:"account_bank"`      // 开户银行
-	BankAddressCode string `json:"bank_address_code"` // 开户银行省市编码
-	BankBranchID    string `json:"bank_branch_id"`    // 开户银行联行号
-	BankName        string `json:"bank_name"`         // 开户银行全称（含支行）
-	AccountNumber   string `json:"account_number"`    // 银行账号
+	BankAccountType string `json:"bank_account_type"`                    // 账户类型，小微商户固定为 BANK_ACCOUNT_TYPE_PERSONAL
+	AccountName     string `json:"account_name" encryption:"EM_APIV3"`   // 开户名称
+	AccountBank     string `json:"account_bank"`                         // 开户银行
+	BankAddressCode string `json:"bank_address_code"`                    // 开户银行省市编码
+	BankBranchID    string `json:"bank_branch_id"`                       // 开户银行联行号
+	BankName        string `json:"bank_name"`                            // 开户银行全称（含支行）
+	AccountNumber   string `json:"account_number" encryption:"EM_APIV3"` // 银行账号
 }
 
 // AdditionInfo 补充材料
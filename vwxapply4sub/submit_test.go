@@ -92,36 +92,15 @@ func TestSubmitApplyment(t *testing.T) {
 	// 准备请求参数
 	ctx := context.Background()
 
-	// 加密敏感信息
-	contactName, err := client.mgr.PlatManager.Encrypt("张三")
-	if err != nil {
-		log.Fatalf("加密联系人姓名失败: %v", err)
-	}
-
-	contactIDNumber, err := client.mgr.PlatManager.Encrypt("110101199003070073")
-	if err != nil {
-		log.Fatalf("加密联系人身份证号失败: %v", err)
-	}
-
-	mobilePhone, err := client.mgr.PlatManager.Encrypt("13900000000")
-	if err != nil {
-		log.Fatalf("加密手机号失败: %v", err)
-	}
-
-	contactEmail, err := client.mgr.PlatManager.Encrypt("test@example.com")
-	if err != nil {
-		log.Fatalf("加密邮箱失败: %v", err)
-	}
-
-	// 构建请求
+	// 构建请求, 敏感信息传明文, 提交时自动使用平台证书加密
 	req := &ApplymentRequest{
 		BusinessCode: "1900000001_10000", // 业务申请编号，服务商自定义
 		ContactInfo: &ContactInfo{
-			ContactName:     contactName,
-			ContactIDNumber: contactIDNumber,
+			ContactName:     "张三",
+			ContactIDNumber: "110101199003070073",
 			OpenID:          "oUpF8uMuAJO_M2pxb1Q9zNjWeS6o", // 超级管理员微信openid
-			MobilePhone:     mobilePhone,
-			ContactEmail:    contactEmail,
+			MobilePhone:     "13900000000",
+			ContactEmail:    "test@example.com",
 		},
 		SubjectInfo: &SubjectInfo{
 			SubjectType: SubjectTypeMicro, // 小微商户固定为 SubjectTypeMicro
@@ -222,56 +201,15 @@ func TestSubmitApplymentWithOnlineInfo(t *testing.T) {
 	// 准备请求参数
 	ctx := context.Background()
 
-	// 加密敏感信息
-	contactName, err := client.mgr.PlatManager.Encrypt("xxx")
-	if err != nil {
-		log.Fatalf("加密联系人姓名失败: %v", err)
-	}
-
-	contactIDNumber, err := client.mgr.PlatManager.Encrypt("xxx")
-	if err != nil {
-		log.Fatalf("加密联系人身份证号失败: %v", err)
-	}
-
-	mobilePhone, err := client.mgr.PlatManager.Encrypt("xxx")
-	if err != nil {
-		log.Fatalf("加密手机号失败: %v", err)
-	}
-
-	contactEmail, err := client.mgr.PlatManager.Encrypt("xxx")
-	if err != nil {
-		log.Fatalf("加密邮箱失败: %v", err)
-	}
-
-	idCardName, err := client.mgr.PlatManager.Encrypt("xxx")
-	if err != nil {
-		log.Fatalf("加密身份证姓名失败: %v", err)
-	}
-
-	idCardNumber, err := client.mgr.PlatManager.Encrypt("xxx")
-	if err != nil {
-		log.Fatalf("加密身份证号码失败: %v", err)
-	}
-
-	accountName, err := client.mgr.PlatManager.Encrypt("xxx")
-	if err != nil {
-		log.Fatalf("加密开户名称失败: %v", err)
-	}
-
-	accountNumber, err := client.mgr.PlatManager.Encrypt("xxx")
-	if err != nil {
-		log.Fatalf("加密银行账号失败: %v", err)
-	}
-
-	// 构建请求
+	// 构建请求, 敏感信息传明文, 提交时自动使用平台证书加密
 	req := &ApplymentRequest{
 		BusinessCode: "1900013511_10000", // 业务申请编号，服务商自定义
 		ContactInfo: &ContactInfo{
-			ContactName:     contactName,
-			ContactIDNumber: contactIDNumber,
+			ContactName:     "xxx",
+			ContactIDNumber: "xxx",
 			OpenID:          "oUpF8uMuAJO_M2pxb1Q9zNjWeS6o", // 超级管理员微信openid
-			MobilePhone:     mobilePhone,
-			ContactEmail:    contactEmail,
+			MobilePhone:     "xxx",
+			ContactEmail:    "xxx",
 		},
 		SubjectInfo: &SubjectInfo{
 			SubjectType: SubjectTypeMicro, // 小微商户固定为 SubjectTypeMicro
@@ -298,8 +236,8 @@ func TestSubmitApplymentWithOnlineInfo(t *testing.T) {
 				IDCardInfo: &IDCardInfo{
 					IDCardCopy:      "xxx", // 身份证人像面照片
 					IDCardNational:  "xxx", // 身份证国徽面照片
-					IDCardName:      idCardName,
-					IDCardNumber:    idCardNumber,
+					IDCardName:      "xxx",
+					IDCardNumber:    "xxx",
 					CardPeriodBegin: "2026-06-06", // 身份证有效期开始时间
 					CardPeriodEnd:   "2026-06-06", // 身份证有效期结束时间
 				},
@@ -321,12 +259,12 @@ func TestSubmitApplymentWithOnlineInfo(t *testing.T) {
 		},
 		BankAccountInfo: &BankAccountInfo{
 			BankAccountType: "BANK_ACCOUNT_TYPE_CORPORATE", // 账户类型
-			AccountName:     accountName,                   // 开户名称
+			AccountName:     "xxx",                         // 开户名称
 			AccountBank:     "工商银行",                        // 开户银行
 			BankAddressCode: "110000",                      // 开户银行省市编码
 			BankBranchID:    "402713354941",                // 开户银行联行号
 			BankName:        "施秉县农村信用合作联社城关信用社",            // 开户银行全称（含支行）
-			AccountNumber:   accountNumber,                 // 银行账号
+			AccountNumber:   "xxx",                         // 银行账号
 		},
 		AdditionInfo: &AdditionInfo{
 			LegalPersonCommitment: "xxx",                                      // 法人开户承诺函
@@ -0,0 +1,145 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbill
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testTradeBill = "\ufeff交易时间,公众账号ID,商户号,特约商户号,设备号,微信订单号,商户订单号,用户标识,交易类型,交易状态,付款银行,货币种类,应结订单金额,代金券金额,微信退款单号,商户退款单号,退款金额,充值券退款金额,退款类型,退款状态,商品名称,商户数据包,手续费,费率,订单金额,申请退款金额,费率备注\r\n" +
+	"`2019-06-11 10:00:00,`wx2421b1c4370ec43b,`10000100,`0,`,`4200000001201906110000000001,`T001,`oUpF8uMuAJO_M2pxb1Q9zNjWeS6o,`JSAPI,`SUCCESS,`OTHERS,`CNY,`12.34,`0.00,`0,`0,`0.00,`0.00,`,`,`商品A,B,`,`0.07000,`0.60%,`12.34,`0.00,`\r\n" +
+	"`2019-06-11 11:00:00,`wx2421b1c4370ec43b,`10000100,`0,`,`4200000001201906110000000001,`T001,`oUpF8uMuAJO_M2pxb1Q9zNjWeS6o,`JSAPI,`REFUND,`OTHERS,`CNY,`0.00,`0.00,`50000000382019061100000000001,`R001,`2.00,`0.00,`ORIGINAL,`SUCCESS,`商品A,`,`-0.01000,`0.60%,`0.00,`2.00,`\r\n" +
+	"总交易单数,应结订单总金额,退款总金额,充值券退款总金额,手续费总金额,订单总金额,申请退款总金额\r\n" +
+	"`2,`12.34,`2.00,`0.00,`0.06000,`12.34,`2.00\r\n"
+
+func TestTradeBillReader(t *testing.T) {
+	reader, err := NewTradeBillReader(strings.NewReader(testTradeBill))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []*TradeBillRow
+	for reader.Next() {
+		rows = append(rows, reader.Row())
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d", len(rows))
+	}
+
+	pay := rows[0]
+	if pay.IsRefund() || pay.OutTradeNo != "T001" || pay.SettlementTotal.Fen() != 1234 || pay.Fee.Fen() != 7 || pay.Body != "商品A,B" {
+		t.Errorf("unexpected pay row: %+v", pay)
+	}
+	if pay.TradeTime.Format("2006-01-02T15:04:05Z07:00") != "2019-06-11T10:00:00+08:00" {
+		t.Errorf("trade time = %s", pay.TradeTime)
+	}
+
+	refund := rows[1]
+	if !refund.IsRefund() || refund.RefundAmount.Fen() != 200 || refund.Fee.Fen() != -1 {
+		t.Errorf("unexpected refund row: %+v", refund)
+	}
+
+	summary := reader.Summary()
+	if summary == nil || summary.TotalCount != 2 || summary.SettlementTotal.Fen() != 1234 || summary.FeeTotal.Fen() != 6 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestReadBill(t *testing.T) {
+	sum := sha1.Sum([]byte(testTradeBill))
+	hashValue := hex.EncodeToString(sum[:])
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte(testTradeBill))
+	_ = gz.Close()
+
+	data, err := readBill(bytes.NewReader(buf.Bytes()), TarTypeGzip, "SHA1", strings.ToUpper(hashValue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != testTradeBill {
+		t.Errorf("unexpected bill content")
+	}
+
+	if _, err := readBill(strings.NewReader(testTradeBill+"x"), TarTypeNone, "SHA1", hashValue); err == nil {
+		t.Errorf("expected hash mismatch")
+	}
+}
+
+func TestOpenBillVerifyAtEOF(t *testing.T) {
+	sum := sha1.Sum([]byte(testTradeBill))
+	hashValue := hex.EncodeToString(sum[:])
+
+	body, err := openBill(io.NopCloser(strings.NewReader(testTradeBill)), TarTypeNone, "SHA1", hashValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewTradeBillReader(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for reader.Next() {
+	}
+	if reader.Err() != nil || reader.Summary() == nil {
+		t.Errorf("unexpected result: %v, %+v", reader.Err(), reader.Summary())
+	}
+
+	// 摘要不一致时读取完明细后返回错误
+	body, _ = openBill(io.NopCloser(strings.NewReader(testTradeBill+"\n")), TarTypeNone, "SHA1", hashValue)
+	reader, _ = NewTradeBillReader(body)
+	for reader.Next() {
+	}
+	if reader.Err() == nil {
+		t.Errorf("expected hash mismatch")
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := map[string]int64{
+		"":        0,
+		"12.34":   1234,
+		"0.01000": 1,
+		"0.00500": 1,
+		"0.00499": 0,
+		"-0.01":   -1,
+		"100":     10000,
+	}
+
+	for s, fen := range tests {
+		m, err := parseAmount(s, "CNY")
+		if err != nil {
+			t.Errorf("parseAmount(%q) error: %v", s, err)
+			continue
+		}
+		if m.Fen() != fen {
+			t.Errorf("parseAmount(%q) = %d, want %d", s, m.Fen(), fen)
+		}
+	}
+}
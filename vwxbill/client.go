@@ -0,0 +1,193 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbill
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	// APIBaseURL 微信支付API地址
+	APIBaseURL = "https://api.mch.weixin.qq.com"
+
+	// billDateLayout 账单日期格式
+	billDateLayout = "2006-01-02"
+)
+
+// TarType 账单压缩类型
+type TarType string
+
+const (
+	TarTypeNone TarType = ""     // 不压缩, 返回数据流
+	TarTypeGzip TarType = "GZIP" // GZIP格式压缩
+)
+
+// BillResponse 申请账单响应
+type BillResponse struct {
+	HashType    string `json:"hash_type"`    // 原始账单(gzip需要解压缩)的摘要算法, 如 SHA1
+	HashValue   string `json:"hash_value"`   // 原始账单(gzip需要解压缩)的摘要值, 用于校验文件的完整性
+	DownloadURL string `json:"download_url"` // 账单下载地址, 30秒内有效
+}
+
+// BillClient 账单客户端
+type BillClient struct {
+	mgr *vwechatpay.Manager
+}
+
+// NewBillClient 创建账单客户端
+func NewBillClient(mgr *vwechatpay.Manager) *BillClient {
+	return &BillClient{
+		mgr: mgr,
+	}
+}
+
+// applyBill 申请账单
+func (c *BillClient) applyBill(ctx context.Context, path string, query url.Values) (*BillResponse, error) {
+	var resp BillResponse
+	if err := c.get(ctx, path, query, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// get 发送GET请求并解析响应
+func (c *BillClient) get(ctx context.Context, path string, query url.Values, resp any) error {
+	reqURL := APIBaseURL + path + "?" + query.Encode()
+
+	vlog.Infof("apply bill | url: %s", reqURL)
+
+	result, err := c.mgr.Client.Get(ctx, reqURL)
+	if err != nil {
+		return err
+	}
+
+	respBody, err := io.ReadAll(result.Response.Body)
+	if err != nil {
+		return fmt.Errorf("read response body error: %w", err)
+	}
+
+	vlog.Infof("apply bill response | body: %s", respBody)
+
+	if err := json.Unmarshal(respBody, resp); err != nil {
+		return fmt.Errorf("unmarshal response error: %w", err)
+	}
+
+	return nil
+}
+
+// DownloadBill 下载账单文件, 返回账单内容的读取器, 使用完毕后需关闭
+// GZIP 压缩的账单边读取边解压, 读取到末尾时使用申请账单返回的摘要校验解压后的原始账单, 摘要不一致时返回错误.
+func (c *BillClient) DownloadBill(ctx context.Context, bill *BillResponse, tarType TarType) (io.ReadCloser, error) {
+	body, err := c.mgr.Download(ctx, bill.DownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("download bill error: %w", err)
+	}
+
+	return openBill(body, tarType, bill.HashType, bill.HashValue)
+}
+
+// billBody 账单内容读取器, 关闭时依次关闭解压读取器及下载响应
+type billBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *billBody) Close() error {
+	var err error
+	for _, c := range b.closers {
+		if closeErr := c.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// openBill 按压缩类型解压账单, 读取到末尾时校验摘要
+func openBill(body io.ReadCloser, tarType TarType, hashType, hashValue string) (io.ReadCloser, error) {
+	b := &billBody{Reader: body, closers: []io.Closer{body}}
+
+	if tarType == TarTypeGzip {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			_ = body.Close()
+			return nil, fmt.Errorf("read gzip bill error: %w", err)
+		}
+		b.Reader = gz
+		b.closers = []io.Closer{gz, body}
+	}
+
+	r, err := vwxutils.NewHashVerifyReader(b.Reader, hashType, hashValue)
+	if err != nil {
+		_ = b.Close()
+		return nil, fmt.Errorf("verify bill error: %w", err)
+	}
+	b.Reader = r
+
+	return b, nil
+}
+
+// readBill 读取全部账单内容, 按压缩类型解压后校验摘要
+func readBill(r io.Reader, tarType TarType, hashType, hashValue string) ([]byte, error) {
+	body, err := openBill(io.NopCloser(r), tarType, hashType, hashValue)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read bill error: %w", err)
+	}
+
+	return data, nil
+}
+
+// validateBillDate 校验账单日期, 仅支持下载三个月内的历史账单, 当日账单次日9点后生成
+func validateBillDate(billDate time.Time) error {
+	if billDate.IsZero() {
+		return fmt.Errorf("bill_date is empty")
+	}
+
+	today := truncateDay(time.Now().In(vwxutils.ChinaLocation))
+	day := truncateDay(billDate.In(vwxutils.ChinaLocation))
+
+	if !day.Before(today) {
+		return fmt.Errorf("bill_date must be before today: %s", day.Format(billDateLayout))
+	}
+
+	if day.Before(today.AddDate(0, -3, 0)) {
+		return fmt.Errorf("bill_date must be within 3 months: %s", day.Format(billDateLayout))
+	}
+
+	return nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
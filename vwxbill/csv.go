@@ -0,0 +1,233 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbill
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	billTimeLayout = "2006-01-02 15:04:05"
+	maxLineBytes   = 1024 * 1024
+)
+
+// billReader 账单CSV读取器
+// 微信支付账单以逗号分隔, 首行为表头, 数据行每个字段以反引号(`)开头,
+// 末尾为汇总信息, 汇总表头以 summaryMarker 开头, 下一行为汇总数据.
+type billReader struct {
+	scanner       *bufio.Scanner
+	summaryMarker string
+	line          int
+	index         map[string]int
+	fields        []string
+	summary       map[string]string
+}
+
+func newBillReader(r io.Reader, summaryMarker string) (*billReader, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+
+	br := &billReader{
+		scanner:       scanner,
+		summaryMarker: summaryMarker,
+	}
+
+	header, ok := br.nextLine()
+	if !ok {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read bill header error: %w", err)
+		}
+		return nil, fmt.Errorf("bill is empty")
+	}
+
+	br.index = make(map[string]int)
+	for i, name := range splitFields(header) {
+		br.index[name] = i
+	}
+
+	return br, nil
+}
+
+// nextLine 读取下一个非空行
+func (r *billReader) nextLine() (string, bool) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimRight(r.scanner.Text(), "\r")
+		if r.line == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) != "" {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// next 读取下一条数据行, 遇到汇总信息或文件结束时返回false
+func (r *billReader) next() (bool, error) {
+	line, ok := r.nextLine()
+	if !ok {
+		return false, r.scanner.Err()
+	}
+
+	if !strings.HasPrefix(line, r.summaryMarker) {
+		r.fields = splitFields(line)
+		return true, nil
+	}
+
+	names := splitFields(line)
+	values, ok := r.nextLine()
+	if !ok {
+		if err := r.scanner.Err(); err != nil {
+			return false, err
+		}
+		return false, fmt.Errorf("bill summary values missing")
+	}
+
+	r.summary = make(map[string]string, len(names))
+	for i, v := range splitFields(values) {
+		if i < len(names) {
+			r.summary[names[i]] = v
+		}
+	}
+
+	// 读取到文件末尾, 流式下载的账单在末尾校验摘要
+	for r.scanner.Scan() {
+	}
+
+	return false, r.scanner.Err()
+}
+
+// get 获取当前数据行指定列的值
+func (r *billReader) get(name string) string {
+	i, ok := r.index[name]
+	if !ok || i >= len(r.fields) {
+		return ""
+	}
+	return r.fields[i]
+}
+
+// splitFields 拆分账单行, 数据行字段以反引号开头, 以 ",`" 拆分以兼容字段内容中的逗号
+func splitFields(line string) []string {
+	if strings.HasPrefix(line, "`") {
+		return strings.Split(line[1:], ",`")
+	}
+
+	fields := strings.Split(line, ",")
+	for i, f := range fields {
+		fields[i] = strings.TrimSpace(f)
+	}
+	return fields
+}
+
+// fieldParser 解析一行中的多个字段, 记录第一个错误
+type fieldParser struct {
+	get      func(string) string
+	currency string
+	err      error
+}
+
+func (p *fieldParser) amount(name string) vwxmoney.Money {
+	m, err := parseAmount(p.get(name), p.currency)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return m
+}
+
+func (p *fieldParser) count(name string) int64 {
+	n, err := parseCount(p.get(name))
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return n
+}
+
+func (p *fieldParser) time(name string) time.Time {
+	t, err := parseTime(p.get(name))
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t
+}
+
+// parseTime 解析账单中的北京时间, 为空时返回零值
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(billTimeLayout, s, vwxutils.ChinaLocation)
+}
+
+// parseAmount 解析账单中以元为单位的金额
+// 手续费等字段带有更多小数位(如 0.01000), 超过两位的部分四舍五入到分.
+func parseAmount(s, currency string) (vwxmoney.Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return vwxmoney.New(0, currency), nil
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	roundUp := false
+	if i := strings.IndexByte(s, '.'); i >= 0 && len(s)-i-1 > 2 {
+		roundUp = s[i+3] >= '5'
+		s = s[:i+3]
+	}
+
+	m, err := vwxmoney.ParseYuanWithCurrency(s, currency)
+	if err != nil {
+		return m, err
+	}
+
+	if roundUp {
+		if m, err = m.Add(vwxmoney.New(1, currency)); err != nil {
+			return m, err
+		}
+	}
+
+	if negative {
+		return vwxmoney.New(0, currency).Sub(m)
+	}
+
+	return m, nil
+}
+
+// parseCount 解析账单中的笔数, 兼容 "20.0" 格式
+func parseCount(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	if i := strings.IndexByte(s, '.'); i >= 0 && strings.Trim(s[i+1:], "0") == "" {
+		s = s[:i]
+	}
+
+	return strconv.ParseInt(s, 10, 64)
+}
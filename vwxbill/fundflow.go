@@ -0,0 +1,392 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbill
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxfund/vwxmchbalance"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	fundFlowBillPath            = "/v3/bill/fundflowbill"
+	subMerchantFundFlowBillPath = "/v3/bill/sub-merchant-fundflowbill"
+
+	// AlgorithmAEADAES256GCM 特约商户资金账单的加密算法
+	AlgorithmAEADAES256GCM = "AEAD_AES_256_GCM"
+)
+
+// FundFlowBillRequest 申请资金账单请求
+type FundFlowBillRequest struct {
+	BillDate    time.Time                 `json:"bill_date"`    // 账单日期, 仅支持三个月内的账单
+	AccountType vwxmchbalance.AccountType `json:"account_type"` // 资金账户类型, 默认为 BASIC
+	TarType     TarType                   `json:"tar_type"`     // 压缩类型, 不填则返回数据流
+}
+
+// ApplyFundFlowBill 申请资金账单
+func (c *BillClient) ApplyFundFlowBill(ctx context.Context, req *FundFlowBillRequest) (*BillResponse, error) {
+	if err := validateBillDate(req.BillDate); err != nil {
+		return nil, err
+	}
+
+	if err := validateAccountType(req.AccountType); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("bill_date", req.BillDate.In(vwxutils.ChinaLocation).Format(billDateLayout))
+
+	if req.AccountType != "" {
+		query.Set("account_type", string(req.AccountType))
+	}
+
+	if req.TarType != TarTypeNone {
+		query.Set("tar_type", string(req.TarType))
+	}
+
+	return c.applyBill(ctx, fundFlowBillPath, query)
+}
+
+// DownloadFundFlowBill 申请并下载资金账单, 返回边下载边解析的账单读取器, 使用完毕后需调用 Close 关闭下载连接
+// 读取完全部明细后校验账单摘要, 校验失败时 Err 返回错误, 已读取的明细应丢弃.
+func (c *BillClient) DownloadFundFlowBill(ctx context.Context, req *FundFlowBillRequest) (*FundFlowBillReader, error) {
+	bill, err := c.ApplyFundFlowBill(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.DownloadBill(ctx, bill, req.TarType)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := NewFundFlowBillReader(body)
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+	reader.closer = body
+
+	return reader, nil
+}
+
+// SubMerchantFundFlowBillRequest 申请特约商户资金账单请求
+type SubMerchantFundFlowBillRequest struct {
+	SubMchID    string                    `json:"sub_mchid"`    // 子商户号
+	BillDate    time.Time                 `json:"bill_date"`    // 账单日期, 仅支持三个月内的账单
+	AccountType vwxmchbalance.AccountType `json:"account_type"` // 资金账户类型
+	TarType     TarType                   `json:"tar_type"`     // 压缩类型, 不填则返回数据流
+}
+
+// EncryptedBill 加密的账单文件信息
+type EncryptedBill struct {
+	BillSequence int    `json:"bill_sequence"` // 账单文件序号
+	DownloadURL  string `json:"download_url"`  // 账单下载地址, 5分钟内有效
+	EncryptKey   string `json:"encrypt_key"`   // 使用商户公钥加密的账单文件密钥
+	HashType     string `json:"hash_type"`     // 原始账单(gzip需要解压缩)的摘要算法
+	HashValue    string `json:"hash_value"`    // 原始账单(gzip需要解压缩)的摘要值
+	Nonce        string `json:"nonce"`         // 账单文件加密使用的随机字符串
+}
+
+// SubMerchantFundFlowBillResponse 申请特约商户资金账单响应, 账单较大时会拆分为多个文件
+type SubMerchantFundFlowBillResponse struct {
+	DownloadBillCount int              `json:"download_bill_count"` // 下载信息总数
+	DownloadBillList  []*EncryptedBill `json:"download_bill_list"`  // 下载信息明细
+}
+
+// ApplySubMerchantFundFlowBill 服务商申请特约商户资金账单
+func (c *BillClient) ApplySubMerchantFundFlowBill(ctx context.Context, req *SubMerchantFundFlowBillRequest) (*SubMerchantFundFlowBillResponse, error) {
+	if req.SubMchID == "" {
+		return nil, fmt.Errorf("sub_mchid is empty")
+	}
+
+	if err := validateBillDate(req.BillDate); err != nil {
+		return nil, err
+	}
+
+	if req.AccountType == "" {
+		return nil, fmt.Errorf("account_type is empty")
+	}
+
+	if err := validateAccountType(req.AccountType); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("sub_mchid", req.SubMchID)
+	query.Set("bill_date", req.BillDate.In(vwxutils.ChinaLocation).Format(billDateLayout))
+	query.Set("account_type", string(req.AccountType))
+	query.Set("algorithm", AlgorithmAEADAES256GCM)
+
+	if req.TarType != TarTypeNone {
+		query.Set("tar_type", string(req.TarType))
+	}
+
+	var resp SubMerchantFundFlowBillResponse
+	if err := c.get(ctx, subMerchantFundFlowBillPath, query, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// DownloadEncryptedBill 下载加密的账单文件
+// 使用商户私钥解密文件密钥, 再使用 AES-256-GCM 解密账单文件, GZIP 压缩的账单自动解压并校验摘要.
+func (c *BillClient) DownloadEncryptedBill(ctx context.Context, bill *EncryptedBill, tarType TarType) ([]byte, error) {
+	key, err := c.mgr.Decrypt(bill.EncryptKey)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt bill key error: %w", err)
+	}
+
+	body, err := c.mgr.Download(ctx, bill.DownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("download bill error: %w", err)
+	}
+	defer body.Close()
+
+	ciphertext, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read bill error: %w", err)
+	}
+
+	plaintext, err := decryptBill(ciphertext, key, bill.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return readBill(bytes.NewReader(plaintext), tarType, bill.HashType, bill.HashValue)
+}
+
+// DownloadSubMerchantFundFlowBill 申请并下载特约商户资金账单
+// 账单拆分为多个文件时按序号依次返回各文件的读取器.
+func (c *BillClient) DownloadSubMerchantFundFlowBill(ctx context.Context, req *SubMerchantFundFlowBillRequest) ([]*FundFlowBillReader, error) {
+	resp, err := c.ApplySubMerchantFundFlowBill(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	readers := make([]*FundFlowBillReader, 0, len(resp.DownloadBillList))
+	for _, bill := range resp.DownloadBillList {
+		data, err := c.DownloadEncryptedBill(ctx, bill, req.TarType)
+		if err != nil {
+			return nil, fmt.Errorf("download bill sequence %d error: %w", bill.BillSequence, err)
+		}
+
+		reader, err := NewFundFlowBillReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("read bill sequence %d error: %w", bill.BillSequence, err)
+		}
+
+		readers = append(readers, reader)
+	}
+
+	return readers, nil
+}
+
+// decryptBill 使用 AES-256-GCM 解密账单文件
+func decryptBill(ciphertext []byte, key, nonce string) ([]byte, error) {
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("new cipher error: %w", err)
+	}
+
+	aesGCM, err := cipher.NewGCMWithNonceSize(block, len(nonce))
+	if err != nil {
+		return nil, fmt.Errorf("new GCM error: %w", err)
+	}
+
+	plaintext, err := aesGCM.Open(nil, []byte(nonce), ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt bill error: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func validateAccountType(accountType vwxmchbalance.AccountType) error {
+	switch accountType {
+	case "", vwxmchbalance.AccountTypeBasic, vwxmchbalance.AccountTypeOperation, vwxmchbalance.AccountTypeFees:
+		return nil
+	default:
+		return fmt.Errorf("invalid account_type: %s", accountType)
+	}
+}
+
+// FundFlowType 收支类型
+type FundFlowType string
+
+const (
+	FundFlowIncome  FundFlowType = "收入"
+	FundFlowExpense FundFlowType = "支出"
+)
+
+// FundFlowRecord 资金账单明细
+type FundFlowRecord struct {
+	AccountingTime time.Time      `json:"accounting_time"` // 记账时间
+	TransactionID  string         `json:"transaction_id"`  // 微信支付业务单号
+	FlowID         string         `json:"flow_id"`         // 资金流水单号
+	BizName        string         `json:"biz_name"`        // 业务名称
+	BizType        string         `json:"biz_type"`        // 业务类型
+	FlowType       FundFlowType   `json:"flow_type"`       // 收支类型
+	Amount         vwxmoney.Money `json:"amount"`          // 收支金额
+	Balance        vwxmoney.Money `json:"balance"`         // 账户结余
+	Applicant      string         `json:"applicant"`       // 资金变更提交申请人
+	Remark         string         `json:"remark"`          // 备注
+	BizVoucherID   string         `json:"biz_voucher_id"`  // 业务凭证号
+}
+
+// SignedAmount 带符号的收支金额, 支出为负数
+func (r *FundFlowRecord) SignedAmount() vwxmoney.Money {
+	if r.FlowType == FundFlowExpense {
+		m, _ := vwxmoney.New(0, r.Amount.Currency()).Sub(r.Amount)
+		return m
+	}
+	return r.Amount
+}
+
+// FundFlowBillSummary 资金账单汇总
+type FundFlowBillSummary struct {
+	TotalCount    int64          `json:"total_count"`    // 资金流水总笔数
+	IncomeCount   int64          `json:"income_count"`   // 收入笔数
+	IncomeAmount  vwxmoney.Money `json:"income_amount"`  // 收入金额
+	ExpenseCount  int64          `json:"expense_count"`  // 支出笔数
+	ExpenseAmount vwxmoney.Money `json:"expense_amount"` // 支出金额
+}
+
+// FundFlowBillReader 资金账单读取器, 逐行解析账单明细, 读取完毕后可获取汇总信息
+type FundFlowBillReader struct {
+	reader  *billReader
+	record  *FundFlowRecord
+	summary *FundFlowBillSummary
+	err     error
+	closer  io.Closer
+}
+
+// NewFundFlowBillReader 创建资金账单读取器, r 为解压(及解密)后的账单内容
+func NewFundFlowBillReader(r io.Reader) (*FundFlowBillReader, error) {
+	reader, err := newBillReader(r, "资金流水总笔数")
+	if err != nil {
+		return nil, err
+	}
+
+	return &FundFlowBillReader{reader: reader}, nil
+}
+
+// Next 读取下一条明细, 没有更多明细或出错时返回false
+func (r *FundFlowBillReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	ok, err := r.reader.next()
+	if err != nil {
+		r.err = fmt.Errorf("read fund flow bill line %d error: %w", r.reader.line, err)
+		return false
+	}
+
+	if !ok {
+		if r.reader.summary != nil {
+			r.summary, r.err = parseFundFlowBillSummary(r.reader.summary)
+		}
+		return false
+	}
+
+	r.record, err = r.parseRecord()
+	if err != nil {
+		r.err = fmt.Errorf("parse fund flow bill line %d error: %w", r.reader.line, err)
+		return false
+	}
+
+	return true
+}
+
+// Record 当前明细
+func (r *FundFlowBillReader) Record() *FundFlowRecord {
+	return r.record
+}
+
+// Err 读取过程中的错误
+func (r *FundFlowBillReader) Err() error {
+	return r.err
+}
+
+// Close 关闭账单读取器, 通过下载方法创建时关闭下载连接
+func (r *FundFlowBillReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// Summary 账单汇总, 读取完全部明细后可用, 账单缺少汇总信息时返回nil
+func (r *FundFlowBillReader) Summary() *FundFlowBillSummary {
+	return r.summary
+}
+
+func (r *FundFlowBillReader) parseRecord() (*FundFlowRecord, error) {
+	get := r.reader.get
+	p := &fieldParser{get: get, currency: vwxmoney.CNY}
+
+	record := &FundFlowRecord{
+		AccountingTime: p.time("记账时间"),
+		TransactionID:  get("微信支付业务单号"),
+		FlowID:         get("资金流水单号"),
+		BizName:        get("业务名称"),
+		BizType:        get("业务类型"),
+		FlowType:       FundFlowType(get("收支类型")),
+		Amount:         p.amount("收支金额（元）"),
+		Balance:        p.amount("账户结余（元）"),
+		Applicant:      get("资金变更提交申请人"),
+		Remark:         get("备注"),
+		BizVoucherID:   get("业务凭证号"),
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return record, nil
+}
+
+func parseFundFlowBillSummary(values map[string]string) (*FundFlowBillSummary, error) {
+	get := func(name string) string { return values[name] }
+	p := &fieldParser{get: get, currency: vwxmoney.CNY}
+
+	summary := &FundFlowBillSummary{
+		TotalCount:    p.count("资金流水总笔数"),
+		IncomeCount:   p.count("收入笔数"),
+		IncomeAmount:  p.amount("收入金额"),
+		ExpenseCount:  p.count("支出笔数"),
+		ExpenseAmount: p.amount("支出金额"),
+	}
+
+	if p.err != nil {
+		return nil, fmt.Errorf("parse fund flow bill summary error: %w", p.err)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbill
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+const testFundFlowBill = "记账时间,微信支付业务单号,资金流水单号,业务名称,业务类型,收支类型,收支金额（元）,账户结余（元）,资金变更提交申请人,备注,业务凭证号\r\n" +
+	"`2019-06-11 10:00:00,`4200000001201906110000000001,`1900000001201906110000000001,`交易,`交易,`收入,`12.34,`112.34,`system,`,`T001\r\n" +
+	"`2019-06-11 11:00:00,`50000000382019061100000000001,`1900000001201906110000000002,`退款,`退款,`支出,`2.00,`110.34,`system,`,`R001\r\n" +
+	"资金流水总笔数,收入笔数,收入金额,支出笔数,支出金额\r\n" +
+	"`2.0,`1.0,`12.34,`1.0,`2.00\r\n"
+
+func TestFundFlowBillReader(t *testing.T) {
+	reader, err := NewFundFlowBillReader(strings.NewReader(testFundFlowBill))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []*FundFlowRecord
+	for reader.Next() {
+		records = append(records, reader.Record())
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("records = %d", len(records))
+	}
+
+	if records[0].FlowType != FundFlowIncome || records[0].Amount.Fen() != 1234 || records[0].Balance.Fen() != 11234 {
+		t.Errorf("unexpected income record: %+v", records[0])
+	}
+
+	if records[1].SignedAmount().Fen() != -200 || records[1].BizVoucherID != "R001" {
+		t.Errorf("unexpected expense record: %+v", records[1])
+	}
+
+	summary := reader.Summary()
+	if summary == nil || summary.TotalCount != 2 || summary.IncomeAmount.Fen() != 1234 || summary.ExpenseCount != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestDecryptBill(t *testing.T) {
+	key := strings.Repeat("k", 32)
+	nonce := "0123456789ab"
+
+	block, _ := aes.NewCipher([]byte(key))
+	aesGCM, _ := cipher.NewGCM(block)
+	ciphertext := aesGCM.Seal(nil, []byte(nonce), []byte(testFundFlowBill), nil)
+
+	plaintext, err := decryptBill(ciphertext, key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha1.Sum(plaintext)
+	data, err := readBill(bytes.NewReader(plaintext), TarTypeNone, "SHA1", hex.EncodeToString(sum[:]))
+	if err != nil || string(data) != testFundFlowBill {
+		t.Errorf("unexpected decrypted bill: %v", err)
+	}
+
+	if _, err := decryptBill(ciphertext, strings.Repeat("x", 32), nonce); err == nil {
+		t.Errorf("expected error with wrong key")
+	}
+}
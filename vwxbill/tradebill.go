@@ -0,0 +1,288 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbill
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const tradeBillPath = "/v3/bill/tradebill"
+
+// BillType 交易账单类型
+type BillType string
+
+const (
+	BillTypeAll     BillType = "ALL"     // 当日所有订单信息(不含充值退款订单)
+	BillTypeSuccess BillType = "SUCCESS" // 当日成功支付的订单(不含充值退款订单)
+	BillTypeRefund  BillType = "REFUND"  // 当日退款订单(不含充值退款订单)
+)
+
+// TradeBillRequest 申请交易账单请求
+type TradeBillRequest struct {
+	BillDate time.Time `json:"bill_date"` // 账单日期, 仅支持三个月内的账单
+	SubMchID string    `json:"sub_mchid"` // 子商户号, 服务商模式下使用, 不填则返回服务商下全部子商户的账单
+	BillType BillType  `json:"bill_type"` // 账单类型, 默认为 ALL
+	TarType  TarType   `json:"tar_type"`  // 压缩类型, 不填则返回数据流
+}
+
+// ApplyTradeBill 申请交易账单
+// 微信支付在次日9点启动生成前一天的对账单, 建议10点后再获取.
+func (c *BillClient) ApplyTradeBill(ctx context.Context, req *TradeBillRequest) (*BillResponse, error) {
+	if err := validateBillDate(req.BillDate); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("bill_date", req.BillDate.In(vwxutils.ChinaLocation).Format(billDateLayout))
+
+	if req.SubMchID != "" {
+		query.Set("sub_mchid", req.SubMchID)
+	}
+
+	if req.BillType != "" {
+		query.Set("bill_type", string(req.BillType))
+	}
+
+	if req.TarType != TarTypeNone {
+		query.Set("tar_type", string(req.TarType))
+	}
+
+	return c.applyBill(ctx, tradeBillPath, query)
+}
+
+// DownloadTradeBill 申请并下载交易账单, 返回边下载边解析的账单读取器, 使用完毕后需调用 Close 关闭下载连接
+// 读取完全部明细后校验账单摘要, 校验失败时 Err 返回错误, 已读取的明细应丢弃.
+func (c *BillClient) DownloadTradeBill(ctx context.Context, req *TradeBillRequest) (*TradeBillReader, error) {
+	bill, err := c.ApplyTradeBill(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.DownloadBill(ctx, bill, req.TarType)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := NewTradeBillReader(body)
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+	reader.closer = body
+
+	return reader, nil
+}
+
+// TradeBillRow 交易账单明细, 不同账单类型仅包含部分字段, 缺失的字段为零值
+type TradeBillRow struct {
+	TradeTime            time.Time      `json:"trade_time"`             // 交易时间
+	AppID                string         `json:"appid"`                  // 公众账号ID
+	MchID                string         `json:"mchid"`                  // 商户号
+	SubMchID             string         `json:"sub_mchid"`              // 特约商户号
+	DeviceInfo           string         `json:"device_info"`            // 设备号
+	TransactionID        string         `json:"transaction_id"`         // 微信订单号
+	OutTradeNo           string         `json:"out_trade_no"`           // 商户订单号
+	OpenID               string         `json:"openid"`                 // 用户标识
+	TradeType            string         `json:"trade_type"`             // 交易类型
+	TradeState           string         `json:"trade_state"`            // 交易状态
+	BankType             string         `json:"bank_type"`              // 付款银行
+	Currency             string         `json:"currency"`               // 货币种类
+	SettlementTotal      vwxmoney.Money `json:"settlement_total"`       // 应结订单金额
+	CouponAmount         vwxmoney.Money `json:"coupon_amount"`          // 代金券金额
+	RefundApplyTime      time.Time      `json:"refund_apply_time"`      // 退款申请时间, 仅退款账单
+	RefundSuccessTime    time.Time      `json:"refund_success_time"`    // 退款成功时间, 仅退款账单
+	RefundID             string         `json:"refund_id"`              // 微信退款单号
+	OutRefundNo          string         `json:"out_refund_no"`          // 商户退款单号
+	RefundAmount         vwxmoney.Money `json:"refund_amount"`          // 退款金额
+	RechargeCouponRefund vwxmoney.Money `json:"recharge_coupon_refund"` // 充值券退款金额
+	RefundType           string         `json:"refund_type"`            // 退款类型
+	RefundStatus         string         `json:"refund_status"`          // 退款状态
+	Body                 string         `json:"body"`                   // 商品名称
+	Attach               string         `json:"attach"`                 // 商户数据包
+	Fee                  vwxmoney.Money `json:"fee"`                    // 手续费
+	FeeRate              string         `json:"fee_rate"`               // 费率, 如 0.60%
+	OrderAmount          vwxmoney.Money `json:"order_amount"`           // 订单金额
+	RefundApplyAmount    vwxmoney.Money `json:"refund_apply_amount"`    // 申请退款金额
+	FeeRateRemark        string         `json:"fee_rate_remark"`        // 费率备注
+}
+
+// IsRefund 是否为退款记录, 全部订单账单中支付记录的退款单号为 0
+func (r *TradeBillRow) IsRefund() bool {
+	return r.TradeState == "REFUND" || (r.RefundID != "" && r.RefundID != "0")
+}
+
+// TradeBillSummary 交易账单汇总
+type TradeBillSummary struct {
+	TotalCount                int64          `json:"total_count"`                  // 总交易单数
+	SettlementTotal           vwxmoney.Money `json:"settlement_total"`             // 应结订单总金额
+	RefundTotal               vwxmoney.Money `json:"refund_total"`                 // 退款总金额
+	RechargeCouponRefundTotal vwxmoney.Money `json:"recharge_coupon_refund_total"` // 充值券退款总金额
+	FeeTotal                  vwxmoney.Money `json:"fee_total"`                    // 手续费总金额
+	OrderTotal                vwxmoney.Money `json:"order_total"`                  // 订单总金额
+	RefundApplyTotal          vwxmoney.Money `json:"refund_apply_total"`           // 申请退款总金额
+}
+
+// TradeBillReader 交易账单读取器, 逐行解析账单明细, 读取完毕后可获取汇总信息
+//
+//	reader, err := vwxbill.NewTradeBillReader(r)
+//	for reader.Next() {
+//	    row := reader.Row()
+//	}
+//	if err := reader.Err(); err != nil {
+//	}
+//	summary := reader.Summary()
+type TradeBillReader struct {
+	reader  *billReader
+	row     *TradeBillRow
+	summary *TradeBillSummary
+	err     error
+	closer  io.Closer
+}
+
+// NewTradeBillReader 创建交易账单读取器, r 为解压后的账单内容
+func NewTradeBillReader(r io.Reader) (*TradeBillReader, error) {
+	reader, err := newBillReader(r, "总交易单数")
+	if err != nil {
+		return nil, err
+	}
+
+	return &TradeBillReader{reader: reader}, nil
+}
+
+// Next 读取下一条明细, 没有更多明细或出错时返回false
+func (r *TradeBillReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	ok, err := r.reader.next()
+	if err != nil {
+		r.err = fmt.Errorf("read trade bill line %d error: %w", r.reader.line, err)
+		return false
+	}
+
+	if !ok {
+		if r.reader.summary != nil {
+			r.summary, r.err = parseTradeBillSummary(r.reader.summary)
+		}
+		return false
+	}
+
+	r.row, err = r.parseRow()
+	if err != nil {
+		r.err = fmt.Errorf("parse trade bill line %d error: %w", r.reader.line, err)
+		return false
+	}
+
+	return true
+}
+
+// Row 当前明细
+func (r *TradeBillReader) Row() *TradeBillRow {
+	return r.row
+}
+
+// Err 读取过程中的错误
+func (r *TradeBillReader) Err() error {
+	return r.err
+}
+
+// Close 关闭账单读取器, 通过下载方法创建时关闭下载连接
+func (r *TradeBillReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// Summary 账单汇总, 读取完全部明细后可用, 账单缺少汇总信息时返回nil
+func (r *TradeBillReader) Summary() *TradeBillSummary {
+	return r.summary
+}
+
+func (r *TradeBillReader) parseRow() (*TradeBillRow, error) {
+	get := r.reader.get
+	currency := get("货币种类")
+	p := &fieldParser{get: get, currency: currency}
+
+	row := &TradeBillRow{
+		TradeTime:            p.time("交易时间"),
+		AppID:                get("公众账号ID"),
+		MchID:                get("商户号"),
+		SubMchID:             get("特约商户号"),
+		DeviceInfo:           get("设备号"),
+		TransactionID:        get("微信订单号"),
+		OutTradeNo:           get("商户订单号"),
+		OpenID:               get("用户标识"),
+		TradeType:            get("交易类型"),
+		TradeState:           get("交易状态"),
+		BankType:             get("付款银行"),
+		Currency:             currency,
+		SettlementTotal:      p.amount("应结订单金额"),
+		CouponAmount:         p.amount("代金券金额"),
+		RefundApplyTime:      p.time("退款申请时间"),
+		RefundSuccessTime:    p.time("退款成功时间"),
+		RefundID:             get("微信退款单号"),
+		OutRefundNo:          get("商户退款单号"),
+		RefundAmount:         p.amount("退款金额"),
+		RechargeCouponRefund: p.amount("充值券退款金额"),
+		RefundType:           get("退款类型"),
+		RefundStatus:         get("退款状态"),
+		Body:                 get("商品名称"),
+		Attach:               get("商户数据包"),
+		Fee:                  p.amount("手续费"),
+		FeeRate:              get("费率"),
+		OrderAmount:          p.amount("订单金额"),
+		RefundApplyAmount:    p.amount("申请退款金额"),
+		FeeRateRemark:        get("费率备注"),
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return row, nil
+}
+
+func parseTradeBillSummary(values map[string]string) (*TradeBillSummary, error) {
+	get := func(name string) string { return values[name] }
+	p := &fieldParser{get: get, currency: vwxmoney.CNY}
+
+	summary := &TradeBillSummary{
+		TotalCount:                p.count("总交易单数"),
+		SettlementTotal:           p.amount("应结订单总金额"),
+		RefundTotal:               p.amount("退款总金额"),
+		RechargeCouponRefundTotal: p.amount("充值券退款总金额"),
+		FeeTotal:                  p.amount("手续费总金额"),
+		OrderTotal:                p.amount("订单总金额"),
+		RefundApplyTotal:          p.amount("申请退款总金额"),
+	}
+
+	if p.err != nil {
+		return nil, fmt.Errorf("parse trade bill summary error: %w", p.err)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,369 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbatchtransfer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxfund/vwxmchtransfer"
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+const (
+	// MaxDetails 单个批次最多的转账明细数
+	MaxDetails = 1000
+	// MaxBatchNameChars 批次名称最大字符数
+	MaxBatchNameChars = 32
+	// MaxRemarkChars 批次备注及转账备注最大字符数
+	MaxRemarkChars = 32
+	// MaxQueryLimit 查询批次时单页最多返回的明细数
+	MaxQueryLimit = 100
+)
+
+const (
+	BatchStatusWaitPay    = "WAIT_PAY"   // 待付款确认, 需要付款出资商户确认
+	BatchStatusAccepted   = "ACCEPTED"   // 已受理, 批次已受理成功, 若发起批量转账的30分钟后, 转账批次单仍处于该状态, 可能原因是商户账户余额不足等
+	BatchStatusProcessing = "PROCESSING" // 转账中, 已开始处理批次内的转账明细单
+	BatchStatusFinished   = "FINISHED"   // 已完成, 批次内的所有转账明细单都已处理完成
+	BatchStatusClosed     = "CLOSED"     // 已关闭, 可查询具体的批次关闭原因确认
+)
+
+// IsBatchStatusFinal 批次是否已完成或已关闭
+func IsBatchStatusFinal(status string) bool {
+	return status == BatchStatusFinished || status == BatchStatusClosed
+}
+
+const (
+	DetailStatusInit       = "INIT"       // 初始态, 系统转账校验中
+	DetailStatusWaitPay    = "WAIT_PAY"   // 待确认, 待商户确认, 符合免密条件时系统会自动扭转为转账中
+	DetailStatusProcessing = "PROCESSING" // 转账中, 正在处理中, 转账结果尚未明确
+	DetailStatusSuccess    = "SUCCESS"    // 转账成功
+	DetailStatusFail       = "FAIL"       // 转账失败, 需要确认失败原因后再决定是否重新发起对该笔明细单的转账
+)
+
+// DetailFilter 查询批次时筛选的明细状态
+type DetailFilter string
+
+const (
+	DetailFilterAll     DetailFilter = "ALL"     // 全部明细
+	DetailFilterSuccess DetailFilter = "SUCCESS" // 转账成功的明细
+	DetailFilterFail    DetailFilter = "FAIL"    // 转账失败的明细
+)
+
+// TransferDetailInput 发起批量转账的转账明细
+type TransferDetailInput struct {
+	OutDetailNo    string         `json:"out_detail_no"`                             // 商家明细单号, 批次内唯一, 只能由数字、大小写字母组成
+	TransferAmount vwxmoney.Money `json:"transfer_amount"`                           // 转账金额
+	TransferRemark string         `json:"transfer_remark"`                           // 转账备注
+	Openid         string         `json:"openid"`                                    // 收款用户OpenID
+	UserName       string         `json:"user_name,omitempty" encryption:"EM_APIV3"` // 收款用户姓名, 传明文, 请求时自动加密
+}
+
+// InitiateBatchRequest 发起批量转账请求
+type InitiateBatchRequest struct {
+	Appid              string                 `json:"appid"`                       // 商户AppID, 为空时使用配置中的默认AppID
+	OutBatchNo         string                 `json:"out_batch_no"`                // 商家批次单号, 只能由数字、大小写字母组成
+	BatchName          string                 `json:"batch_name"`                  // 批次名称
+	BatchRemark        string                 `json:"batch_remark"`                // 批次备注
+	TotalAmount        vwxmoney.Money         `json:"total_amount"`                // 转账总金额, 必须与明细金额之和一致
+	TotalNum           int                    `json:"total_num"`                   // 转账总笔数, 必须与明细数一致
+	TransferDetailList []*TransferDetailInput `json:"transfer_detail_list"`        // 转账明细列表
+	TransferSceneId    string                 `json:"transfer_scene_id,omitempty"` // 转账场景ID, 为空时使用默认场景
+	NotifyUrl          string                 `json:"notify_url,omitempty"`        // 批次完成通知地址
+}
+
+// Validate 校验发起批量转账请求
+func (r *InitiateBatchRequest) Validate() error {
+	if r.OutBatchNo == "" {
+		return fmt.Errorf("out_batch_no is empty")
+	}
+
+	if r.BatchName == "" || utf8.RuneCountInString(r.BatchName) > MaxBatchNameChars {
+		return fmt.Errorf("batch_name must be 1-%d chars: %s", MaxBatchNameChars, r.BatchName)
+	}
+
+	if r.BatchRemark == "" || utf8.RuneCountInString(r.BatchRemark) > MaxRemarkChars {
+		return fmt.Errorf("batch_remark must be 1-%d chars: %s", MaxRemarkChars, r.BatchRemark)
+	}
+
+	if len(r.TransferDetailList) == 0 || len(r.TransferDetailList) > MaxDetails {
+		return fmt.Errorf("transfer details must be 1-%d: %d", MaxDetails, len(r.TransferDetailList))
+	}
+
+	if r.TotalNum != len(r.TransferDetailList) {
+		return fmt.Errorf("total_num %d mismatch with details %d", r.TotalNum, len(r.TransferDetailList))
+	}
+
+	amounts := make([]vwxmoney.Money, 0, len(r.TransferDetailList))
+	outDetailNos := make(map[string]struct{}, len(r.TransferDetailList))
+
+	for _, detail := range r.TransferDetailList {
+		if err := detail.validate(); err != nil {
+			return err
+		}
+
+		if _, ok := outDetailNos[detail.OutDetailNo]; ok {
+			return fmt.Errorf("duplicate out_detail_no: %s", detail.OutDetailNo)
+		}
+		outDetailNos[detail.OutDetailNo] = struct{}{}
+
+		amounts = append(amounts, detail.TransferAmount)
+	}
+
+	total, err := vwxmoney.Sum(amounts...)
+	if err != nil {
+		return err
+	}
+
+	if total != r.TotalAmount {
+		return fmt.Errorf("total_amount %s mismatch with sum of details %s", r.TotalAmount, total)
+	}
+
+	return nil
+}
+
+func (d *TransferDetailInput) validate() error {
+	if d.OutDetailNo == "" {
+		return fmt.Errorf("out_detail_no is empty")
+	}
+
+	if d.Openid == "" {
+		return fmt.Errorf("openid of detail %s is empty", d.OutDetailNo)
+	}
+
+	if !d.TransferAmount.IsPositive() {
+		return fmt.Errorf("transfer_amount of detail %s must be greater than 0: %s", d.OutDetailNo, d.TransferAmount)
+	}
+
+	if d.TransferRemark == "" || utf8.RuneCountInString(d.TransferRemark) > MaxRemarkChars {
+		return fmt.Errorf("transfer_remark of detail %s must be 1-%d chars", d.OutDetailNo, MaxRemarkChars)
+	}
+
+	if d.UserName == "" {
+		cmp, err := d.TransferAmount.Cmp(vwxmchtransfer.UserNameRequiredAmount())
+		if err != nil {
+			return err
+		}
+		if cmp >= 0 {
+			return fmt.Errorf("%w: detail %s, amount %s", vwxmchtransfer.ErrUserNameRequired, d.OutDetailNo, d.TransferAmount)
+		}
+	}
+
+	return nil
+}
+
+// InitiateBatchResponse 发起批量转账响应
+type InitiateBatchResponse struct {
+	OutBatchNo  string `json:"out_batch_no"` // 商家批次单号
+	BatchId     string `json:"batch_id"`     // 微信批次单号
+	CreateTime  string `json:"create_time"`  // 批次创建时间
+	BatchStatus string `json:"batch_status"` // 批次状态
+}
+
+// InitiateBatch 发起批量转账
+// 收款用户姓名传明文, 请求时自动使用平台证书加密并传递 Wechatpay-Serial 头.
+func (c *BatchTransferClient) InitiateBatch(ctx context.Context, req *InitiateBatchRequest) (*InitiateBatchResponse, error) {
+	if req.Appid == "" {
+		r := *req
+		r.Appid = c.mgr.Config.AppID
+		req = &r
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	vlog.Infof("initiate batch transfer | out_batch_no: %s | total_amount: %s | total_num: %d",
+		req.OutBatchNo, req.TotalAmount, req.TotalNum)
+
+	body, header, err := c.mgr.PlatManager.EncryptRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt batch transfer request error: %w", err)
+	}
+
+	var resp InitiateBatchResponse
+	if err := c.request(ctx, http.MethodPost, "/transfer/batches", header, nil, body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// TransferBatch 转账批次单
+type TransferBatch struct {
+	Mchid           string         `json:"mchid"`             // 商户号
+	OutBatchNo      string         `json:"out_batch_no"`      // 商家批次单号
+	BatchId         string         `json:"batch_id"`          // 微信批次单号
+	Appid           string         `json:"appid"`             // 商户AppID
+	BatchStatus     string         `json:"batch_status"`      // 批次状态
+	BatchType       string         `json:"batch_type"`        // 批次类型, API: API方式发起, WEB: 页面方式发起
+	BatchName       string         `json:"batch_name"`        // 批次名称
+	BatchRemark     string         `json:"batch_remark"`      // 批次备注
+	CloseReason     string         `json:"close_reason"`      // 批次关闭原因
+	TotalAmount     vwxmoney.Money `json:"total_amount"`      // 转账总金额
+	TotalNum        int            `json:"total_num"`         // 转账总笔数
+	CreateTime      string         `json:"create_time"`       // 批次创建时间
+	UpdateTime      string         `json:"update_time"`       // 批次更新时间
+	SuccessAmount   vwxmoney.Money `json:"success_amount"`    // 转账成功金额
+	SuccessNum      int            `json:"success_num"`       // 转账成功笔数
+	FailAmount      vwxmoney.Money `json:"fail_amount"`       // 转账失败金额
+	FailNum         int            `json:"fail_num"`          // 转账失败笔数
+	TransferSceneId string         `json:"transfer_scene_id"` // 转账场景ID
+}
+
+// TransferDetailBrief 批次中的转账明细状态
+type TransferDetailBrief struct {
+	DetailId     string `json:"detail_id"`     // 微信明细单号
+	OutDetailNo  string `json:"out_detail_no"` // 商家明细单号
+	DetailStatus string `json:"detail_status"` // 明细状态
+}
+
+// BatchQueryResponse 查询转账批次单响应
+type BatchQueryResponse struct {
+	TransferBatch      *TransferBatch         `json:"transfer_batch"`       // 转账批次单
+	TransferDetailList []*TransferDetailBrief `json:"transfer_detail_list"` // 转账明细单列表
+}
+
+// BatchQuery 查询转账批次单的参数
+type BatchQuery struct {
+	NeedQueryDetail bool         // 是否查询转账明细单
+	Offset          int          // 明细分页起始位置, 从0开始
+	Limit           int          // 明细分页大小, 为0时使用 MaxQueryLimit
+	DetailStatus    DetailFilter // 明细状态, 查询明细时为空则查询全部明细
+}
+
+func (q *BatchQuery) values() (url.Values, error) {
+	query := url.Values{}
+	if q == nil || !q.NeedQueryDetail {
+		query.Set("need_query_detail", "false")
+		return query, nil
+	}
+
+	limit := q.Limit
+	if limit == 0 {
+		limit = MaxQueryLimit
+	}
+
+	if q.Offset < 0 || limit < 0 || limit > MaxQueryLimit {
+		return nil, fmt.Errorf("invalid detail page: offset %d, limit %d", q.Offset, limit)
+	}
+
+	status := q.DetailStatus
+	if status == "" {
+		status = DetailFilterAll
+	}
+
+	query.Set("need_query_detail", "true")
+	query.Set("offset", strconv.Itoa(q.Offset))
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("detail_status", string(status))
+
+	return query, nil
+}
+
+// QueryBatchByOutBatchNo 商家批次单号查询批次单
+// query: 查询参数, 为 nil 时不查询明细
+func (c *BatchTransferClient) QueryBatchByOutBatchNo(ctx context.Context, outBatchNo string, query *BatchQuery) (*BatchQueryResponse, error) {
+	vlog.Infof("query transfer batch | out_batch_no: %s", outBatchNo)
+
+	return c.queryBatch(ctx, "/transfer/batches/out-batch-no/"+url.PathEscape(outBatchNo), query)
+}
+
+// QueryBatchByBatchId 微信批次单号查询批次单
+// query: 查询参数, 为 nil 时不查询明细
+func (c *BatchTransferClient) QueryBatchByBatchId(ctx context.Context, batchId string, query *BatchQuery) (*BatchQueryResponse, error) {
+	vlog.Infof("query transfer batch | batch_id: %s", batchId)
+
+	return c.queryBatch(ctx, "/transfer/batches/batch-id/"+url.PathEscape(batchId), query)
+}
+
+func (c *BatchTransferClient) queryBatch(ctx context.Context, path string, query *BatchQuery) (*BatchQueryResponse, error) {
+	values, err := query.values()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp BatchQueryResponse
+	if err := c.request(ctx, http.MethodGet, path, nil, values, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// TransferDetail 转账明细单
+type TransferDetail struct {
+	Mchid          string         `json:"mchid"`           // 商户号
+	OutBatchNo     string         `json:"out_batch_no"`    // 商家批次单号
+	BatchId        string         `json:"batch_id"`        // 微信批次单号
+	Appid          string         `json:"appid"`           // 商户AppID
+	OutDetailNo    string         `json:"out_detail_no"`   // 商家明细单号
+	DetailId       string         `json:"detail_id"`       // 微信明细单号
+	DetailStatus   string         `json:"detail_status"`   // 明细状态
+	TransferAmount vwxmoney.Money `json:"transfer_amount"` // 转账金额
+	TransferRemark string         `json:"transfer_remark"` // 转账备注
+	FailReason     string         `json:"fail_reason"`     // 明细失败原因
+	Openid         string         `json:"openid"`          // 收款用户OpenID
+	UserName       string         `json:"user_name"`       // 收款用户姓名, 已使用商户私钥解密
+	InitiateTime   string         `json:"initiate_time"`   // 转账发起时间
+	UpdateTime     string         `json:"update_time"`     // 明细更新时间
+}
+
+// QueryDetailByOutNo 商家明细单号查询明细单
+func (c *BatchTransferClient) QueryDetailByOutNo(ctx context.Context, outBatchNo, outDetailNo string) (*TransferDetail, error) {
+	vlog.Infof("query transfer detail | out_batch_no: %s | out_detail_no: %s", outBatchNo, outDetailNo)
+
+	path := fmt.Sprintf("/transfer/batches/out-batch-no/%s/details/out-detail-no/%s",
+		url.PathEscape(outBatchNo), url.PathEscape(outDetailNo))
+
+	return c.queryDetail(ctx, path)
+}
+
+// QueryDetailById 微信明细单号查询明细单
+func (c *BatchTransferClient) QueryDetailById(ctx context.Context, batchId, detailId string) (*TransferDetail, error) {
+	vlog.Infof("query transfer detail | batch_id: %s | detail_id: %s", batchId, detailId)
+
+	path := fmt.Sprintf("/transfer/batches/batch-id/%s/details/detail-id/%s",
+		url.PathEscape(batchId), url.PathEscape(detailId))
+
+	return c.queryDetail(ctx, path)
+}
+
+func (c *BatchTransferClient) queryDetail(ctx context.Context, path string) (*TransferDetail, error) {
+	var detail TransferDetail
+	if err := c.request(ctx, http.MethodGet, path, nil, nil, nil, &detail); err != nil {
+		return nil, err
+	}
+
+	// 收款用户姓名使用商户公钥加密返回
+	if detail.UserName != "" {
+		userName, err := c.mgr.Decrypt(detail.UserName)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt user_name error: %w", err)
+		}
+		detail.UserName = userName
+	}
+
+	return &detail, nil
+}
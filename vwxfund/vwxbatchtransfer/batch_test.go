@@ -0,0 +1,149 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbatchtransfer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vogo/vwechatpay/vwxfund/vwxmchtransfer"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+func newBatchRequest(amounts ...string) *InitiateBatchRequest {
+	req := &InitiateBatchRequest{
+		OutBatchNo:  "B001",
+		BatchName:   "2025年6月活动奖励",
+		BatchRemark: "活动奖励",
+	}
+
+	for i, amount := range amounts {
+		detail := &TransferDetailInput{
+			OutDetailNo:    fmt.Sprintf("D%03d", i+1),
+			TransferAmount: vwxmoney.MustParseYuan(amount),
+			TransferRemark: "活动奖励",
+			Openid:         "openid",
+		}
+		req.TransferDetailList = append(req.TransferDetailList, detail)
+		req.TotalAmount, _ = req.TotalAmount.Add(detail.TransferAmount)
+	}
+	req.TotalNum = len(req.TransferDetailList)
+
+	return req
+}
+
+func TestInitiateBatchRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(r *InitiateBatchRequest)
+		wantErr bool
+	}{
+		{"ok", func(r *InitiateBatchRequest) {}, false},
+		{"empty out_batch_no", func(r *InitiateBatchRequest) { r.OutBatchNo = "" }, true},
+		{"total num mismatch", func(r *InitiateBatchRequest) { r.TotalNum = 3 }, true},
+		{"total amount mismatch", func(r *InitiateBatchRequest) { r.TotalAmount = vwxmoney.Fen(1) }, true},
+		{"duplicate detail", func(r *InitiateBatchRequest) { r.TransferDetailList[1].OutDetailNo = "D001" }, true},
+		{"empty remark", func(r *InitiateBatchRequest) { r.TransferDetailList[0].TransferRemark = "" }, true},
+		{"too many details", func(r *InitiateBatchRequest) {
+			for len(r.TransferDetailList) <= MaxDetails {
+				r.TransferDetailList = append(r.TransferDetailList, r.TransferDetailList[0])
+			}
+			r.TotalNum = len(r.TransferDetailList)
+		}, true},
+	}
+
+	for _, tt := range tests {
+		req := newBatchRequest("1", "2.5")
+		tt.modify(req)
+		if err := req.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	req := newBatchRequest("2000")
+	if err := req.Validate(); !errors.Is(err, vwxmchtransfer.ErrUserNameRequired) {
+		t.Errorf("expected user name required, got: %v", err)
+	}
+
+	req.TransferDetailList[0].UserName = "张三"
+	if err := req.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestBatchQueryValues(t *testing.T) {
+	values, err := (*BatchQuery)(nil).values()
+	if err != nil || values.Encode() != "need_query_detail=false" {
+		t.Errorf("unexpected values: %s, %v", values.Encode(), err)
+	}
+
+	values, err = (&BatchQuery{NeedQueryDetail: true, Offset: 20, DetailStatus: DetailFilterFail}).values()
+	if err != nil || values.Encode() != "detail_status=FAIL&limit=100&need_query_detail=true&offset=20" {
+		t.Errorf("unexpected values: %s, %v", values.Encode(), err)
+	}
+
+	if _, err = (&BatchQuery{NeedQueryDetail: true, Limit: MaxQueryLimit + 1}).values(); err == nil {
+		t.Error("expected limit error")
+	}
+}
+
+func TestParseBatchNotifyBody(t *testing.T) {
+	const apiV3Key = "0123456789abcdef0123456789abcdef"
+
+	plaintext := `{"out_batch_no":"B001","batch_id":"1030000071100999991182020050700019480001","batch_status":"FINISHED","total_num":2,"total_amount":350,"success_amount":100,"success_num":1,"fail_amount":250,"fail_num":1,"mchid":"2483775951","update_time":"2022-12-09T16:49:24+08:00"}`
+
+	block, err := aes.NewCipher([]byte(apiV3Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, associatedData := "0123456789ab", "mch_payment"
+	ciphertext := gcm.Seal(nil, []byte(nonce), []byte(plaintext), []byte(associatedData))
+
+	body, _ := json.Marshal(map[string]any{
+		"id":         "EV-2018022511223320873",
+		"event_type": NotifyEventBatchFinished,
+		"resource": map[string]string{
+			"algorithm":       "AEAD_AES_256_GCM",
+			"ciphertext":      base64.StdEncoding.EncodeToString(ciphertext),
+			"nonce":           nonce,
+			"associated_data": associatedData,
+		},
+	})
+
+	req, n, err := vwxutils.ParseNotifyBody[BatchNotify](apiV3Key, "batch transfer", body)
+	if err != nil {
+		t.Fatalf("ParseNotifyBody() error = %v", err)
+	}
+
+	if req.EventType != NotifyEventBatchFinished || !IsBatchStatusFinal(n.BatchStatus) {
+		t.Errorf("unexpected notify: %s %+v", req.EventType, n)
+	}
+	if n.OutBatchNo != "B001" || n.FailAmount != vwxmoney.Fen(250) || n.SuccessNum != 1 {
+		t.Errorf("unexpected notify: %+v", n)
+	}
+}
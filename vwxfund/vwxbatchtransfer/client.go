@@ -0,0 +1,47 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbatchtransfer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/vogo/vwechatpay"
+)
+
+// APIBaseURL 批量转账到零钱接口地址前缀
+const APIBaseURL = "https://api.mch.weixin.qq.com/v3"
+
+// BatchTransferClient 批量转账到零钱客户端
+// 批量转账到零钱为商家转账的旧版产品, 仍在使用该产品的商户通过此客户端发起和查询批量转账.
+type BatchTransferClient struct {
+	mgr *vwechatpay.Manager
+}
+
+// NewBatchTransferClient 创建批量转账到零钱客户端
+func NewBatchTransferClient(mgr *vwechatpay.Manager) *BatchTransferClient {
+	return &BatchTransferClient{
+		mgr: mgr,
+	}
+}
+
+// request 发送请求并将响应解析到 resp
+func (c *BatchTransferClient) request(ctx context.Context, method, path string, header http.Header, query url.Values, body, resp any) error {
+	return c.mgr.RequestJSON(ctx, "batch transfer", method, APIBaseURL+path, header, query, body, resp)
+}
@@ -0,0 +1,70 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbatchtransfer
+
+import (
+	"context"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+)
+
+const (
+	// NotifyEventBatchFinished 批次完成通知
+	NotifyEventBatchFinished = "MCHTRANSFER.BATCH.FINISHED"
+	// NotifyEventBatchClosed 批次关闭通知
+	NotifyEventBatchClosed = "MCHTRANSFER.BATCH.CLOSED"
+)
+
+// BatchNotify 批次完成或关闭通知
+type BatchNotify struct {
+	Mchid         string         `json:"mchid"`          // 商户号
+	OutBatchNo    string         `json:"out_batch_no"`   // 商家批次单号
+	BatchId       string         `json:"batch_id"`       // 微信批次单号
+	BatchStatus   string         `json:"batch_status"`   // 批次状态
+	TotalNum      int            `json:"total_num"`      // 转账总笔数
+	TotalAmount   vwxmoney.Money `json:"total_amount"`   // 转账总金额
+	SuccessAmount vwxmoney.Money `json:"success_amount"` // 转账成功金额
+	SuccessNum    int            `json:"success_num"`    // 转账成功笔数
+	FailAmount    vwxmoney.Money `json:"fail_amount"`    // 转账失败金额
+	FailNum       int            `json:"fail_num"`       // 转账失败笔数
+	CloseReason   string         `json:"close_reason"`   // 批次关闭原因
+	UpdateTime    string         `json:"update_time"`    // 批次更新时间
+}
+
+// ParseBatchNotify 解析批次完成通知
+// 商户需要验证签名，确保回调通知的真实性
+func (c *BatchTransferClient) ParseBatchNotify(headerFetcher func(string) string, body []byte) (*notify.Request, *BatchNotify, error) {
+	ctx := context.Background()
+
+	// 验证回调通知签名
+	err := c.mgr.PlatManager.VerifyRequestMessage(ctx, headerFetcher, body)
+	if err != nil {
+		vlog.Errorf("validate http message failed | err: %v", err)
+		return nil, nil, err
+	}
+
+	return c.ParseBatchNotifyBody(body)
+}
+
+// ParseBatchNotifyBody 解析批次完成通知体
+func (c *BatchTransferClient) ParseBatchNotifyBody(body []byte) (*notify.Request, *BatchNotify, error) {
+	return vwxutils.ParseNotifyBody[BatchNotify](c.mgr.Config.MerchantAPIv3Key, "batch transfer", body)
+}
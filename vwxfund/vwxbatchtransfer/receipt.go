@@ -0,0 +1,165 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxbatchtransfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	ReceiptStatusAccepted = "ACCEPTED" // 电子回单已受理, 生成中
+	ReceiptStatusFinished = "FINISHED" // 电子回单已生成
+)
+
+// AcceptTypeBatchTransfer 电子回单受理类型: 批量转账明细
+const AcceptTypeBatchTransfer = "BATCH_TRANSFER"
+
+// BatchReceipt 转账批次电子回单
+type BatchReceipt struct {
+	OutBatchNo      string `json:"out_batch_no"`     // 商家批次单号
+	SignatureNo     string `json:"signature_no"`     // 电子回单申请单号
+	SignatureStatus string `json:"signature_status"` // 电子回单状态
+	HashType        string `json:"hash_type"`        // 电子回单文件摘要算法, 如 SHA256
+	HashValue       string `json:"hash_value"`       // 电子回单文件摘要值
+	DownloadURL     string `json:"download_url"`     // 电子回单文件下载地址, 需使用带签名的请求下载
+	CreateTime      string `json:"create_time"`      // 创建时间
+	UpdateTime      string `json:"update_time"`      // 更新时间
+}
+
+// IsFinished 电子回单是否已生成
+func (r *BatchReceipt) IsFinished() bool {
+	return r.SignatureStatus == ReceiptStatusFinished
+}
+
+// DetailReceipt 转账明细电子回单
+type DetailReceipt struct {
+	AcceptType      string `json:"accept_type"`      // 受理类型
+	OutBatchNo      string `json:"out_batch_no"`     // 商家批次单号
+	OutDetailNo     string `json:"out_detail_no"`    // 商家明细单号
+	SignatureNo     string `json:"signature_no"`     // 电子回单受理单号
+	SignatureStatus string `json:"signature_status"` // 电子回单状态
+	HashType        string `json:"hash_type"`        // 电子回单文件摘要算法
+	HashValue       string `json:"hash_value"`       // 电子回单文件摘要值
+	DownloadURL     string `json:"download_url"`     // 电子回单文件下载地址, 需使用带签名的请求下载
+}
+
+// IsFinished 电子回单是否已生成
+func (r *DetailReceipt) IsFinished() bool {
+	return r.SignatureStatus == ReceiptStatusFinished
+}
+
+// ApplyBatchReceipt 申请转账批次电子回单, 仅批次已完成时可申请
+// 电子回单为异步生成, 申请后需调用 QueryBatchReceipt 查询生成状态
+func (c *BatchTransferClient) ApplyBatchReceipt(ctx context.Context, outBatchNo string) (*BatchReceipt, error) {
+	vlog.Infof("apply batch receipt | out_batch_no: %s", outBatchNo)
+
+	var receipt BatchReceipt
+	body := map[string]string{"out_batch_no": outBatchNo}
+	if err := c.request(ctx, http.MethodPost, "/transfer/bill-receipt", nil, nil, body, &receipt); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// QueryBatchReceipt 查询转账批次电子回单
+func (c *BatchTransferClient) QueryBatchReceipt(ctx context.Context, outBatchNo string) (*BatchReceipt, error) {
+	var receipt BatchReceipt
+	if err := c.request(ctx, http.MethodGet, "/transfer/bill-receipt/"+url.PathEscape(outBatchNo), nil, nil, nil, &receipt); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// ApplyDetailReceipt 申请转账明细电子回单, 仅转账成功的明细可申请
+// 电子回单为异步生成, 申请后需调用 QueryDetailReceipt 查询生成状态
+func (c *BatchTransferClient) ApplyDetailReceipt(ctx context.Context, outBatchNo, outDetailNo string) (*DetailReceipt, error) {
+	vlog.Infof("apply detail receipt | out_batch_no: %s | out_detail_no: %s", outBatchNo, outDetailNo)
+
+	var receipt DetailReceipt
+	body := map[string]string{
+		"accept_type":   AcceptTypeBatchTransfer,
+		"out_batch_no":  outBatchNo,
+		"out_detail_no": outDetailNo,
+	}
+	if err := c.request(ctx, http.MethodPost, "/transfer-detail/electronic-receipts", nil, nil, body, &receipt); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// QueryDetailReceipt 查询转账明细电子回单
+func (c *BatchTransferClient) QueryDetailReceipt(ctx context.Context, outBatchNo, outDetailNo string) (*DetailReceipt, error) {
+	query := url.Values{}
+	query.Set("accept_type", AcceptTypeBatchTransfer)
+	query.Set("out_batch_no", outBatchNo)
+	query.Set("out_detail_no", outDetailNo)
+
+	var receipt DetailReceipt
+	if err := c.request(ctx, http.MethodGet, "/transfer-detail/electronic-receipts", nil, query, nil, &receipt); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// DownloadBatchReceipt 下载已生成的转账批次电子回单PDF文件, 并按查询返回的摘要校验文件完整性
+func (c *BatchTransferClient) DownloadBatchReceipt(ctx context.Context, receipt *BatchReceipt) ([]byte, error) {
+	if !receipt.IsFinished() {
+		return nil, fmt.Errorf("batch receipt is not finished: %s", receipt.SignatureStatus)
+	}
+
+	return c.download(ctx, receipt.DownloadURL, receipt.HashType, receipt.HashValue)
+}
+
+// DownloadDetailReceipt 下载已生成的转账明细电子回单PDF文件, 并按查询返回的摘要校验文件完整性
+func (c *BatchTransferClient) DownloadDetailReceipt(ctx context.Context, receipt *DetailReceipt) ([]byte, error) {
+	if !receipt.IsFinished() {
+		return nil, fmt.Errorf("detail receipt is not finished: %s", receipt.SignatureStatus)
+	}
+
+	return c.download(ctx, receipt.DownloadURL, receipt.HashType, receipt.HashValue)
+}
+
+func (c *BatchTransferClient) download(ctx context.Context, downloadURL, hashType, hashValue string) ([]byte, error) {
+	body, err := c.mgr.Download(ctx, downloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("download receipt error: %w", err)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read receipt error: %w", err)
+	}
+
+	if err := vwxutils.VerifyHash(data, hashType, hashValue); err != nil {
+		return nil, fmt.Errorf("verify receipt error: %w", err)
+	}
+
+	return data, nil
+}
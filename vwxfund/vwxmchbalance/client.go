@@ -18,9 +18,16 @@
 package vwxmchbalance
 
 import (
+	"context"
+	"net/http"
+	"net/url"
+
 	"github.com/vogo/vwechatpay"
 )
 
+// APIBaseURL 微信支付API地址
+const APIBaseURL = "https://api.mch.weixin.qq.com"
+
 // MchBalanceClient 商户账户余额查询客户端
 type MchBalanceClient struct {
 	mgr *vwechatpay.Manager
@@ -32,3 +39,8 @@ func NewMchBalanceClient(mgr *vwechatpay.Manager) *MchBalanceClient {
 		mgr: mgr,
 	}
 }
+
+// get 发送查询请求并将响应解析到 resp
+func (c *MchBalanceClient) get(ctx context.Context, path string, query url.Values, resp any) error {
+	return c.mgr.RequestJSON(ctx, "query balance", http.MethodGet, APIBaseURL+path, nil, query, nil, resp)
+}
@@ -0,0 +1,160 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchbalance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+// balanceDateLayout 日终余额日期格式
+const balanceDateLayout = "2006-01-02"
+
+// DayEndBalanceResponse 日终余额响应
+type DayEndBalanceResponse struct {
+	AvailableAmount vwxmoney.Money  `json:"available_amount"`         // 可用余额
+	PendingAmount   *vwxmoney.Money `json:"pending_amount,omitempty"` // 不可用余额
+}
+
+// QueryDayEndBalance 查询账户日终余额
+// accountType: 账户类型, 为空时查询基本账户
+// date: 查询日期, 按北京时间自然日, 仅支持今日之前的日期
+func (c *MchBalanceClient) QueryDayEndBalance(ctx context.Context, accountType AccountType, date time.Time) (*DayEndBalanceResponse, error) {
+	if accountType == "" {
+		accountType = AccountTypeBasic
+	}
+
+	if err := validateAccountType(accountType); err != nil {
+		return nil, err
+	}
+
+	if err := validateBalanceDate(date); err != nil {
+		return nil, err
+	}
+
+	day := date.In(vwxutils.ChinaLocation).Format(balanceDateLayout)
+
+	vlog.Infof("query day end balance | account_type: %s | date: %s", accountType, day)
+
+	query := url.Values{}
+	query.Set("date", day)
+
+	var resp DayEndBalanceResponse
+	if err := c.get(ctx, "/v3/merchant/fund/dayendbalance/"+url.PathEscape(string(accountType)), query, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// SubMchBalanceResponse 子商户账户实时余额响应
+type SubMchBalanceResponse struct {
+	SubMchID        string          `json:"sub_mchid"`                // 子商户号
+	AccountType     AccountType     `json:"account_type"`             // 账户类型
+	AvailableAmount vwxmoney.Money  `json:"available_amount"`         // 可用余额
+	PendingAmount   *vwxmoney.Money `json:"pending_amount,omitempty"` // 不可用余额
+}
+
+// QuerySubMchBalance 服务商查询子商户账户实时余额
+// accountType: 账户类型, 为空时查询基本账户
+func (c *MchBalanceClient) QuerySubMchBalance(ctx context.Context, subMchID string, accountType AccountType) (*SubMchBalanceResponse, error) {
+	if subMchID == "" {
+		return nil, fmt.Errorf("sub_mchid is empty")
+	}
+
+	if err := validateAccountType(accountType); err != nil {
+		return nil, err
+	}
+
+	vlog.Infof("query sub merchant balance | sub_mchid: %s | account_type: %s", subMchID, accountType)
+
+	query := url.Values{}
+	if accountType != "" {
+		query.Set("account_type", string(accountType))
+	}
+
+	var resp SubMchBalanceResponse
+	if err := c.get(ctx, "/v3/ecommerce/fund/balances/"+url.PathEscape(subMchID), query, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// SubMchDayEndBalanceResponse 子商户账户日终余额响应
+type SubMchDayEndBalanceResponse struct {
+	SubMchID        string          `json:"sub_mchid"`                // 子商户号
+	AvailableAmount vwxmoney.Money  `json:"available_amount"`         // 可用余额
+	PendingAmount   *vwxmoney.Money `json:"pending_amount,omitempty"` // 不可用余额
+}
+
+// QuerySubMchDayEndBalance 服务商查询子商户账户日终余额
+// accountType: 账户类型, 为空时查询基本账户
+// date: 查询日期, 按北京时间自然日, 仅支持今日之前的日期
+func (c *MchBalanceClient) QuerySubMchDayEndBalance(ctx context.Context, subMchID string, accountType AccountType, date time.Time) (*SubMchDayEndBalanceResponse, error) {
+	if subMchID == "" {
+		return nil, fmt.Errorf("sub_mchid is empty")
+	}
+
+	if err := validateAccountType(accountType); err != nil {
+		return nil, err
+	}
+
+	if err := validateBalanceDate(date); err != nil {
+		return nil, err
+	}
+
+	day := date.In(vwxutils.ChinaLocation).Format(balanceDateLayout)
+
+	vlog.Infof("query sub merchant day end balance | sub_mchid: %s | account_type: %s | date: %s", subMchID, accountType, day)
+
+	query := url.Values{}
+	query.Set("date", day)
+	if accountType != "" {
+		query.Set("account_type", string(accountType))
+	}
+
+	var resp SubMchDayEndBalanceResponse
+	if err := c.get(ctx, "/v3/ecommerce/fund/enddaybalances/"+url.PathEscape(subMchID), query, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// validateBalanceDate 校验日终余额日期, 仅支持今日之前的日期
+func validateBalanceDate(date time.Time) error {
+	if date.IsZero() {
+		return fmt.Errorf("date is empty")
+	}
+
+	now := time.Now().In(vwxutils.ChinaLocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, vwxutils.ChinaLocation)
+
+	if !date.Before(today) {
+		return fmt.Errorf("date must be before today: %s", date.In(vwxutils.ChinaLocation).Format(balanceDateLayout))
+	}
+
+	return nil
+}
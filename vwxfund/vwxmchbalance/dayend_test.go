@@ -0,0 +1,61 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchbalance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+func TestValidateBalanceDate(t *testing.T) {
+	now := time.Now().In(vwxutils.ChinaLocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, vwxutils.ChinaLocation)
+
+	tests := []struct {
+		name    string
+		date    time.Time
+		wantErr bool
+	}{
+		{"zero", time.Time{}, true},
+		{"yesterday", today.AddDate(0, 0, -1), false},
+		{"last second of yesterday", today.Add(-time.Second), false},
+		{"today", today, true},
+		{"tomorrow", today.AddDate(0, 0, 1), true},
+		{"today in utc", today.Add(time.Hour).UTC(), true},
+	}
+
+	for _, tt := range tests {
+		if err := validateBalanceDate(tt.date); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateBalanceDate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateAccountType(t *testing.T) {
+	for _, accountType := range []AccountType{"", AccountTypeBasic, AccountTypeOperation, AccountTypeFees} {
+		if err := validateAccountType(accountType); err != nil {
+			t.Errorf("validateAccountType(%q) error = %v", accountType, err)
+		}
+	}
+
+	if err := validateAccountType("OTHER"); err == nil {
+		t.Error("expected invalid account type error")
+	}
+}
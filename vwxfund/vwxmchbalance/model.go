@@ -17,6 +17,8 @@
 
 package vwxmchbalance
 
+import "fmt"
+
 // AccountType 账户类型
 type AccountType string
 
@@ -28,3 +30,13 @@ const (
 	// AccountTypeFees 手续费账户
 	AccountTypeFees AccountType = "FEES"
 )
+
+// validateAccountType 校验账户类型, 为空时使用接口默认的账户类型
+func validateAccountType(accountType AccountType) error {
+	switch accountType {
+	case "", AccountTypeBasic, AccountTypeOperation, AccountTypeFees:
+		return nil
+	default:
+		return fmt.Errorf("invalid account_type: %s", accountType)
+	}
+}
@@ -0,0 +1,529 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchbalance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+const (
+	defaultMonitorInterval  = 5 * time.Minute
+	defaultBurnRateWindow   = 6 * time.Hour
+	defaultAlertCooldown    = time.Hour
+	defaultHistoryRetention = 7 * 24 * time.Hour
+)
+
+// AlertLevel 告警级别
+type AlertLevel string
+
+const (
+	AlertLevelWarning  AlertLevel = "WARNING"  // 警告
+	AlertLevelCritical AlertLevel = "CRITICAL" // 严重
+)
+
+// AlertKind 告警类型
+type AlertKind string
+
+const (
+	AlertKindLowBalance AlertKind = "LOW_BALANCE" // 可用余额低于阈值
+	AlertKindDepletion  AlertKind = "DEPLETION"   // 按消耗速度预计即将耗尽
+)
+
+// BalanceThreshold 账户余额告警阈值
+type BalanceThreshold struct {
+	AccountType    AccountType    // 账户类型
+	Warning        vwxmoney.Money // 可用余额低于该值时发出警告, 为零不检查
+	Critical       vwxmoney.Money // 可用余额低于该值时发出严重告警, 为零不检查
+	ForecastWindow time.Duration  // 按消耗速度预计在该时长内耗尽时发出警告, 为零不预测
+}
+
+// BalanceSnapshot 账户余额快照
+type BalanceSnapshot struct {
+	AccountType     AccountType     `json:"account_type"`             // 账户类型
+	AvailableAmount vwxmoney.Money  `json:"available_amount"`         // 可用余额
+	PendingAmount   *vwxmoney.Money `json:"pending_amount,omitempty"` // 不可用余额
+	Time            time.Time       `json:"time"`                     // 查询时间
+}
+
+// BalanceTrend 一段时间内的余额变化趋势
+type BalanceTrend struct {
+	AccountType     AccountType    `json:"account_type"`      // 账户类型
+	Start           time.Time      `json:"start"`             // 首个快照时间
+	End             time.Time      `json:"end"`               // 最后快照时间
+	StartAmount     vwxmoney.Money `json:"start_amount"`      // 首个快照的可用余额
+	EndAmount       vwxmoney.Money `json:"end_amount"`        // 最后快照的可用余额
+	MinAmount       vwxmoney.Money `json:"min_amount"`        // 最低可用余额
+	MaxAmount       vwxmoney.Money `json:"max_amount"`        // 最高可用余额
+	Consumed        vwxmoney.Money `json:"consumed"`          // 消耗金额, 仅累计相邻快照间的减少, 不含充值
+	ConsumedPerHour vwxmoney.Money `json:"consumed_per_hour"` // 每小时消耗金额
+}
+
+// NewBalanceTrend 根据按时间升序排列的余额快照计算变化趋势, 快照为空时返回 nil
+func NewBalanceTrend(snapshots []*BalanceSnapshot) (*BalanceTrend, error) {
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	trend := &BalanceTrend{
+		AccountType:     first.AccountType,
+		Start:           first.Time,
+		End:             last.Time,
+		StartAmount:     first.AvailableAmount,
+		EndAmount:       last.AvailableAmount,
+		MinAmount:       first.AvailableAmount,
+		MaxAmount:       first.AvailableAmount,
+		Consumed:        vwxmoney.New(0, first.AvailableAmount.Currency()),
+		ConsumedPerHour: vwxmoney.New(0, first.AvailableAmount.Currency()),
+	}
+
+	for i := 1; i < len(snapshots); i++ {
+		prev, cur := snapshots[i-1].AvailableAmount, snapshots[i].AvailableAmount
+
+		cmp, err := cur.Cmp(prev)
+		if err != nil {
+			return nil, err
+		}
+
+		if cmp < 0 {
+			decrease, err := prev.Sub(cur)
+			if err != nil {
+				return nil, err
+			}
+			if trend.Consumed, err = trend.Consumed.Add(decrease); err != nil {
+				return nil, err
+			}
+		}
+
+		if cmp, _ = cur.Cmp(trend.MinAmount); cmp < 0 {
+			trend.MinAmount = cur
+		}
+		if cmp, _ = cur.Cmp(trend.MaxAmount); cmp > 0 {
+			trend.MaxAmount = cur
+		}
+	}
+
+	if elapsed := trend.End.Sub(trend.Start); elapsed > 0 {
+		perHour := int64(float64(trend.Consumed.Fen()) * float64(time.Hour) / float64(elapsed))
+		trend.ConsumedPerHour = vwxmoney.New(perHour, trend.Consumed.Currency())
+	}
+
+	return trend, nil
+}
+
+// DepletionTime 按消耗速度预计可用余额耗尽的时间, 无消耗时返回零值
+func (t *BalanceTrend) DepletionTime() time.Time {
+	elapsed := t.End.Sub(t.Start)
+	if elapsed <= 0 || !t.Consumed.IsPositive() {
+		return time.Time{}
+	}
+
+	if !t.EndAmount.IsPositive() {
+		return t.End
+	}
+
+	remaining := float64(t.EndAmount.Fen()) / float64(t.Consumed.Fen()) * float64(elapsed)
+	return t.End.Add(time.Duration(remaining))
+}
+
+// BalanceAlert 余额告警
+type BalanceAlert struct {
+	AccountType     AccountType    `json:"account_type"`      // 账户类型
+	Kind            AlertKind      `json:"kind"`              // 告警类型
+	Level           AlertLevel     `json:"level"`             // 告警级别
+	AvailableAmount vwxmoney.Money `json:"available_amount"`  // 当前可用余额
+	Threshold       vwxmoney.Money `json:"threshold"`         // 触发的余额阈值, 仅余额低于阈值时有值
+	ConsumedPerHour vwxmoney.Money `json:"consumed_per_hour"` // 每小时消耗金额, 仅预计耗尽时有值
+	DepletionTime   time.Time      `json:"depletion_time"`    // 预计耗尽时间, 仅预计耗尽时有值
+	Time            time.Time      `json:"time"`              // 告警时间
+}
+
+// String 告警描述
+func (a *BalanceAlert) String() string {
+	if a.Kind == AlertKindDepletion {
+		return fmt.Sprintf("[%s] %s balance %s will be depleted at %s, consumed %s per hour",
+			a.Level, a.AccountType, a.AvailableAmount, a.DepletionTime.Format(time.RFC3339), a.ConsumedPerHour)
+	}
+
+	return fmt.Sprintf("[%s] %s balance %s is below %s", a.Level, a.AccountType, a.AvailableAmount, a.Threshold)
+}
+
+// BalanceNotifier 余额告警通知, 如发送短信、企业微信消息等
+type BalanceNotifier interface {
+	Notify(ctx context.Context, alert *BalanceAlert) error
+}
+
+// BalanceNotifierFunc 函数形式的余额告警通知
+type BalanceNotifierFunc func(ctx context.Context, alert *BalanceAlert) error
+
+func (f BalanceNotifierFunc) Notify(ctx context.Context, alert *BalanceAlert) error {
+	return f(ctx, alert)
+}
+
+// BalanceHistoryStore 余额快照存储
+type BalanceHistoryStore interface {
+	// Append 保存余额快照
+	Append(ctx context.Context, snapshot *BalanceSnapshot) error
+	// List 按时间升序列出账户在 since 之后的余额快照
+	List(ctx context.Context, accountType AccountType, since time.Time) ([]*BalanceSnapshot, error)
+}
+
+// MemoryBalanceHistoryStore 基于内存的余额快照存储, 仅保留保存期限内的快照
+type MemoryBalanceHistoryStore struct {
+	mu        sync.Mutex
+	retention time.Duration
+	snapshots map[AccountType][]*BalanceSnapshot
+}
+
+// NewMemoryBalanceHistoryStore 创建基于内存的余额快照存储
+// retention: 快照保存期限, 为0时保存7天
+func NewMemoryBalanceHistoryStore(retention time.Duration) *MemoryBalanceHistoryStore {
+	if retention <= 0 {
+		retention = defaultHistoryRetention
+	}
+
+	return &MemoryBalanceHistoryStore{
+		retention: retention,
+		snapshots: make(map[AccountType][]*BalanceSnapshot),
+	}
+}
+
+func (s *MemoryBalanceHistoryStore) Append(_ context.Context, snapshot *BalanceSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap := *snapshot
+	list := append(s.snapshots[snap.AccountType], &snap)
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Time.Before(list[j].Time)
+	})
+
+	// 清理超过保存期限的快照
+	expire := list[len(list)-1].Time.Add(-s.retention)
+	i := sort.Search(len(list), func(i int) bool { return !list[i].Time.Before(expire) })
+	s.snapshots[snap.AccountType] = list[i:]
+
+	return nil
+}
+
+func (s *MemoryBalanceHistoryStore) List(_ context.Context, accountType AccountType, since time.Time) ([]*BalanceSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []*BalanceSnapshot
+	for _, snapshot := range s.snapshots[accountType] {
+		if snapshot.Time.Before(since) {
+			continue
+		}
+		snap := *snapshot
+		list = append(list, &snap)
+	}
+
+	return list, nil
+}
+
+// balanceQuerier 实时余额查询接口, 由 MchBalanceClient 实现
+type balanceQuerier interface {
+	QueryBalance(ctx context.Context, accountType AccountType) (*BalanceQueryResponse, error)
+}
+
+// alertState 已发出的告警, 用于抑制重复告警
+type alertState struct {
+	level AlertLevel
+	time  time.Time
+}
+
+// BalanceMonitor 账户余额监控
+// 定时查询配置的账户实时余额并保存快照, 可用余额低于阈值或按消耗速度预计即将耗尽时发出告警,
+// 同一账户同类告警在冷却时间内仅在级别升高时重复发出, 恢复正常后重置.
+type BalanceMonitor struct {
+	client         balanceQuerier
+	runner         *vrun.Runner
+	notifier       BalanceNotifier
+	thresholds     []*BalanceThreshold
+	store          BalanceHistoryStore
+	interval       time.Duration
+	burnRateWindow time.Duration
+	alertCooldown  time.Duration
+	now            func() time.Time
+	startOnce      sync.Once
+	mu             sync.Mutex
+	alerts         map[string]*alertState
+}
+
+// BalanceMonitorOption 余额监控可选项
+type BalanceMonitorOption func(*BalanceMonitor)
+
+// WithBalanceHistoryStore 设置余额快照存储, 默认使用保存7天的内存存储
+func WithBalanceHistoryStore(store BalanceHistoryStore) BalanceMonitorOption {
+	return func(m *BalanceMonitor) { m.store = store }
+}
+
+// WithMonitorInterval 设置查询余额的间隔, 默认5分钟
+func WithMonitorInterval(interval time.Duration) BalanceMonitorOption {
+	return func(m *BalanceMonitor) { m.interval = interval }
+}
+
+// WithBurnRateWindow 设置计算消耗速度使用的快照时间范围, 默认6小时
+func WithBurnRateWindow(window time.Duration) BalanceMonitorOption {
+	return func(m *BalanceMonitor) { m.burnRateWindow = window }
+}
+
+// WithAlertCooldown 设置重复告警的冷却时间, 默认1小时
+func WithAlertCooldown(cooldown time.Duration) BalanceMonitorOption {
+	return func(m *BalanceMonitor) { m.alertCooldown = cooldown }
+}
+
+// NewBalanceMonitor 创建账户余额监控, 后台任务运行在 Manager 的 Runner 上, Manager 停止时随之停止
+// notifier 不能为空, 每个账户类型只能配置一个告警阈值.
+func NewBalanceMonitor(client *MchBalanceClient, notifier BalanceNotifier, thresholds []*BalanceThreshold, opts ...BalanceMonitorOption) (*BalanceMonitor, error) {
+	return newBalanceMonitor(client, client.mgr.Runner().NewChild(), notifier, thresholds, opts...)
+}
+
+func newBalanceMonitor(client balanceQuerier, runner *vrun.Runner, notifier BalanceNotifier, thresholds []*BalanceThreshold, opts ...BalanceMonitorOption) (*BalanceMonitor, error) {
+	if notifier == nil {
+		return nil, fmt.Errorf("balance notifier is nil")
+	}
+
+	if err := validateThresholds(thresholds); err != nil {
+		return nil, err
+	}
+
+	m := &BalanceMonitor{
+		client:         client,
+		runner:         runner,
+		notifier:       notifier,
+		thresholds:     thresholds,
+		store:          NewMemoryBalanceHistoryStore(defaultHistoryRetention),
+		interval:       defaultMonitorInterval,
+		burnRateWindow: defaultBurnRateWindow,
+		alertCooldown:  defaultAlertCooldown,
+		now:            time.Now,
+		alerts:         make(map[string]*alertState),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m, nil
+}
+
+// validateThresholds 校验告警阈值, 告警状态按账户类型区分, 每个账户类型只能配置一个阈值
+func validateThresholds(thresholds []*BalanceThreshold) error {
+	seen := make(map[AccountType]bool, len(thresholds))
+	for _, threshold := range thresholds {
+		if threshold == nil {
+			return fmt.Errorf("balance threshold is nil")
+		}
+
+		if threshold.AccountType == "" {
+			return fmt.Errorf("account_type of balance threshold is empty")
+		}
+
+		if err := validateAccountType(threshold.AccountType); err != nil {
+			return err
+		}
+
+		if seen[threshold.AccountType] {
+			return fmt.Errorf("duplicate balance threshold for account_type: %s", threshold.AccountType)
+		}
+		seen[threshold.AccountType] = true
+	}
+
+	return nil
+}
+
+// Start 启动后台定时查询
+func (m *BalanceMonitor) Start() {
+	m.startOnce.Do(func() {
+		m.runner.Interval(m.poll, m.interval)
+	})
+}
+
+// Stop 停止后台定时查询
+func (m *BalanceMonitor) Stop() {
+	m.runner.Stop()
+}
+
+// History 按时间升序列出账户在 since 之后的余额快照
+func (m *BalanceMonitor) History(ctx context.Context, accountType AccountType, since time.Time) ([]*BalanceSnapshot, error) {
+	return m.store.List(ctx, accountType, since)
+}
+
+// Trend 计算账户在 since 之后的余额变化趋势, 无快照时返回 nil
+func (m *BalanceMonitor) Trend(ctx context.Context, accountType AccountType, since time.Time) (*BalanceTrend, error) {
+	snapshots, err := m.store.List(ctx, accountType, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBalanceTrend(snapshots)
+}
+
+func (m *BalanceMonitor) poll() {
+	ctx := context.Background()
+
+	for _, threshold := range m.thresholds {
+		select {
+		case <-m.runner.C:
+			return
+		default:
+			if err := m.check(ctx, threshold); err != nil {
+				vlog.Errorf("check balance error | account_type: %s | err: %v", threshold.AccountType, err)
+			}
+		}
+	}
+}
+
+// check 查询余额并保存快照, 按阈值和消耗速度评估是否告警
+func (m *BalanceMonitor) check(ctx context.Context, threshold *BalanceThreshold) error {
+	balance, err := m.client.QueryBalance(ctx, threshold.AccountType)
+	if err != nil {
+		return err
+	}
+
+	now := m.now()
+	snapshot := &BalanceSnapshot{
+		AccountType:     threshold.AccountType,
+		AvailableAmount: balance.AvailableAmount,
+		PendingAmount:   balance.PendingAmount,
+		Time:            now,
+	}
+
+	if err := m.store.Append(ctx, snapshot); err != nil {
+		return fmt.Errorf("save balance snapshot error: %w", err)
+	}
+
+	alert, err := lowBalanceAlert(threshold, snapshot)
+	if err != nil {
+		return err
+	}
+	m.emit(ctx, threshold.AccountType, AlertKindLowBalance, alert)
+
+	if threshold.ForecastWindow <= 0 {
+		return nil
+	}
+
+	trend, err := m.Trend(ctx, threshold.AccountType, now.Add(-m.burnRateWindow))
+	if err != nil {
+		return err
+	}
+	m.emit(ctx, threshold.AccountType, AlertKindDepletion, depletionAlert(threshold, trend, now))
+
+	return nil
+}
+
+// emit 发出告警, 冷却时间内同级或更低级别的告警不重复发出, alert 为 nil 表示已恢复正常
+func (m *BalanceMonitor) emit(ctx context.Context, accountType AccountType, kind AlertKind, alert *BalanceAlert) {
+	key := string(accountType) + "/" + string(kind)
+
+	m.mu.Lock()
+	last := m.alerts[key]
+	if alert == nil {
+		delete(m.alerts, key)
+		m.mu.Unlock()
+		return
+	}
+
+	escalated := last != nil && last.level == AlertLevelWarning && alert.Level == AlertLevelCritical
+	if last != nil && !escalated && alert.Time.Sub(last.time) < m.alertCooldown {
+		m.mu.Unlock()
+		return
+	}
+
+	m.alerts[key] = &alertState{level: alert.Level, time: alert.Time}
+	m.mu.Unlock()
+
+	vlog.Warnf("balance alert | %s", alert)
+
+	if err := m.notifier.Notify(ctx, alert); err != nil {
+		vlog.Errorf("notify balance alert error | account_type: %s | kind: %s | err: %v", accountType, kind, err)
+
+		// 通知失败时下次检查重新发出
+		m.mu.Lock()
+		delete(m.alerts, key)
+		m.mu.Unlock()
+	}
+}
+
+// lowBalanceAlert 可用余额低于阈值时返回告警, 严重阈值优先
+func lowBalanceAlert(threshold *BalanceThreshold, snapshot *BalanceSnapshot) (*BalanceAlert, error) {
+	levels := []struct {
+		level AlertLevel
+		limit vwxmoney.Money
+	}{
+		{AlertLevelCritical, threshold.Critical},
+		{AlertLevelWarning, threshold.Warning},
+	}
+
+	for _, l := range levels {
+		if !l.limit.IsPositive() {
+			continue
+		}
+
+		cmp, err := snapshot.AvailableAmount.Cmp(l.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		if cmp < 0 {
+			return &BalanceAlert{
+				AccountType:     snapshot.AccountType,
+				Kind:            AlertKindLowBalance,
+				Level:           l.level,
+				AvailableAmount: snapshot.AvailableAmount,
+				Threshold:       l.limit,
+				Time:            snapshot.Time,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// depletionAlert 按消耗速度预计在预测时长内耗尽时返回告警
+func depletionAlert(threshold *BalanceThreshold, trend *BalanceTrend, now time.Time) *BalanceAlert {
+	if trend == nil {
+		return nil
+	}
+
+	depletion := trend.DepletionTime()
+	if depletion.IsZero() || depletion.After(now.Add(threshold.ForecastWindow)) {
+		return nil
+	}
+
+	return &BalanceAlert{
+		AccountType:     trend.AccountType,
+		Kind:            AlertKindDepletion,
+		Level:           AlertLevelWarning,
+		AvailableAmount: trend.EndAmount,
+		ConsumedPerHour: trend.ConsumedPerHour,
+		DepletionTime:   depletion,
+		Time:            now,
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchbalance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+type fakeBalanceQuerier struct {
+	balances map[AccountType]vwxmoney.Money
+}
+
+func (c *fakeBalanceQuerier) QueryBalance(_ context.Context, accountType AccountType) (*BalanceQueryResponse, error) {
+	return &BalanceQueryResponse{AvailableAmount: c.balances[accountType]}, nil
+}
+
+func TestBalanceMonitor(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeBalanceQuerier{balances: map[AccountType]vwxmoney.Money{
+		AccountTypeOperation: vwxmoney.MustParseYuan("10000"),
+	}}
+
+	var alerts []*BalanceAlert
+	notifier := BalanceNotifierFunc(func(_ context.Context, alert *BalanceAlert) error {
+		alerts = append(alerts, alert)
+		return nil
+	})
+
+	now := time.Date(2025, 6, 1, 8, 0, 0, 0, vwxutils.ChinaLocation)
+	m, err := newBalanceMonitor(client, vrun.New(), notifier, []*BalanceThreshold{{
+		AccountType:    AccountTypeOperation,
+		Warning:        vwxmoney.MustParseYuan("5000"),
+		Critical:       vwxmoney.MustParseYuan("1000"),
+		ForecastWindow: 4 * time.Hour,
+	}}, WithAlertCooldown(3*time.Hour))
+	if err != nil {
+		t.Fatalf("new balance monitor error: %v", err)
+	}
+	m.now = func() time.Time { return now }
+
+	step := func(balance string) {
+		client.balances[AccountTypeOperation] = vwxmoney.MustParseYuan(balance)
+		m.poll()
+		now = now.Add(time.Hour)
+	}
+
+	step("10000")
+	step("9000")
+	if len(alerts) != 0 {
+		t.Fatalf("unexpected alerts: %v", alerts)
+	}
+
+	// 充值不计入消耗, 3小时消耗10000元, 余额4000元预计2小时内耗尽, 同时低于警告阈值
+	step("13000")
+	step("4000")
+	if len(alerts) != 2 || alerts[0].Kind != AlertKindLowBalance || alerts[1].Kind != AlertKindDepletion {
+		t.Fatalf("unexpected alerts: %v", alerts)
+	}
+
+	// 冷却时间内同级别告警不重复发出, 升级为严重时立即发出
+	step("3500")
+	if len(alerts) != 2 {
+		t.Fatalf("alerts should be suppressed: %v", alerts)
+	}
+
+	step("800")
+	if len(alerts) != 3 || alerts[2].Level != AlertLevelCritical {
+		t.Fatalf("expected critical alert: %v", alerts)
+	}
+
+	trend, err := m.Trend(ctx, AccountTypeOperation, time.Time{})
+	if err != nil {
+		t.Fatalf("trend error: %v", err)
+	}
+
+	if trend.MaxAmount != vwxmoney.MustParseYuan("13000") || trend.Consumed != vwxmoney.MustParseYuan("13200") {
+		t.Errorf("unexpected trend: %+v", trend)
+	}
+
+	if trend.ConsumedPerHour != vwxmoney.MustParseYuan("2640") {
+		t.Errorf("unexpected consumed per hour: %s", trend.ConsumedPerHour)
+	}
+}
+
+func TestNewBalanceMonitorValidate(t *testing.T) {
+	client := &fakeBalanceQuerier{balances: map[AccountType]vwxmoney.Money{}}
+	notifier := BalanceNotifierFunc(func(context.Context, *BalanceAlert) error { return nil })
+
+	if _, err := newBalanceMonitor(client, vrun.New(), nil, nil); err == nil {
+		t.Error("nil notifier should be rejected")
+	}
+
+	thresholds := []*BalanceThreshold{
+		{AccountType: AccountTypeOperation, Warning: vwxmoney.MustParseYuan("5000")},
+		{AccountType: AccountTypeOperation, Critical: vwxmoney.MustParseYuan("1000")},
+	}
+	if _, err := newBalanceMonitor(client, vrun.New(), notifier, thresholds); err == nil {
+		t.Error("duplicate account type should be rejected")
+	}
+
+	if _, err := newBalanceMonitor(client, vrun.New(), notifier, []*BalanceThreshold{{}}); err == nil {
+		t.Error("empty account type should be rejected")
+	}
+}
+
+func TestMemoryBalanceHistoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryBalanceHistoryStore(2 * time.Hour)
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, vwxutils.ChinaLocation)
+	for i := 0; i < 5; i++ {
+		_ = store.Append(ctx, &BalanceSnapshot{
+			AccountType:     AccountTypeBasic,
+			AvailableAmount: vwxmoney.Fen(int64(i)),
+			Time:            start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+
+	list, _ := store.List(ctx, AccountTypeBasic, time.Time{})
+	if len(list) != 3 || list[0].AvailableAmount != vwxmoney.Fen(2) {
+		t.Fatalf("expired snapshots should be removed: %d", len(list))
+	}
+
+	list, _ = store.List(ctx, AccountTypeBasic, start.Add(4*time.Hour))
+	if len(list) != 1 {
+		t.Fatalf("unexpected snapshots: %d", len(list))
+	}
+}
@@ -24,17 +24,18 @@ import (
 	"io"
 
 	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
 )
 
 // BalanceQueryResponse 账户余额响应
 type BalanceQueryResponse struct {
-	// AvailableAmount 可用余额(单位:分)
+	// AvailableAmount 可用余额
 	// 可用余额可用于提现等操作
-	AvailableAmount int64 `json:"available_amount"`
+	AvailableAmount vwxmoney.Money `json:"available_amount"`
 
-	// PendingAmount 不可用余额(单位:分)
+	// PendingAmount 不可用余额
 	// 不可用余额为冻结金额,不可进行提现等操作
-	PendingAmount *int64 `json:"pending_amount,omitempty"`
+	PendingAmount *vwxmoney.Money `json:"pending_amount,omitempty"`
 }
 
 // QueryBalance 查询账户实时余额
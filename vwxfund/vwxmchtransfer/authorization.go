@@ -0,0 +1,185 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+)
+
+// AuthorizationBaseURL 用户免确认收款授权接口地址前缀
+const AuthorizationBaseURL = "https://api.mch.weixin.qq.com/v3/fund-app/mch-transfer/user-authorizations"
+
+const (
+	AuthorizationStateWaitAuthorize = "WAIT_AUTHORIZE" // 待用户授权
+	AuthorizationStateAuthorized    = "AUTHORIZED"     // 已授权
+	AuthorizationStateCancelled     = "CANCELLED"      // 用户已解除授权
+)
+
+// NotifyEventAuthorizationChanged 用户免确认收款授权变更通知
+const NotifyEventAuthorizationChanged = "MCHTRANSFER.AUTHORIZATION.CHANGED"
+
+// ErrNotAuthorized 用户未授权免确认收款
+var ErrNotAuthorized = errors.New("user has not authorized confirmation-free transfer")
+
+// ApplyAuthorizationRequest 发起用户免确认收款授权请求
+type ApplyAuthorizationRequest struct {
+	Appid              string `json:"appid"`                // 商户AppID, 为空时使用配置中的默认AppID
+	OutAuthorizationNo string `json:"out_authorization_no"` // 商户授权单号
+	Openid             string `json:"openid"`               // 用户OpenID
+	TransferSceneId    string `json:"transfer_scene_id"`    // 授权的转账场景ID
+	NotifyUrl          string `json:"notify_url,omitempty"` // 授权结果通知地址
+}
+
+// ApplyAuthorizationResponse 发起用户免确认收款授权响应
+type ApplyAuthorizationResponse struct {
+	OutAuthorizationNo string `json:"out_authorization_no"` // 商户授权单号
+	State              string `json:"state"`                // 授权状态
+	PackageInfo        string `json:"package_info"`         // 拉起用户授权页面的 package 信息
+	CreateTime         string `json:"create_time"`          // 创建时间
+}
+
+// Authorization 用户免确认收款授权信息
+type Authorization struct {
+	Mchid              string `json:"mchid"`                // 商户号
+	Appid              string `json:"appid"`                // 商户AppID
+	Openid             string `json:"openid"`               // 用户OpenID
+	TransferSceneId    string `json:"transfer_scene_id"`    // 授权的转账场景ID
+	OutAuthorizationNo string `json:"out_authorization_no"` // 商户授权单号
+	State              string `json:"state"`                // 授权状态
+	AuthorizeTime      string `json:"authorize_time"`       // 授权时间
+	UpdateTime         string `json:"update_time"`          // 状态更新时间
+}
+
+// IsAuthorized 用户是否已授权免确认收款
+func (a *Authorization) IsAuthorized() bool {
+	return a.State == AuthorizationStateAuthorized
+}
+
+// ApplyAuthorization 发起用户免确认收款授权
+// 返回的 package_info 通过 BuildAuthorizationPayload 生成前端参数, 拉起用户授权页面, 用户授权后同一场景的转账无需确认收款.
+func (c *MchTransferClient) ApplyAuthorization(ctx context.Context, req *ApplyAuthorizationRequest) (*ApplyAuthorizationResponse, error) {
+	if req.OutAuthorizationNo == "" || req.Openid == "" || req.TransferSceneId == "" {
+		return nil, fmt.Errorf("out_authorization_no, openid and transfer_scene_id are required")
+	}
+
+	body := *req
+	if body.Appid == "" {
+		body.Appid = c.mgr.Config.AppID
+	}
+
+	vlog.Infof("apply transfer authorization | out_authorization_no: %s | transfer_scene_id: %s", body.OutAuthorizationNo, body.TransferSceneId)
+
+	var resp ApplyAuthorizationResponse
+	if err := c.mgr.RequestJSON(ctx, "transfer authorization", http.MethodPost, AuthorizationBaseURL, nil, nil, &body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// BuildAuthorizationPayload 根据发起授权的应答生成前端拉起用户授权页面的参数, 参数格式与确认收款页面一致
+// appID: 发起授权时使用的商户AppID, 为空时使用配置中的默认AppID
+func (c *MchTransferClient) BuildAuthorizationPayload(appID string, resp *ApplyAuthorizationResponse) (*ConfirmPayload, error) {
+	if resp.PackageInfo == "" {
+		return nil, fmt.Errorf("package_info of authorization %s is empty", resp.OutAuthorizationNo)
+	}
+
+	if appID == "" {
+		appID = c.mgr.Config.AppID
+	}
+
+	return &ConfirmPayload{
+		MchID:   c.mgr.Config.MerchantID,
+		AppID:   appID,
+		Package: resp.PackageInfo,
+	}, nil
+}
+
+// QueryAuthorization 查询用户在转账场景下的免确认收款授权状态
+// appID: 商户AppID, 为空时使用配置中的默认AppID
+func (c *MchTransferClient) QueryAuthorization(ctx context.Context, appID, openid, transferSceneId string) (*Authorization, error) {
+	if appID == "" {
+		appID = c.mgr.Config.AppID
+	}
+
+	query := url.Values{}
+	query.Set("appid", appID)
+	query.Set("transfer_scene_id", transferSceneId)
+
+	vlog.Infof("query transfer authorization | openid: %s | transfer_scene_id: %s", openid, transferSceneId)
+
+	var resp Authorization
+	if err := c.mgr.RequestJSON(ctx, "transfer authorization", http.MethodGet, AuthorizationBaseURL+"/openid/"+url.PathEscape(openid), nil, query, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// TransferWithAuthorization 向已授权免确认收款的用户发起转账
+// 发起前查询用户在该转账场景下的授权状态, 未授权时返回 ErrNotAuthorized, 调用方可改用需用户确认的转账流程.
+// req.Appid 为空时使用配置中的默认AppID.
+func (c *MchTransferClient) TransferWithAuthorization(ctx context.Context, req *TransferRequest) (*TransferBillsResponse, error) {
+	if req.Appid == "" {
+		r := *req
+		r.Appid = c.mgr.Config.AppID
+		req = &r
+	}
+
+	authorization, err := c.QueryAuthorization(ctx, req.Appid, req.Openid, req.TransferSceneId)
+	if err != nil {
+		return nil, fmt.Errorf("query transfer authorization error: %w", err)
+	}
+
+	if !authorization.IsAuthorized() {
+		return nil, fmt.Errorf("%w: openid: %s, transfer_scene_id: %s, state: %s", ErrNotAuthorized, req.Openid, req.TransferSceneId, authorization.State)
+	}
+
+	return c.DoTransfer(ctx, req)
+}
+
+// AuthorizationNotify 用户免确认收款授权变更通知
+type AuthorizationNotify struct {
+	Mchid              string `json:"mchid"`                // 商户号
+	Appid              string `json:"appid"`                // 商户AppID
+	Openid             string `json:"openid"`               // 用户OpenID
+	TransferSceneId    string `json:"transfer_scene_id"`    // 授权的转账场景ID
+	OutAuthorizationNo string `json:"out_authorization_no"` // 商户授权单号
+	State              string `json:"state"`                // 授权状态
+	UpdateTime         string `json:"update_time"`          // 状态更新时间
+}
+
+// ParseAuthorizationNotify 解析用户免确认收款授权变更通知
+// 商户需要验证签名，确保回调通知的真实性
+func (c *MchTransferClient) ParseAuthorizationNotify(headerFetcher func(string) string, body []byte) (*notify.Request, *AuthorizationNotify, error) {
+	err := c.mgr.PlatManager.VerifyRequestMessage(context.Background(), headerFetcher, body)
+	if err != nil {
+		vlog.Errorf("validate http message failed | err: %v", err)
+		return nil, nil, err
+	}
+
+	return vwxutils.ParseNotifyBody[AuthorizationNotify](c.mgr.Config.MerchantAPIv3Key, "transfer authorization", body)
+}
@@ -0,0 +1,69 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+func TestParseAuthorizationNotifyBody(t *testing.T) {
+	const apiV3Key = "0123456789abcdef0123456789abcdef"
+
+	plaintext := `{"mchid":"1900000100","appid":"wx001","openid":"o001","transfer_scene_id":"1000","out_authorization_no":"A001","state":"AUTHORIZED","update_time":"2025-01-01T10:00:00+08:00"}`
+
+	block, err := aes.NewCipher([]byte(apiV3Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, associatedData := "0123456789ab", "mch_payment"
+	ciphertext := gcm.Seal(nil, []byte(nonce), []byte(plaintext), []byte(associatedData))
+
+	body, _ := json.Marshal(map[string]any{
+		"id":         "EV-001",
+		"event_type": NotifyEventAuthorizationChanged,
+		"resource": map[string]string{
+			"algorithm":       "AEAD_AES_256_GCM",
+			"ciphertext":      base64.StdEncoding.EncodeToString(ciphertext),
+			"nonce":           nonce,
+			"associated_data": associatedData,
+		},
+	})
+
+	_, n, err := vwxutils.ParseNotifyBody[AuthorizationNotify](apiV3Key, "transfer authorization", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n.Openid != "o001" || n.State != AuthorizationStateAuthorized {
+		t.Errorf("unexpected notify: %+v", n)
+	}
+
+	if !(&Authorization{State: n.State}).IsAuthorized() {
+		t.Error("expected authorized")
+	}
+}
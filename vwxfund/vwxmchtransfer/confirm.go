@@ -0,0 +1,97 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vogo/vogo/vlog"
+)
+
+// ErrNotConfirmable 转账单不处于待用户确认或转账中状态, 无法拉起用户确认收款页面
+var ErrNotConfirmable = errors.New("transfer bill is not waiting for user confirmation")
+
+// IsStateConfirmable 转账单是否可拉起用户确认收款页面
+func IsStateConfirmable(state string) bool {
+	return state == StateWaitUserConfirm || state == StateTransfering
+}
+
+// ConfirmPayload 前端拉起用户确认收款页面的参数
+// 小程序: wx.requestMerchantTransfer({ mchId, appId, package, success, fail })
+// 公众号网页: WeixinJSBridge.invoke('requestMerchantTransfer', { mchId, appId, package }, callback)
+type ConfirmPayload struct {
+	MchID   string `json:"mchId"`   // 商户号
+	AppID   string `json:"appId"`   // 发起转账时使用的商户AppID
+	Package string `json:"package"` // 发起转账返回的 package_info
+}
+
+// BuildConfirmPayload 根据发起转账的应答生成前端拉起用户确认收款页面的参数
+// appID: 发起转账时使用的商户AppID, 为空时使用配置中的默认AppID
+// resp: 发起转账或 RefreshPackageInfo 的应答
+func (c *MchTransferClient) BuildConfirmPayload(appID string, resp *TransferBillsResponse) (*ConfirmPayload, error) {
+	if appID == "" {
+		appID = c.mgr.Config.AppID
+	}
+
+	return newConfirmPayload(c.mgr.Config.MerchantID, appID, resp)
+}
+
+func newConfirmPayload(mchID, appID string, resp *TransferBillsResponse) (*ConfirmPayload, error) {
+	if !IsStateConfirmable(resp.State) {
+		return nil, fmt.Errorf("%w: out_bill_no: %s, state: %s", ErrNotConfirmable, resp.OutBillNo, resp.State)
+	}
+
+	if resp.PackageInfo == "" {
+		return nil, fmt.Errorf("package_info of transfer %s is empty", resp.OutBillNo)
+	}
+
+	return &ConfirmPayload{
+		MchID:   mchID,
+		AppID:   appID,
+		Package: resp.PackageInfo,
+	}, nil
+}
+
+// RefreshPackageInfo 重新获取转账单的 package_info, 用于用户未完成确认时再次拉起确认收款页面
+// 使用与原转账完全相同的请求参数(含商户单号)再次发起转账, 微信支付按商户单号返回原转账单及新的 package_info, 不会创建新的转账.
+// 仅待用户确认(WAIT_USER_CONFIRM)或转账中(TRANSFERING)的转账单可重新获取, 否则返回 ErrNotConfirmable.
+func (c *MchTransferClient) RefreshPackageInfo(ctx context.Context, req *TransferRequest) (*TransferBillsResponse, error) {
+	bill, err := c.QueryTransferByOutBillNo(ctx, req.OutBillNo)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsStateConfirmable(bill.State) {
+		return nil, fmt.Errorf("%w: out_bill_no: %s, state: %s", ErrNotConfirmable, req.OutBillNo, bill.State)
+	}
+
+	vlog.Infof("refresh transfer package info | out_bill_no: %s | state: %s", req.OutBillNo, bill.State)
+
+	resp, err := c.DoTransfer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.TransferBillNo != bill.TransferBillNo {
+		return nil, fmt.Errorf("transfer bill mismatch: out_bill_no: %s, expect %s, got %s", req.OutBillNo, bill.TransferBillNo, resp.TransferBillNo)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,429 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxfund/vwxmchbalance"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+// guardDayLayout 计数键中的日期格式
+const guardDayLayout = "20060102"
+
+var (
+	// ErrTransferLimitExceeded 转账超过本地限额
+	ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+	// ErrDuplicateOutBillNo 商户单号已用于不同的转账
+	ErrDuplicateOutBillNo = errors.New("out_bill_no already used by another transfer")
+	// ErrInsufficientBalance 运营账户可用余额不足
+	ErrInsufficientBalance = errors.New("insufficient operation account balance")
+)
+
+// TransferLimit 累计转账限额, 零值表示不限制
+type TransferLimit struct {
+	MaxAmount vwxmoney.Money // 累计金额上限
+	MaxCount  int64          // 累计笔数上限
+}
+
+// IsUnlimited 是否不限制
+func (l TransferLimit) IsUnlimited() bool {
+	return !l.MaxAmount.IsPositive() && l.MaxCount <= 0
+}
+
+// TransferGuardLimits 转账防护限额配置, 每日按北京时间划分
+type TransferGuardLimits struct {
+	MaxAmountPerTransfer vwxmoney.Money           // 单笔金额上限, 为零不限制
+	UserDaily            TransferLimit            // 每个收款用户每日限额
+	SceneDaily           map[string]TransferLimit // 每个转账场景每日限额, key 为转账场景ID
+	Daily                TransferLimit            // 商户每日限额
+	BalanceCheckAmount   vwxmoney.Money           // 单笔金额达到该值时先查询运营账户余额, 为零不查询
+}
+
+// GuardCounter 转账计数项
+type GuardCounter struct {
+	Key        string        // 计数键, 如 user/20250601/openid
+	Limit      TransferLimit // 限额
+	ExpireTime time.Time     // 过期时间, 为计数日期次日零点(北京时间), 过期后存储可删除该计数项
+}
+
+// GuardRecord 转账防护登记记录
+type GuardRecord struct {
+	OutBillNo       string         `json:"out_bill_no"`       // 商户单号
+	Openid          string         `json:"openid"`            // 收款用户OpenID
+	TransferSceneId string         `json:"transfer_scene_id"` // 转账场景ID
+	Amount          vwxmoney.Money `json:"amount"`            // 转账金额
+	CounterKeys     []string       `json:"counter_keys"`      // 已累加的计数键, 释放时回滚
+	Released        bool           `json:"released"`          // 是否已释放, 已释放的记录不计入限额
+	CreateTime      time.Time      `json:"create_time"`       // 登记时间
+}
+
+// sameTransfer 是否为相同的转账
+func (r *GuardRecord) sameTransfer(o *GuardRecord) bool {
+	return r.Openid == o.Openid && r.TransferSceneId == o.TransferSceneId && r.Amount == o.Amount
+}
+
+// TransferGuardStore 转账防护计数存储
+type TransferGuardStore interface {
+	// Reserve 原子地校验并登记转账: 相同商户单号已登记时, 收款用户、场景和金额不一致返回 ErrDuplicateOutBillNo,
+	// 一致且未释放时返回已有记录, 不重复计数; 否则校验各计数项加上本次转账后不超过限额, 超过时返回 ErrTransferLimitExceeded.
+	Reserve(ctx context.Context, record *GuardRecord, counters []*GuardCounter) (*GuardRecord, error)
+	// Release 释放登记并回滚计数
+	Release(ctx context.Context, outBillNo string) error
+}
+
+type guardCount struct {
+	amount     vwxmoney.Money
+	count      int64
+	expireTime time.Time
+}
+
+// MemoryTransferGuardStore 基于内存的转账防护计数存储
+// 日期变化后首次登记时清理往日的登记记录及已过期的计数项, 往日的转账不再支持释放.
+type MemoryTransferGuardStore struct {
+	mu       sync.Mutex
+	day      string                  // 当前登记日期(北京时间)
+	records  map[string]*GuardRecord // key: 商户单号
+	counters map[string]*guardCount  // key: 计数键
+}
+
+// NewMemoryTransferGuardStore 创建基于内存的转账防护计数存储
+func NewMemoryTransferGuardStore() *MemoryTransferGuardStore {
+	return &MemoryTransferGuardStore{
+		records:  make(map[string]*GuardRecord),
+		counters: make(map[string]*guardCount),
+	}
+}
+
+func (s *MemoryTransferGuardStore) Reserve(_ context.Context, record *GuardRecord, counters []*GuardCounter) (*GuardRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(record.CreateTime)
+
+	if existing, ok := s.records[record.OutBillNo]; ok {
+		if !existing.sameTransfer(record) {
+			return nil, fmt.Errorf("%w: %s, amount %s", ErrDuplicateOutBillNo, record.OutBillNo, existing.Amount)
+		}
+		if !existing.Released {
+			r := *existing
+			return &r, nil
+		}
+	}
+
+	// 先校验全部计数项, 通过后再累加
+	for _, counter := range counters {
+		current, ok := s.counters[counter.Key]
+		if !ok {
+			current = &guardCount{amount: vwxmoney.New(0, record.Amount.Currency())}
+		}
+
+		if err := checkTransferLimit(counter, current.amount, current.count, record.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]string, 0, len(counters))
+	for _, counter := range counters {
+		current, ok := s.counters[counter.Key]
+		if !ok {
+			current = &guardCount{amount: vwxmoney.New(0, record.Amount.Currency()), expireTime: counter.ExpireTime}
+			s.counters[counter.Key] = current
+		}
+
+		amount, err := current.amount.Add(record.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		current.amount = amount
+		current.count++
+		keys = append(keys, counter.Key)
+	}
+
+	r := *record
+	r.CounterKeys = keys
+	r.Released = false
+	s.records[record.OutBillNo] = &r
+
+	result := r
+	return &result, nil
+}
+
+func (s *MemoryTransferGuardStore) Release(_ context.Context, outBillNo string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[outBillNo]
+	if !ok {
+		return fmt.Errorf("transfer guard record not found: %s", outBillNo)
+	}
+
+	if record.Released {
+		return nil
+	}
+
+	for _, key := range record.CounterKeys {
+		current, ok := s.counters[key]
+		if !ok {
+			continue
+		}
+
+		amount, err := current.amount.Sub(record.Amount)
+		if err != nil {
+			return err
+		}
+
+		current.amount = amount
+		current.count--
+	}
+
+	record.Released = true
+
+	return nil
+}
+
+// prune 日期变化时清理往日的登记记录及已过期的计数项
+func (s *MemoryTransferGuardStore) prune(now time.Time) {
+	now = now.In(vwxutils.ChinaLocation)
+	day := now.Format(guardDayLayout)
+	if day == s.day {
+		return
+	}
+	s.day = day
+
+	for outBillNo, record := range s.records {
+		if record.CreateTime.In(vwxutils.ChinaLocation).Format(guardDayLayout) < day {
+			delete(s.records, outBillNo)
+		}
+	}
+
+	for key, current := range s.counters {
+		if !current.expireTime.IsZero() && !now.Before(current.expireTime) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// checkTransferLimit 校验计数项加上本次转账金额后不超过限额
+func checkTransferLimit(counter *GuardCounter, amount vwxmoney.Money, count int64, transferAmount vwxmoney.Money) error {
+	if counter.Limit.MaxCount > 0 && count+1 > counter.Limit.MaxCount {
+		return fmt.Errorf("%w: %s count %d, max %d", ErrTransferLimitExceeded, counter.Key, count, counter.Limit.MaxCount)
+	}
+
+	if counter.Limit.MaxAmount.IsPositive() {
+		total, err := amount.Add(transferAmount)
+		if err != nil {
+			return err
+		}
+
+		cmp, err := total.Cmp(counter.Limit.MaxAmount)
+		if err != nil {
+			return err
+		}
+		if cmp > 0 {
+			return fmt.Errorf("%w: %s amount %s, transfer %s, max %s",
+				ErrTransferLimitExceeded, counter.Key, amount, transferAmount, counter.Limit.MaxAmount)
+		}
+	}
+
+	return nil
+}
+
+// transferer 发起转账接口, 由 MchTransferClient 实现
+type transferer interface {
+	DoTransfer(ctx context.Context, req *TransferRequest) (*TransferBillsResponse, error)
+}
+
+// balanceQuerier 查询账户余额接口, 由 vwxmchbalance.MchBalanceClient 实现
+type balanceQuerier interface {
+	QueryBalance(ctx context.Context, accountType vwxmchbalance.AccountType) (*vwxmchbalance.BalanceQueryResponse, error)
+}
+
+// TransferGuard 转账防护
+// 发起转账前按本地配置校验单笔、每个用户、每个场景及每日的累计金额和笔数, 拒绝重复使用商户单号发起不同的转账,
+// 大额转账前查询运营账户余额, 避免程序缺陷导致批量误转账.
+type TransferGuard struct {
+	client  transferer
+	balance balanceQuerier
+	limits  *TransferGuardLimits
+	store   TransferGuardStore
+	now     func() time.Time
+}
+
+// TransferGuardOption 转账防护可选项
+type TransferGuardOption func(*TransferGuard)
+
+// WithTransferGuardStore 设置转账防护计数存储, 默认使用内存存储, 多实例部署时需使用共享存储
+func WithTransferGuardStore(store TransferGuardStore) TransferGuardOption {
+	return func(g *TransferGuard) { g.store = store }
+}
+
+// NewTransferGuard 创建转账防护
+// balanceClient: 余额查询客户端, 为 nil 时不查询余额
+func NewTransferGuard(client *MchTransferClient, balanceClient *vwxmchbalance.MchBalanceClient,
+	limits *TransferGuardLimits, opts ...TransferGuardOption,
+) *TransferGuard {
+	var balance balanceQuerier
+	if balanceClient != nil {
+		balance = balanceClient
+	}
+
+	return newTransferGuard(client, balance, limits, opts...)
+}
+
+func newTransferGuard(client transferer, balance balanceQuerier, limits *TransferGuardLimits, opts ...TransferGuardOption) *TransferGuard {
+	if limits == nil {
+		limits = &TransferGuardLimits{}
+	}
+
+	g := &TransferGuard{
+		client:  client,
+		balance: balance,
+		limits:  limits,
+		store:   NewMemoryTransferGuardStore(),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// Transfer 校验限额并发起转账
+// 转账明确失败(请求参数错误或转账失败)时释放登记的额度, 结果未知时保留登记, 可使用相同的商户单号重试.
+func (g *TransferGuard) Transfer(ctx context.Context, req *TransferRequest) (*TransferBillsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if g.limits.MaxAmountPerTransfer.IsPositive() {
+		cmp, err := req.TransferAmount.Cmp(g.limits.MaxAmountPerTransfer)
+		if err != nil {
+			return nil, err
+		}
+		if cmp > 0 {
+			return nil, fmt.Errorf("%w: transfer %s, max per transfer %s",
+				ErrTransferLimitExceeded, req.TransferAmount, g.limits.MaxAmountPerTransfer)
+		}
+	}
+
+	if err := g.checkBalance(ctx, req.TransferAmount); err != nil {
+		return nil, err
+	}
+
+	_, err := g.store.Reserve(ctx, &GuardRecord{
+		OutBillNo:       req.OutBillNo,
+		Openid:          req.Openid,
+		TransferSceneId: req.TransferSceneId,
+		Amount:          req.TransferAmount,
+		CreateTime:      g.now(),
+	}, g.counters(req))
+	if err != nil {
+		vlog.Warnf("transfer rejected by guard | out_bill_no: %s | openid: %s | amount: %s | err: %v",
+			req.OutBillNo, req.Openid, req.TransferAmount, err)
+		return nil, err
+	}
+
+	resp, err := g.client.DoTransfer(ctx, req)
+	if err != nil {
+		if vwxutils.IsDefiniteFailure(err) {
+			g.release(ctx, req.OutBillNo)
+		}
+		return nil, err
+	}
+
+	if IsStateFail(resp.State) {
+		g.release(ctx, req.OutBillNo)
+	}
+
+	return resp, nil
+}
+
+// Release 释放转账登记的额度, 收到转账失败或撤销完成的通知时调用
+func (g *TransferGuard) Release(ctx context.Context, outBillNo string) error {
+	return g.store.Release(ctx, outBillNo)
+}
+
+func (g *TransferGuard) release(ctx context.Context, outBillNo string) {
+	if err := g.store.Release(ctx, outBillNo); err != nil {
+		vlog.Errorf("release transfer guard record error | out_bill_no: %s | err: %v", outBillNo, err)
+	}
+}
+
+// counters 生成转账需要累加的计数项
+func (g *TransferGuard) counters(req *TransferRequest) []*GuardCounter {
+	now := g.now().In(vwxutils.ChinaLocation)
+	day := now.Format(guardDayLayout)
+	expireTime := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, vwxutils.ChinaLocation)
+
+	var counters []*GuardCounter
+
+	if !g.limits.UserDaily.IsUnlimited() {
+		counters = append(counters, &GuardCounter{Key: "user/" + day + "/" + req.Openid, Limit: g.limits.UserDaily, ExpireTime: expireTime})
+	}
+
+	if limit, ok := g.limits.SceneDaily[req.TransferSceneId]; ok && !limit.IsUnlimited() {
+		counters = append(counters, &GuardCounter{Key: "scene/" + day + "/" + req.TransferSceneId, Limit: limit, ExpireTime: expireTime})
+	}
+
+	if !g.limits.Daily.IsUnlimited() {
+		counters = append(counters, &GuardCounter{Key: "daily/" + day, Limit: g.limits.Daily, ExpireTime: expireTime})
+	}
+
+	return counters
+}
+
+// checkBalance 大额转账前校验运营账户可用余额
+func (g *TransferGuard) checkBalance(ctx context.Context, amount vwxmoney.Money) error {
+	if g.balance == nil || !g.limits.BalanceCheckAmount.IsPositive() {
+		return nil
+	}
+
+	cmp, err := amount.Cmp(g.limits.BalanceCheckAmount)
+	if err != nil {
+		return err
+	}
+	if cmp < 0 {
+		return nil
+	}
+
+	balance, err := g.balance.QueryBalance(ctx, vwxmchbalance.AccountTypeOperation)
+	if err != nil {
+		return fmt.Errorf("query operation balance error: %w", err)
+	}
+
+	cmp, err = balance.AvailableAmount.Cmp(amount)
+	if err != nil {
+		return err
+	}
+	if cmp < 0 {
+		return fmt.Errorf("%w: available %s, transfer %s", ErrInsufficientBalance, balance.AvailableAmount, amount)
+	}
+
+	return nil
+}
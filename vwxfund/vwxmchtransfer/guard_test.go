@@ -0,0 +1,180 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxfund/vwxmchbalance"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+)
+
+type fakeTransferer struct {
+	err       error
+	transfers []string
+}
+
+func (c *fakeTransferer) DoTransfer(_ context.Context, req *TransferRequest) (*TransferBillsResponse, error) {
+	c.transfers = append(c.transfers, req.OutBillNo)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &TransferBillsResponse{OutBillNo: req.OutBillNo, State: StateAccepted}, nil
+}
+
+type fakeBalanceQuerier struct {
+	available vwxmoney.Money
+	queries   int
+}
+
+func (c *fakeBalanceQuerier) QueryBalance(_ context.Context, _ vwxmchbalance.AccountType) (*vwxmchbalance.BalanceQueryResponse, error) {
+	c.queries++
+	return &vwxmchbalance.BalanceQueryResponse{AvailableAmount: c.available}, nil
+}
+
+func newGuardTransferRequest(outBillNo, openid string, amount vwxmoney.Money) *TransferRequest {
+	return &TransferRequest{
+		OutBillNo:       outBillNo,
+		TransferSceneId: "9999",
+		Openid:          openid,
+		TransferAmount:  amount,
+	}
+}
+
+func TestTransferGuardLimits(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeTransferer{}
+	guard := newTransferGuard(client, nil, &TransferGuardLimits{
+		MaxAmountPerTransfer: vwxmoney.MustParseYuan("100"),
+		UserDaily:            TransferLimit{MaxCount: 2},
+		SceneDaily:           map[string]TransferLimit{"9999": {MaxAmount: vwxmoney.MustParseYuan("150")}},
+	})
+
+	tests := []struct {
+		name    string
+		req     *TransferRequest
+		wantErr error
+	}{
+		{"ok", newGuardTransferRequest("T001", "u1", vwxmoney.MustParseYuan("60")), nil},
+		{"retry same bill", newGuardTransferRequest("T001", "u1", vwxmoney.MustParseYuan("60")), nil},
+		{"reuse bill with different amount", newGuardTransferRequest("T001", "u1", vwxmoney.MustParseYuan("61")), ErrDuplicateOutBillNo},
+		{"over per transfer", newGuardTransferRequest("T002", "u2", vwxmoney.MustParseYuan("101")), ErrTransferLimitExceeded},
+		{"second of user", newGuardTransferRequest("T003", "u1", vwxmoney.MustParseYuan("10")), nil},
+		{"user count exceeded", newGuardTransferRequest("T004", "u1", vwxmoney.MustParseYuan("10")), ErrTransferLimitExceeded},
+		{"scene amount exceeded", newGuardTransferRequest("T005", "u2", vwxmoney.MustParseYuan("81")), ErrTransferLimitExceeded},
+		{"scene amount reached", newGuardTransferRequest("T006", "u2", vwxmoney.MustParseYuan("80")), nil},
+	}
+
+	for _, tt := range tests {
+		_, err := guard.Transfer(ctx, tt.req)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+
+	if len(client.transfers) != 4 {
+		t.Errorf("unexpected transfers: %v", client.transfers)
+	}
+}
+
+func TestTransferGuardRelease(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeTransferer{err: &core.APIError{StatusCode: http.StatusBadRequest, Code: "PARAM_ERROR"}}
+	guard := newTransferGuard(client, nil, &TransferGuardLimits{Daily: TransferLimit{MaxCount: 1}})
+
+	if _, err := guard.Transfer(ctx, newGuardTransferRequest("T001", "u1", vwxmoney.Fen(100))); err == nil {
+		t.Fatal("expected transfer error")
+	}
+
+	// 明确失败的转账释放额度
+	client.err = nil
+	if _, err := guard.Transfer(ctx, newGuardTransferRequest("T002", "u1", vwxmoney.Fen(100))); err != nil {
+		t.Fatalf("transfer after release error: %v", err)
+	}
+
+	// 结果未知的转账保留额度
+	client.err = &core.APIError{StatusCode: http.StatusInternalServerError, Code: "SYSTEM_ERROR"}
+	guard = newTransferGuard(client, nil, &TransferGuardLimits{Daily: TransferLimit{MaxCount: 1}})
+	_, _ = guard.Transfer(ctx, newGuardTransferRequest("T003", "u1", vwxmoney.Fen(100)))
+
+	client.err = nil
+	if _, err := guard.Transfer(ctx, newGuardTransferRequest("T004", "u1", vwxmoney.Fen(100))); !errors.Is(err, ErrTransferLimitExceeded) {
+		t.Fatalf("expected limit exceeded, got: %v", err)
+	}
+
+	if err := guard.Release(ctx, "T003"); err != nil {
+		t.Fatalf("release error: %v", err)
+	}
+	if _, err := guard.Transfer(ctx, newGuardTransferRequest("T004", "u1", vwxmoney.Fen(100))); err != nil {
+		t.Fatalf("transfer after manual release error: %v", err)
+	}
+}
+
+func TestTransferGuardPrune(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTransferGuardStore()
+	guard := newTransferGuard(&fakeTransferer{}, nil, &TransferGuardLimits{Daily: TransferLimit{MaxCount: 1}},
+		WithTransferGuardStore(store))
+
+	now := time.Date(2025, 6, 1, 23, 0, 0, 0, vwxutils.ChinaLocation)
+	guard.now = func() time.Time { return now }
+
+	if _, err := guard.Transfer(ctx, newGuardTransferRequest("T001", "u1", vwxmoney.Fen(100))); err != nil {
+		t.Fatal(err)
+	}
+
+	// 次日清理往日的登记记录及计数项
+	now = now.Add(2 * time.Hour)
+	if _, err := guard.Transfer(ctx, newGuardTransferRequest("T002", "u1", vwxmoney.Fen(100))); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.records) != 1 || store.records["T002"] == nil || len(store.counters) != 1 || store.counters["daily/20250602"] == nil {
+		t.Errorf("past day should be pruned: %v, %v", store.records, store.counters)
+	}
+}
+
+func TestTransferGuardBalance(t *testing.T) {
+	ctx := context.Background()
+	balance := &fakeBalanceQuerier{available: vwxmoney.MustParseYuan("500")}
+	guard := newTransferGuard(&fakeTransferer{}, balance, &TransferGuardLimits{
+		BalanceCheckAmount: vwxmoney.MustParseYuan("200"),
+	})
+
+	req := newGuardTransferRequest("T001", "u1", vwxmoney.MustParseYuan("100"))
+	if _, err := guard.Transfer(ctx, req); err != nil || balance.queries != 0 {
+		t.Fatalf("small transfer should skip balance check: %v, queries %d", err, balance.queries)
+	}
+
+	req = newGuardTransferRequest("T002", "u1", vwxmoney.MustParseYuan("600"))
+	req.UserName = "张三"
+	if _, err := guard.Transfer(ctx, req); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected insufficient balance, got: %v", err)
+	}
+
+	req = newGuardTransferRequest("T003", "u1", vwxmoney.MustParseYuan("300"))
+	if _, err := guard.Transfer(ctx, req); err != nil || balance.queries != 2 {
+		t.Fatalf("unexpected result: %v, queries %d", err, balance.queries)
+	}
+}
@@ -0,0 +1,333 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	// MaxPayoutChunks 单次付款最多拆分的转账笔数
+	MaxPayoutChunks = 99
+	// MaxPayoutNoLength 付款单号最大长度, 各笔转账的商户单号为付款单号加两位序号, 不超过32个字符
+	MaxPayoutNoLength = 30
+)
+
+// DefaultPayoutChunkAmount 默认单笔转账金额上限(200元), 以商户平台配置的转账场景单笔限额为准
+var DefaultPayoutChunkAmount = vwxmoney.Fen(20000)
+
+// PayoutState 付款状态, 由各笔转账的状态汇总得出
+type PayoutState string
+
+const (
+	// PayoutStateProcessing 付款处理中, 有转账未完成或尚未发起
+	PayoutStateProcessing PayoutState = "PROCESSING"
+	// PayoutStateSuccess 全部转账成功
+	PayoutStateSuccess PayoutState = "SUCCESS"
+	// PayoutStatePartialSuccess 部分转账成功, 其余转账失败或已撤销
+	PayoutStatePartialSuccess PayoutState = "PARTIAL_SUCCESS"
+	// PayoutStateFail 全部转账失败或已撤销
+	PayoutStateFail PayoutState = "FAIL"
+)
+
+// IsFinal 是否为最终状态
+func (s PayoutState) IsFinal() bool {
+	return s != PayoutStateProcessing
+}
+
+// PayoutRequest 拆分付款请求, 除金额和商户单号外的参数用于每笔转账
+type PayoutRequest struct {
+	Appid                    string                     // 商户AppID
+	PayoutNo                 string                     // 付款单号, 只能由数字、大小写字母组成, 用于生成各笔转账的商户单号
+	TransferSceneId          string                     // 转账场景ID
+	Openid                   string                     // 收款用户OpenID
+	UserName                 string                     // 收款用户姓名, 传明文
+	TotalAmount              vwxmoney.Money             // 付款总金额
+	MaxChunkAmount           vwxmoney.Money             // 单笔转账金额上限, 为零时使用 DefaultPayoutChunkAmount
+	TransferRemark           string                     // 转账备注
+	NotifyUrl                string                     // 通知地址
+	UserRecvPerception       string                     // 用户收款感知
+	TransferSceneReportInfos []*TransferSceneReportInfo // 转账场景报备信息
+}
+
+// PayoutOutBillNo 生成付款第 index 笔(从0开始)转账的商户单号
+func PayoutOutBillNo(payoutNo string, index int) string {
+	return fmt.Sprintf("%s%02d", payoutNo, index+1)
+}
+
+// SplitPayout 将付款按单笔转账金额上限平均拆分为多笔转账请求, 相同的请求总是生成相同的拆分结果
+func SplitPayout(req *PayoutRequest) ([]*TransferRequest, error) {
+	if req.PayoutNo == "" {
+		return nil, fmt.Errorf("payout_no is empty")
+	}
+
+	if len(req.PayoutNo) > MaxPayoutNoLength {
+		return nil, fmt.Errorf("payout_no exceeds %d chars: %s", MaxPayoutNoLength, req.PayoutNo)
+	}
+
+	if !req.TotalAmount.IsPositive() {
+		return nil, fmt.Errorf("total amount must be greater than 0: %s", req.TotalAmount)
+	}
+
+	maxChunk := req.MaxChunkAmount
+	if maxChunk.IsZero() {
+		maxChunk = DefaultPayoutChunkAmount
+	}
+
+	if !maxChunk.IsPositive() {
+		return nil, fmt.Errorf("max chunk amount must be greater than 0: %s", maxChunk)
+	}
+
+	if !maxChunk.SameCurrency(req.TotalAmount) {
+		return nil, fmt.Errorf("%w: total %s, max chunk %s", vwxmoney.ErrCurrencyMismatch, req.TotalAmount, maxChunk)
+	}
+
+	n := (req.TotalAmount.Fen() + maxChunk.Fen() - 1) / maxChunk.Fen()
+	if n > MaxPayoutChunks {
+		return nil, fmt.Errorf("payout %s needs %d transfers, exceeds %d", req.TotalAmount, n, MaxPayoutChunks)
+	}
+
+	amounts, err := req.TotalAmount.Split(int(n))
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := make([]*TransferRequest, len(amounts))
+	for i, amount := range amounts {
+		reqs[i] = &TransferRequest{
+			Appid:                    req.Appid,
+			OutBillNo:                PayoutOutBillNo(req.PayoutNo, i),
+			TransferSceneId:          req.TransferSceneId,
+			Openid:                   req.Openid,
+			UserName:                 req.UserName,
+			TransferAmount:           amount,
+			TransferRemark:           req.TransferRemark,
+			NotifyUrl:                req.NotifyUrl,
+			UserRecvPerception:       req.UserRecvPerception,
+			TransferSceneReportInfos: req.TransferSceneReportInfos,
+		}
+	}
+
+	return reqs, nil
+}
+
+// PayoutChunk 付款拆分的单笔转账
+type PayoutChunk struct {
+	OutBillNo      string         `json:"out_bill_no"`      // 商户单号
+	Amount         vwxmoney.Money `json:"amount"`           // 转账金额
+	TransferBillNo string         `json:"transfer_bill_no"` // 微信转账单号
+	State          string         `json:"state"`            // 转账状态, 为空表示尚未发起或发起结果未知
+	FailReason     string         `json:"fail_reason"`      // 失败原因
+	PackageInfo    string         `json:"package_info"`     // 转账场景包信息, 待用户确认收款时用于拉起确认页面
+}
+
+// isFailed 转账是否失败或已撤销
+func (c *PayoutChunk) isFailed() bool {
+	return IsStateFail(c.State) || c.State == StateCancelled
+}
+
+// Payout 拆分付款
+type Payout struct {
+	PayoutNo    string         `json:"payout_no"`    // 付款单号
+	Openid      string         `json:"openid"`       // 收款用户OpenID
+	TotalAmount vwxmoney.Money `json:"total_amount"` // 付款总金额
+	Chunks      []*PayoutChunk `json:"chunks"`       // 各笔转账
+}
+
+// State 汇总各笔转账的状态
+// 有转账失败或撤销时不再发起后续转账, 待其他转账完成后为部分成功或失败.
+func (p *Payout) State() PayoutState {
+	var failed, succeeded, processing bool
+
+	for _, chunk := range p.Chunks {
+		switch {
+		case chunk.isFailed():
+			failed = true
+		case IsStateSuccess(chunk.State):
+			succeeded = true
+		default:
+			processing = true
+		}
+	}
+
+	switch {
+	case !failed && !processing:
+		return PayoutStateSuccess
+	case !failed:
+		return PayoutStateProcessing
+	}
+
+	// 有转账失败时, 尚未发起的转账不再发起, 仅等待已发起的转账完成
+	for _, chunk := range p.Chunks {
+		if IsStateProcessing(chunk.State) || chunk.State == StateCanceling {
+			return PayoutStateProcessing
+		}
+	}
+
+	if succeeded {
+		return PayoutStatePartialSuccess
+	}
+	return PayoutStateFail
+}
+
+// SucceededAmount 已转账成功的金额
+func (p *Payout) SucceededAmount() (vwxmoney.Money, error) {
+	succeeded := vwxmoney.New(0, p.TotalAmount.Currency())
+	for _, chunk := range p.Chunks {
+		if !IsStateSuccess(chunk.State) {
+			continue
+		}
+
+		var err error
+		if succeeded, err = succeeded.Add(chunk.Amount); err != nil {
+			return vwxmoney.Money{}, err
+		}
+	}
+	return succeeded, nil
+}
+
+// payoutClient 拆分付款使用的转账接口, 由 MchTransferClient 实现
+type payoutClient interface {
+	transferer
+	transferQuerier
+}
+
+// Payout 拆分付款, 超过单笔转账金额上限的付款拆分为多笔转账逐笔发起
+// 某笔转账失败时不再发起后续转账, 并撤销已发起但未完成的转账.
+// 发起结果未知时返回错误和当前的付款, 可使用相同的请求重试, 已发起的转账使用相同的商户单号不会重复转账.
+func (c *MchTransferClient) Payout(ctx context.Context, req *PayoutRequest) (*Payout, error) {
+	if req.Appid == "" {
+		r := *req
+		r.Appid = c.mgr.Config.AppID
+		req = &r
+	}
+
+	return payout(ctx, c, req)
+}
+
+func payout(ctx context.Context, client payoutClient, req *PayoutRequest) (*Payout, error) {
+	reqs, err := SplitPayout(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range reqs {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	p := &Payout{
+		PayoutNo:    req.PayoutNo,
+		Openid:      req.Openid,
+		TotalAmount: req.TotalAmount,
+		Chunks:      make([]*PayoutChunk, len(reqs)),
+	}
+	for i, r := range reqs {
+		p.Chunks[i] = &PayoutChunk{OutBillNo: r.OutBillNo, Amount: r.TransferAmount}
+	}
+
+	vlog.Infof("payout | payout_no: %s | openid: %s | amount: %s | chunks: %d",
+		req.PayoutNo, req.Openid, req.TotalAmount, len(reqs))
+
+	for i, r := range reqs {
+		chunk := p.Chunks[i]
+
+		resp, err := client.DoTransfer(ctx, r)
+		if err != nil {
+			if !vwxutils.IsDefiniteFailure(err) {
+				return p, fmt.Errorf("payout %s transfer %s error: %w", req.PayoutNo, r.OutBillNo, err)
+			}
+
+			chunk.State = StateFail
+			chunk.FailReason = err.Error()
+			cancelPayoutChunks(ctx, client, p)
+
+			return p, nil
+		}
+
+		chunk.TransferBillNo = resp.TransferBillNo
+		chunk.State = resp.State
+		chunk.FailReason = resp.FailReason
+		chunk.PackageInfo = resp.PackageInfo
+
+		if chunk.isFailed() {
+			cancelPayoutChunks(ctx, client, p)
+			return p, nil
+		}
+	}
+
+	return p, nil
+}
+
+// RefreshPayout 查询未完成转账的最新状态并汇总, 有转账失败时撤销其他未完成的转账
+// 状态为空的转账(发起结果未知)同样查询, 转账单不存在时视为尚未发起.
+func (c *MchTransferClient) RefreshPayout(ctx context.Context, p *Payout) (PayoutState, error) {
+	return refreshPayout(ctx, c, p)
+}
+
+func refreshPayout(ctx context.Context, client payoutClient, p *Payout) (PayoutState, error) {
+	for _, chunk := range p.Chunks {
+		if IsStateTerminal(chunk.State) {
+			continue
+		}
+
+		transfer, err := client.QueryTransferByOutBillNo(ctx, chunk.OutBillNo)
+		if err != nil {
+			if chunk.State == "" && vwxutils.IsNotFound(err) {
+				continue
+			}
+			return p.State(), fmt.Errorf("query payout %s transfer %s error: %w", p.PayoutNo, chunk.OutBillNo, err)
+		}
+
+		chunk.TransferBillNo = transfer.TransferBillNo
+		chunk.State = transfer.State
+		chunk.FailReason = transfer.FailReason
+	}
+
+	for _, chunk := range p.Chunks {
+		if chunk.isFailed() {
+			cancelPayoutChunks(ctx, client, p)
+			break
+		}
+	}
+
+	return p.State(), nil
+}
+
+// cancelPayoutChunks 撤销付款中已发起但未完成的转账, 撤销失败时记录日志, 可通过 RefreshPayout 重试
+func cancelPayoutChunks(ctx context.Context, client transferQuerier, p *Payout) {
+	for _, chunk := range p.Chunks {
+		if !IsStateProcessing(chunk.State) {
+			continue
+		}
+
+		resp, err := client.CancelTransfer(ctx, chunk.OutBillNo)
+		if err != nil {
+			vlog.Errorf("cancel payout transfer error | payout_no: %s | out_bill_no: %s | err: %v", p.PayoutNo, chunk.OutBillNo, err)
+			continue
+		}
+
+		chunk.State = resp.State
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+)
+
+type fakePayoutClient struct {
+	fakeTransferQuerier
+	results map[string]string // 发起转账返回的状态, 为空时返回 WAIT_USER_CONFIRM
+	errs    map[string]error
+}
+
+func (c *fakePayoutClient) DoTransfer(_ context.Context, req *TransferRequest) (*TransferBillsResponse, error) {
+	if err := c.errs[req.OutBillNo]; err != nil {
+		return nil, err
+	}
+
+	state := c.results[req.OutBillNo]
+	if state == "" {
+		state = StateWaitUserConfirm
+	}
+	c.states[req.OutBillNo] = state
+
+	return &TransferBillsResponse{OutBillNo: req.OutBillNo, TransferBillNo: "W" + req.OutBillNo, State: state}, nil
+}
+
+func newFakePayoutClient() *fakePayoutClient {
+	return &fakePayoutClient{
+		fakeTransferQuerier: fakeTransferQuerier{states: map[string]string{}},
+		results:             map[string]string{},
+		errs:                map[string]error{},
+	}
+}
+
+func TestSplitPayout(t *testing.T) {
+	tests := []struct {
+		total    string
+		maxChunk string
+		want     []int64
+		wantErr  bool
+	}{
+		{"100", "", []int64{10000}, false},
+		{"200", "", []int64{20000}, false},
+		{"500.01", "", []int64{16667, 16667, 16667}, false},
+		{"5000", "2000", []int64{166667, 166667, 166666}, false},
+		{"0", "", nil, true},
+		{"1000", "10", nil, true},
+	}
+
+	for _, tt := range tests {
+		req := &PayoutRequest{PayoutNo: "P001", TotalAmount: vwxmoney.MustParseYuan(tt.total)}
+		if tt.maxChunk != "" {
+			req.MaxChunkAmount = vwxmoney.MustParseYuan(tt.maxChunk)
+		}
+
+		reqs, err := SplitPayout(req)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("split %s error: %v", tt.total, err)
+		}
+
+		if len(reqs) != len(tt.want) {
+			t.Fatalf("split %s into %d transfers, want %d", tt.total, len(reqs), len(tt.want))
+		}
+
+		for i, r := range reqs {
+			if r.TransferAmount.Fen() != tt.want[i] || r.OutBillNo != PayoutOutBillNo("P001", i) {
+				t.Errorf("split %s transfer %d: %s %s", tt.total, i, r.OutBillNo, r.TransferAmount)
+			}
+		}
+	}
+}
+
+func TestPayout(t *testing.T) {
+	ctx := context.Background()
+	req := &PayoutRequest{
+		PayoutNo:        "P001",
+		TransferSceneId: "9999",
+		Openid:          "u1",
+		TotalAmount:     vwxmoney.MustParseYuan("500"),
+	}
+
+	client := newFakePayoutClient()
+	p, err := payout(ctx, client, req)
+	if err != nil {
+		t.Fatalf("payout error: %v", err)
+	}
+
+	if len(p.Chunks) != 3 || p.State() != PayoutStateProcessing {
+		t.Fatalf("unexpected payout: %d chunks, state %s", len(p.Chunks), p.State())
+	}
+
+	for _, chunk := range p.Chunks {
+		client.states[chunk.OutBillNo] = StateSuccess
+	}
+
+	state, err := refreshPayout(ctx, client, p)
+	if err != nil || state != PayoutStateSuccess {
+		t.Fatalf("unexpected state: %s, %v", state, err)
+	}
+
+	// 第二笔转账失败, 撤销第一笔, 不再发起第三笔
+	client = newFakePayoutClient()
+	client.errs["P00102"] = &core.APIError{StatusCode: http.StatusForbidden, Code: "NOT_ENOUGH"}
+
+	p, err = payout(ctx, client, req)
+	if err != nil {
+		t.Fatalf("payout error: %v", err)
+	}
+
+	if len(client.cancelled) != 1 || client.cancelled[0] != "P00101" || p.Chunks[2].State != "" {
+		t.Fatalf("unexpected cancel: %v, chunks: %v", client.cancelled, p.Chunks)
+	}
+
+	if p.State() != PayoutStateProcessing {
+		t.Fatalf("payout should wait for cancelling: %s", p.State())
+	}
+
+	client.states["P00101"] = StateCancelled
+	state, _ = refreshPayout(ctx, client, p)
+	if state != PayoutStateFail {
+		t.Fatalf("unexpected state: %s", state)
+	}
+
+	// 结果未知时返回错误, 使用相同的请求重试
+	client = newFakePayoutClient()
+	client.errs["P00103"] = &core.APIError{StatusCode: http.StatusInternalServerError, Code: "SYSTEM_ERROR"}
+	if _, err = payout(ctx, client, req); err == nil || len(client.cancelled) != 0 {
+		t.Fatalf("expected unknown result error: %v, cancelled: %v", err, client.cancelled)
+	}
+
+	// 发起结果未知的转账实际已受理, 刷新时查询到最新状态; 未发起的转账保持为空
+	p = &Payout{PayoutNo: "P001", TotalAmount: req.TotalAmount, Chunks: []*PayoutChunk{
+		{OutBillNo: "P00101", State: StateSuccess},
+		{OutBillNo: "P00102"},
+		{OutBillNo: "P00103"},
+	}}
+	client = newFakePayoutClient()
+	client.states["P00102"] = StateWaitUserConfirm
+
+	state, err = refreshPayout(ctx, client, p)
+	if err != nil || state != PayoutStateProcessing || p.Chunks[1].State != StateWaitUserConfirm || p.Chunks[2].State != "" {
+		t.Fatalf("unexpected refresh: %s, %v, chunks: %v", state, err, p.Chunks)
+	}
+}
+
+func TestPayoutState(t *testing.T) {
+	tests := []struct {
+		states []string
+		want   PayoutState
+	}{
+		{[]string{StateSuccess, StateSuccess}, PayoutStateSuccess},
+		{[]string{StateSuccess, ""}, PayoutStateProcessing},
+		{[]string{StateSuccess, StateFail, ""}, PayoutStatePartialSuccess},
+		{[]string{StateCanceling, StateFail}, PayoutStateProcessing},
+		{[]string{StateCancelled, StateFail}, PayoutStateFail},
+	}
+
+	for _, tt := range tests {
+		p := &Payout{}
+		for _, state := range tt.states {
+			p.Chunks = append(p.Chunks, &PayoutChunk{State: state})
+		}
+
+		if got := p.State(); got != tt.want {
+			t.Errorf("states %v: got %s, want %s", tt.states, got, tt.want)
+		}
+	}
+}
@@ -24,22 +24,23 @@ import (
 	"io"
 
 	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
 )
 
 // QueryTransferResponse 查询转账单响应参数
 type QueryTransferResponse struct {
-	Mchid          string `json:"mchid"`            // 商户号
-	OutBillNo      string `json:"out_bill_no"`      // 商户单号
-	TransferBillNo string `json:"transfer_bill_no"` // 微信转账单号
-	Appid          string `json:"appid"`            // 应用ID
-	State          string `json:"state"`            // 转账状态
-	TransferAmount int64  `json:"transfer_amount"`  // 转账金额
-	TransferRemark string `json:"transfer_remark"`  // 转账备注
-	FailReason     string `json:"fail_reason"`      // 失败原因
-	Openid         string `json:"openid"`           // 收款用户OpenID
-	UserName       string `json:"user_name"`        // 收款用户姓名
-	CreateTime     string `json:"create_time"`      // 创建时间
-	UpdateTime     string `json:"update_time"`      // 更新时间
+	Mchid          string         `json:"mchid"`            // 商户号
+	OutBillNo      string         `json:"out_bill_no"`      // 商户单号
+	TransferBillNo string         `json:"transfer_bill_no"` // 微信转账单号
+	Appid          string         `json:"appid"`            // 应用ID
+	State          string         `json:"state"`            // 转账状态
+	TransferAmount vwxmoney.Money `json:"transfer_amount"`  // 转账金额
+	TransferRemark string         `json:"transfer_remark"`  // 转账备注
+	FailReason     string         `json:"fail_reason"`      // 失败原因
+	Openid         string         `json:"openid"`           // 收款用户OpenID
+	UserName       string         `json:"user_name"`        // 收款用户姓名
+	CreateTime     string         `json:"create_time"`      // 创建时间
+	UpdateTime     string         `json:"update_time"`      // 更新时间
 }
 
 // QueryTransferByOutBillNo 商户单号查询转账单
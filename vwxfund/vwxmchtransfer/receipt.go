@@ -0,0 +1,141 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+// ReceiptBaseURL 商家转账电子回单接口地址前缀
+const ReceiptBaseURL = "https://api.mch.weixin.qq.com/v3/fund-app/mch-transfer/elecsign"
+
+const (
+	ReceiptStateGenerating = "GENERATING" // 电子回单生成中
+	ReceiptStateFinished   = "FINISHED"   // 电子回单已生成
+	ReceiptStateFailed     = "FAILED"     // 电子回单生成失败
+)
+
+// ReceiptApplyResponse 申请电子回单响应
+type ReceiptApplyResponse struct {
+	State      string `json:"state"`       // 电子回单状态
+	CreateTime string `json:"create_time"` // 电子回单申请时间
+}
+
+// Receipt 电子回单
+type Receipt struct {
+	State       string `json:"state"`        // 电子回单状态
+	CreateTime  string `json:"create_time"`  // 电子回单申请时间
+	UpdateTime  string `json:"update_time"`  // 电子回单状态更新时间
+	HashType    string `json:"hash_type"`    // 电子回单文件摘要算法, 如 SHA256
+	HashValue   string `json:"hash_value"`   // 电子回单文件摘要值
+	DownloadURL string `json:"download_url"` // 电子回单文件下载地址, 需使用带签名的请求下载
+}
+
+// IsFinished 电子回单是否已生成
+func (r *Receipt) IsFinished() bool {
+	return r.State == ReceiptStateFinished
+}
+
+// IsFailed 电子回单是否生成失败
+func (r *Receipt) IsFailed() bool {
+	return r.State == ReceiptStateFailed
+}
+
+// ApplyReceiptByOutBillNo 商户单号申请电子回单
+// 电子回单为异步生成, 申请后需调用 QueryReceiptByOutBillNo 查询生成状态
+func (c *MchTransferClient) ApplyReceiptByOutBillNo(ctx context.Context, outBillNo string) (*ReceiptApplyResponse, error) {
+	vlog.Infof("apply transfer receipt | out_bill_no: %s", outBillNo)
+
+	var resp ReceiptApplyResponse
+	if err := c.postReceipt(ctx, "/out-bill-no", map[string]string{"out_bill_no": outBillNo}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// ApplyReceiptByTransferBillNo 微信单号申请电子回单
+func (c *MchTransferClient) ApplyReceiptByTransferBillNo(ctx context.Context, transferBillNo string) (*ReceiptApplyResponse, error) {
+	vlog.Infof("apply transfer receipt | transfer_bill_no: %s", transferBillNo)
+
+	var resp ReceiptApplyResponse
+	if err := c.postReceipt(ctx, "/transfer-bill-no", map[string]string{"transfer_bill_no": transferBillNo}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// QueryReceiptByOutBillNo 商户单号查询电子回单
+func (c *MchTransferClient) QueryReceiptByOutBillNo(ctx context.Context, outBillNo string) (*Receipt, error) {
+	var receipt Receipt
+	if err := c.getReceipt(ctx, "/out-bill-no/"+url.PathEscape(outBillNo), &receipt); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// QueryReceiptByTransferBillNo 微信单号查询电子回单
+func (c *MchTransferClient) QueryReceiptByTransferBillNo(ctx context.Context, transferBillNo string) (*Receipt, error) {
+	var receipt Receipt
+	if err := c.getReceipt(ctx, "/transfer-bill-no/"+url.PathEscape(transferBillNo), &receipt); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// DownloadReceipt 下载已生成的电子回单PDF文件, 并按查询返回的摘要校验文件完整性
+func (c *MchTransferClient) DownloadReceipt(ctx context.Context, receipt *Receipt) ([]byte, error) {
+	if !receipt.IsFinished() {
+		return nil, fmt.Errorf("receipt is not finished: %s", receipt.State)
+	}
+
+	body, err := c.mgr.Download(ctx, receipt.DownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("download receipt error: %w", err)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read receipt error: %w", err)
+	}
+
+	if err := vwxutils.VerifyHash(data, receipt.HashType, receipt.HashValue); err != nil {
+		return nil, fmt.Errorf("verify receipt error: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *MchTransferClient) postReceipt(ctx context.Context, path string, req, resp any) error {
+	return c.mgr.RequestJSON(ctx, "transfer receipt", http.MethodPost, ReceiptBaseURL+path, nil, nil, req, resp)
+}
+
+func (c *MchTransferClient) getReceipt(ctx context.Context, path string, resp any) error {
+	return c.mgr.RequestJSON(ctx, "transfer receipt", http.MethodGet, ReceiptBaseURL+path, nil, nil, nil, resp)
+}
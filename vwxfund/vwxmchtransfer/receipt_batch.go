@@ -0,0 +1,180 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	defaultReceiptPollInterval = 5 * time.Second
+	defaultReceiptPollTimeout  = 2 * time.Minute
+)
+
+// BillNoSource 提供某日发起的转账商户单号, 如从本地转账记录中查询
+type BillNoSource func(ctx context.Context, day time.Time) ([]string, error)
+
+// ReceiptHandler 电子回单下载完成时的回调, 如保存PDF文件
+type ReceiptHandler func(ctx context.Context, outBillNo string, pdf []byte) error
+
+// FetchReceiptsRequest 批量获取电子回单请求
+type FetchReceiptsRequest struct {
+	Start        time.Time     // 开始日期, 按北京时间自然日
+	End          time.Time     // 结束日期(含), 按北京时间自然日
+	Source       BillNoSource  // 提供每日的转账商户单号
+	PollInterval time.Duration // 查询电子回单生成状态的间隔, 默认5秒
+	PollTimeout  time.Duration // 单个电子回单等待生成的最长时间, 默认2分钟
+}
+
+// FetchReceiptsResult 批量获取电子回单结果
+type FetchReceiptsResult struct {
+	Succeeded []string         // 获取成功的商户单号
+	Failed    map[string]error // 获取失败的商户单号及原因
+}
+
+// receiptFetcher 电子回单接口, 由 MchTransferClient 实现
+type receiptFetcher interface {
+	ApplyReceiptByOutBillNo(ctx context.Context, outBillNo string) (*ReceiptApplyResponse, error)
+	QueryReceiptByOutBillNo(ctx context.Context, outBillNo string) (*Receipt, error)
+	DownloadReceipt(ctx context.Context, receipt *Receipt) ([]byte, error)
+}
+
+// FetchReceipts 批量获取日期范围内转账的电子回单
+// 按日从 Source 获取转账商户单号, 逐笔申请电子回单, 等待生成后下载并校验, 再交由 handler 处理.
+// 单笔失败不影响其他转账, 失败原因记录在结果中; 仅获取商户单号失败或 ctx 取消时返回错误.
+func (c *MchTransferClient) FetchReceipts(ctx context.Context, req *FetchReceiptsRequest, handler ReceiptHandler) (*FetchReceiptsResult, error) {
+	return fetchReceipts(ctx, c, req, handler)
+}
+
+func fetchReceipts(ctx context.Context, client receiptFetcher, req *FetchReceiptsRequest, handler ReceiptHandler) (*FetchReceiptsResult, error) {
+	if req.Source == nil {
+		return nil, fmt.Errorf("bill no source is nil")
+	}
+
+	if handler == nil {
+		return nil, fmt.Errorf("receipt handler is nil")
+	}
+
+	days, err := receiptDays(req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+
+	interval, timeout := req.PollInterval, req.PollTimeout
+	if interval <= 0 {
+		interval = defaultReceiptPollInterval
+	}
+	if timeout <= 0 {
+		timeout = defaultReceiptPollTimeout
+	}
+
+	result := &FetchReceiptsResult{Failed: make(map[string]error)}
+
+	for _, day := range days {
+		outBillNos, err := req.Source(ctx, day)
+		if err != nil {
+			return result, fmt.Errorf("list transfer bills of %s error: %w", day.Format(time.DateOnly), err)
+		}
+
+		for _, outBillNo := range outBillNos {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+
+			pdf, err := fetchReceipt(ctx, client, outBillNo, interval, timeout)
+			if err == nil {
+				err = handler(ctx, outBillNo, pdf)
+			}
+
+			if err != nil {
+				vlog.Errorf("fetch transfer receipt error | out_bill_no: %s | err: %v", outBillNo, err)
+				result.Failed[outBillNo] = err
+				continue
+			}
+
+			result.Succeeded = append(result.Succeeded, outBillNo)
+		}
+	}
+
+	return result, nil
+}
+
+// fetchReceipt 申请电子回单并等待生成后下载
+func fetchReceipt(ctx context.Context, client receiptFetcher, outBillNo string, interval, timeout time.Duration) ([]byte, error) {
+	// 重复申请时接口可能返回错误, 继续查询已申请的电子回单
+	if _, err := client.ApplyReceiptByOutBillNo(ctx, outBillNo); err != nil {
+		vlog.Warnf("apply transfer receipt error | out_bill_no: %s | err: %v", outBillNo, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		receipt, err := client.QueryReceiptByOutBillNo(ctx, outBillNo)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case receipt.IsFinished():
+			return client.DownloadReceipt(ctx, receipt)
+		case receipt.IsFailed():
+			return nil, fmt.Errorf("receipt generate failed")
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return nil, fmt.Errorf("receipt not generated in %s: %s", timeout, receipt.State)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// receiptDays 按北京时间列出起止日期(含)之间的每一天
+func receiptDays(start, end time.Time) ([]time.Time, error) {
+	if start.IsZero() || end.IsZero() {
+		return nil, fmt.Errorf("start and end are required")
+	}
+
+	startDay := truncateDay(start)
+	endDay := truncateDay(end)
+	if endDay.Before(startDay) {
+		return nil, fmt.Errorf("end %s is before start %s", endDay.Format(time.DateOnly), startDay.Format(time.DateOnly))
+	}
+
+	var days []time.Time
+	for day := startDay; !day.After(endDay); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.In(vwxutils.ChinaLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, vwxutils.ChinaLocation)
+}
@@ -0,0 +1,98 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+type fakeReceiptFetcher struct {
+	states  map[string][]string // 每次查询依次返回的状态
+	applied []string
+}
+
+func (c *fakeReceiptFetcher) ApplyReceiptByOutBillNo(_ context.Context, outBillNo string) (*ReceiptApplyResponse, error) {
+	c.applied = append(c.applied, outBillNo)
+	return &ReceiptApplyResponse{State: ReceiptStateGenerating}, nil
+}
+
+func (c *fakeReceiptFetcher) QueryReceiptByOutBillNo(_ context.Context, outBillNo string) (*Receipt, error) {
+	states := c.states[outBillNo]
+	state := states[0]
+	if len(states) > 1 {
+		c.states[outBillNo] = states[1:]
+	}
+	return &Receipt{State: state}, nil
+}
+
+func (c *fakeReceiptFetcher) DownloadReceipt(_ context.Context, receipt *Receipt) ([]byte, error) {
+	return []byte("%PDF"), nil
+}
+
+func TestFetchReceipts(t *testing.T) {
+	client := &fakeReceiptFetcher{states: map[string][]string{
+		"T001": {ReceiptStateGenerating, ReceiptStateFinished},
+		"T002": {ReceiptStateFailed},
+		"T003": {ReceiptStateFinished},
+	}}
+
+	day1 := time.Date(2025, 6, 1, 10, 0, 0, 0, vwxutils.ChinaLocation)
+	day2 := day1.AddDate(0, 0, 1)
+
+	source := func(_ context.Context, day time.Time) ([]string, error) {
+		if day.Equal(truncateDay(day1)) {
+			return []string{"T001", "T002"}, nil
+		}
+		return []string{"T003"}, nil
+	}
+
+	saved := map[string][]byte{}
+	result, err := fetchReceipts(context.Background(), client, &FetchReceiptsRequest{
+		Start:        day1,
+		End:          day2,
+		Source:       source,
+		PollInterval: time.Millisecond,
+		PollTimeout:  time.Second,
+	}, func(_ context.Context, outBillNo string, pdf []byte) error {
+		saved[outBillNo] = pdf
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Succeeded) != 2 || len(result.Failed) != 1 || result.Failed["T002"] == nil {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if len(saved) != 2 || len(client.applied) != 3 {
+		t.Errorf("unexpected saved receipts: %v, applied: %v", saved, client.applied)
+	}
+
+	if _, err := receiptDays(day2, day1); err == nil {
+		t.Error("expected error when end is before start")
+	}
+
+	if _, err := fetchReceipts(context.Background(), client, &FetchReceiptsRequest{Start: day1, End: day2, Source: source}, nil); err == nil {
+		t.Error("expected error for nil handler")
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// MaxReportInfoTypeChars 报备信息类型最大字符数
+	MaxReportInfoTypeChars = 15
+	// MaxReportInfoContentChars 报备信息内容最大字符数
+	MaxReportInfoContentChars = 32
+)
+
+// 常用转账场景ID, 可前往"商户平台-产品中心-商家转账"中申请
+const (
+	SceneCashMarketing     = "1000" // 现金营销
+	SceneCommission        = "1005" // 佣金报酬
+	SceneReimbursement     = "1006" // 企业报销
+	ScenePurchasePayment   = "1009" // 采购货款
+	SceneSecondHandRecycle = "1010" // 二手回收
+	ScenePublicWelfare     = "1011" // 公益补助
+)
+
+// TransferScene 转账场景及其报备要求
+type TransferScene struct {
+	ID              string   // 转账场景ID
+	Name            string   // 转账场景名称
+	ReportInfoTypes []string // 必须报备的信息类型, 每种类型需提供且仅提供一条报备信息
+	RecvPerceptions []string // 可选的用户收款感知, 为空时不校验
+}
+
+// Validate 校验转账请求的报备信息及用户收款感知是否符合场景要求
+func (s *TransferScene) Validate(req *TransferRequest) error {
+	required := make(map[string]bool, len(s.ReportInfoTypes))
+	for _, infoType := range s.ReportInfoTypes {
+		required[infoType] = false
+	}
+
+	for i, info := range req.TransferSceneReportInfos {
+		if info == nil {
+			return fmt.Errorf("transfer_scene_report_infos[%d] is nil", i)
+		}
+		reported, ok := required[info.InfoType]
+		if !ok {
+			return fmt.Errorf("info_type %q is not allowed in transfer scene %s(%s), expect %v", info.InfoType, s.ID, s.Name, s.ReportInfoTypes)
+		}
+		if reported {
+			return fmt.Errorf("info_type %q is reported more than once in transfer scene %s(%s)", info.InfoType, s.ID, s.Name)
+		}
+		if utf8.RuneCountInString(info.InfoType) > MaxReportInfoTypeChars {
+			return fmt.Errorf("info_type %q exceeds %d characters", info.InfoType, MaxReportInfoTypeChars)
+		}
+		if info.InfoContent == "" || utf8.RuneCountInString(info.InfoContent) > MaxReportInfoContentChars {
+			return fmt.Errorf("info_content of %q must be 1-%d characters", info.InfoType, MaxReportInfoContentChars)
+		}
+		required[info.InfoType] = true
+	}
+
+	for _, infoType := range s.ReportInfoTypes {
+		if !required[infoType] {
+			return fmt.Errorf("info_type %q is required in transfer scene %s(%s)", infoType, s.ID, s.Name)
+		}
+	}
+
+	if req.UserRecvPerception != "" && len(s.RecvPerceptions) > 0 {
+		for _, perception := range s.RecvPerceptions {
+			if perception == req.UserRecvPerception {
+				return nil
+			}
+		}
+		return fmt.Errorf("user_recv_perception %q is not allowed in transfer scene %s(%s), expect %v",
+			req.UserRecvPerception, s.ID, s.Name, s.RecvPerceptions)
+	}
+
+	return nil
+}
+
+// SceneRegistry 转账场景注册表
+type SceneRegistry struct {
+	mu     sync.RWMutex
+	scenes map[string]*TransferScene
+}
+
+// NewSceneRegistry 创建转账场景注册表
+func NewSceneRegistry(scenes ...*TransferScene) *SceneRegistry {
+	r := &SceneRegistry{
+		scenes: make(map[string]*TransferScene, len(scenes)),
+	}
+
+	for _, scene := range scenes {
+		r.Register(scene)
+	}
+
+	return r
+}
+
+// Register 注册或覆盖转账场景, 用于补充商户开通的其他场景或调整报备要求
+func (r *SceneRegistry) Register(scene *TransferScene) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.scenes[scene.ID] = scene
+}
+
+// Get 获取转账场景
+func (r *SceneRegistry) Get(id string) (*TransferScene, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scene, ok := r.scenes[id]
+	return scene, ok
+}
+
+// List 按场景ID列出全部转账场景
+func (r *SceneRegistry) List() []*TransferScene {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]*TransferScene, 0, len(r.scenes))
+	for _, scene := range r.scenes {
+		list = append(list, scene)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+// Validate 校验转账请求的报备信息, 未注册的场景不校验
+func (r *SceneRegistry) Validate(req *TransferRequest) error {
+	scene, ok := r.Get(req.TransferSceneId)
+	if !ok {
+		return nil
+	}
+
+	return scene.Validate(req)
+}
+
+// DefaultScenes 默认转账场景注册表, 包含常用的转账场景, 发起转账前按其校验报备信息
+var DefaultScenes = NewSceneRegistry(
+	&TransferScene{
+		ID:              SceneCashMarketing,
+		Name:            "现金营销",
+		ReportInfoTypes: []string{"活动名称", "奖励说明"},
+		RecvPerceptions: []string{"活动奖励", "现金奖励"},
+	},
+	&TransferScene{
+		ID:              SceneCommission,
+		Name:            "佣金报酬",
+		ReportInfoTypes: []string{"岗位类型", "报酬说明"},
+		RecvPerceptions: []string{"劳务报酬", "报酬收入"},
+	},
+	&TransferScene{
+		ID:              SceneReimbursement,
+		Name:            "企业报销",
+		ReportInfoTypes: []string{"报销类型", "报销说明"},
+	},
+	&TransferScene{
+		ID:              ScenePurchasePayment,
+		Name:            "采购货款",
+		ReportInfoTypes: []string{"采购商品名称"},
+	},
+	&TransferScene{
+		ID:              SceneSecondHandRecycle,
+		Name:            "二手回收",
+		ReportInfoTypes: []string{"回收商品名称"},
+	},
+	&TransferScene{
+		ID:              ScenePublicWelfare,
+		Name:            "公益补助",
+		ReportInfoTypes: []string{"公益活动名称", "公益活动备案编号"},
+	},
+)
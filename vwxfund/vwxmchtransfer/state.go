@@ -73,3 +73,8 @@ func IsStateCancel(state string) bool {
 func IsStateFail(state string) bool {
 	return state == StateFail
 }
+
+// IsStateTerminal 转账单是否已进入终态
+func IsStateTerminal(state string) bool {
+	return state == StateSuccess || state == StateFail || state == StateCancelled
+}
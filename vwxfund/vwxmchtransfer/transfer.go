@@ -18,15 +18,28 @@
 package vwxmchtransfer
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
 )
 
+// userNameRequiredFen 转账金额达到2000元时必须传入收款用户姓名
+const userNameRequiredFen = 200000
+
+// UserNameRequiredAmount 转账金额达到该金额(2000元)时必须传入收款用户姓名, 批量转账的明细同样适用
+func UserNameRequiredAmount() vwxmoney.Money {
+	return vwxmoney.Fen(userNameRequiredFen)
+}
+
+// ErrUserNameRequired 转账金额达到2000元时未传入收款用户姓名
+var ErrUserNameRequired = errors.New("user_name is required when transfer amount >= 2000 yuan")
+
 // TransferSceneReportInfo 转账场景报备信息, 参考 https://pay.weixin.qq.com/doc/v3/merchant/4013774588
 type TransferSceneReportInfo struct {
 	InfoType    string `json:"info_type,omitempty"`    // 【信息类型】 不能超过15个字符，商户所属转账场景下的信息类型，此字段内容为固定值, 信息类型，两条明细中必须分别填写以下两个取值：活动名称,奖励说明
@@ -35,16 +48,48 @@ type TransferSceneReportInfo struct {
 
 // TransferRequest 发起转账请求
 type TransferRequest struct {
-	Appid                    string                     `json:"appid"`                          // 商户AppID
-	OutBillNo                string                     `json:"out_bill_no"`                    // 商户单号
-	TransferSceneId          string                     `json:"transfer_scene_id"`              // 转账场景ID, 可前往“商户平台-产品中心-商家转账”中申请。如：1000（现金营销），1006（企业报销）等
-	Openid                   string                     `json:"openid"`                         // 收款用户OpenID
-	UserName                 string                     `json:"user_name,omitempty"`            // 收款用户姓名
-	TransferAmount           int64                      `json:"transfer_amount"`                // 转账金额
-	TransferRemark           string                     `json:"transfer_remark"`                // 转账备注
-	NotifyUrl                string                     `json:"notify_url,omitempty"`           // 通知地址
-	UserRecvPerception       string                     `json:"user_recv_perception,omitempty"` // 用户收款感知, 参考 https://pay.weixin.qq.com/doc/v3/merchant/4012711988#2.3-%E5%8F%91%E8%B5%B7%E8%BD%AC%E8%B4%A6
-	TransferSceneReportInfos []*TransferSceneReportInfo `json:"transfer_scene_report_infos"`    // 转账场景报备信息
+	Appid                    string                     `json:"appid"`                                     // 商户AppID
+	OutBillNo                string                     `json:"out_bill_no"`                               // 商户单号
+	TransferSceneId          string                     `json:"transfer_scene_id"`                         // 转账场景ID, 可前往“商户平台-产品中心-商家转账”中申请。如：1000（现金营销），1006（企业报销）等
+	Openid                   string                     `json:"openid"`                                    // 收款用户OpenID
+	UserName                 string                     `json:"user_name,omitempty" encryption:"EM_APIV3"` // 收款用户姓名, 传明文, 请求时自动加密
+	TransferAmount           vwxmoney.Money             `json:"transfer_amount"`                           // 转账金额
+	TransferRemark           string                     `json:"transfer_remark"`                           // 转账备注
+	NotifyUrl                string                     `json:"notify_url,omitempty"`                      // 通知地址
+	UserRecvPerception       string                     `json:"user_recv_perception,omitempty"`            // 用户收款感知, 参考 https://pay.weixin.qq.com/doc/v3/merchant/4012711988#2.3-%E5%8F%91%E8%B5%B7%E8%BD%AC%E8%B4%A6
+	TransferSceneReportInfos []*TransferSceneReportInfo `json:"transfer_scene_report_infos"`               // 转账场景报备信息
+}
+
+// Validate 校验转账请求, 包括按 DefaultScenes 校验转账场景的报备信息
+func (r *TransferRequest) Validate() error {
+	if r.OutBillNo == "" {
+		return fmt.Errorf("out_bill_no is empty")
+	}
+
+	if r.TransferSceneId == "" {
+		return fmt.Errorf("transfer_scene_id is empty")
+	}
+
+	if r.Openid == "" {
+		return fmt.Errorf("openid is empty")
+	}
+
+	if !r.TransferAmount.IsPositive() {
+		return fmt.Errorf("transfer_amount must be greater than 0: %s", r.TransferAmount)
+	}
+
+	if r.UserName == "" {
+		cmp, err := r.TransferAmount.Cmp(UserNameRequiredAmount())
+		if err != nil {
+			return err
+		}
+		if cmp >= 0 {
+			return fmt.Errorf("%w: %s", ErrUserNameRequired, r.TransferAmount)
+		}
+	}
+
+	// 按转账场景校验报备信息及用户收款感知
+	return DefaultScenes.Validate(r)
 }
 
 // TransferBillsResponse 转账单信息
@@ -62,7 +107,7 @@ type TransferBillsResponse struct {
 // outBillNo: 商户单号
 // transferSceneId: 转账场景ID
 // openid: 收款用户OpenID
-// transferAmount: 转账金额（单位：分）
+// transferAmount: 转账金额
 // transferRemark: 转账备注
 // userName: 收款用户姓名（可选，转账金额>=2000元时必填）
 // notifyUrl: 通知地址（可选）
@@ -71,7 +116,7 @@ type TransferBillsResponse struct {
 func (c *MchTransferClient) Transfer(ctx context.Context,
 	appID string,
 	outBillNo, transferSceneId, openid string,
-	transferAmount int64,
+	transferAmount vwxmoney.Money,
 	transferRemark string,
 	userName, notifyUrl, userRecvPerception string,
 	transferSceneReportInfos []*TransferSceneReportInfo,
@@ -105,19 +150,24 @@ func (c *MchTransferClient) Transfer(ctx context.Context,
 	return c.DoTransfer(ctx, &req)
 }
 
+// DoTransfer 发起转账
+// 收款用户姓名传明文, 请求时自动使用平台证书加密并传递 Wechatpay-Serial 头.
 func (c *MchTransferClient) DoTransfer(ctx context.Context, req *TransferRequest) (*TransferBillsResponse, error) {
-	// 序列化请求数据
-	reqBody, err := json.Marshal(req)
-	if err != nil {
-		vlog.Errorf("marshal transfer request error | err: %v", err)
+	if err := req.Validate(); err != nil {
 		return nil, err
 	}
 
-	vlog.Infof("mch transfer request | body: %s", reqBody)
+	vlog.Infof("mch transfer request | out_bill_no: %s | openid: %s | amount: %s | scene: %s",
+		req.OutBillNo, req.Openid, req.TransferAmount, req.TransferSceneId)
+
+	body, header, err := c.mgr.PlatManager.EncryptRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt transfer request error: %w", err)
+	}
 
 	// 发送HTTP请求
 	url := "https://api.mch.weixin.qq.com/v3/fund-app/mch-transfer/transfer-bills"
-	result, err := c.mgr.Client.Post(ctx, url, bytes.NewBuffer(reqBody))
+	result, err := c.mgr.Client.Request(ctx, http.MethodPost, url, header, nil, body, "")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,113 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+func TestTransferRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     TransferRequest
+		wantErr error
+	}{
+		{"small amount without name", TransferRequest{OutBillNo: "T001", TransferSceneId: "9999", Openid: "o001", TransferAmount: vwxmoney.MustParseYuan("1999.99")}, nil},
+		{"large amount with name", TransferRequest{OutBillNo: "T001", TransferSceneId: "9999", Openid: "o001", TransferAmount: vwxmoney.MustParseYuan("2000"), UserName: "张三"}, nil},
+		{"large amount without name", TransferRequest{OutBillNo: "T001", TransferSceneId: "9999", Openid: "o001", TransferAmount: vwxmoney.MustParseYuan("2000")}, ErrUserNameRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (&TransferRequest{OutBillNo: "T001", TransferSceneId: "9999", Openid: "o001"}).Validate(); err == nil {
+		t.Error("expected error for zero amount")
+	}
+}
+
+func TestNewConfirmPayload(t *testing.T) {
+	payload, err := newConfirmPayload("1900000100", "wx001", &TransferBillsResponse{OutBillNo: "T001", State: StateWaitUserConfirm, PackageInfo: "affffddafdfafddffda=="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.MchID != "1900000100" || payload.AppID != "wx001" || payload.Package != "affffddafdfafddffda==" {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+
+	if _, err := newConfirmPayload("1900000100", "wx001", &TransferBillsResponse{OutBillNo: "T001", State: StateSuccess}); !errors.Is(err, ErrNotConfirmable) {
+		t.Errorf("expected ErrNotConfirmable, got %v", err)
+	}
+}
+
+func TestSceneValidate(t *testing.T) {
+	req := &TransferRequest{
+		OutBillNo:       "T001",
+		TransferSceneId: SceneCashMarketing,
+		Openid:          "o001",
+		TransferAmount:  vwxmoney.Fen(100),
+		TransferSceneReportInfos: []*TransferSceneReportInfo{
+			{InfoType: "活动名称", InfoContent: "新会员有礼"},
+			{InfoType: "奖励说明", InfoContent: "注册会员抽奖一等奖"},
+		},
+		UserRecvPerception: "现金奖励",
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	req.UserRecvPerception = "劳务报酬"
+	if err := req.Validate(); err == nil {
+		t.Error("expected error for invalid user_recv_perception")
+	}
+
+	req.UserRecvPerception = ""
+	req.TransferSceneReportInfos = req.TransferSceneReportInfos[:1]
+	if err := req.Validate(); err == nil {
+		t.Error("expected error for missing report info")
+	}
+
+	req.TransferSceneReportInfos = append(req.TransferSceneReportInfos, &TransferSceneReportInfo{InfoType: "岗位类型", InfoContent: "外卖员"})
+	if err := req.Validate(); err == nil {
+		t.Error("expected error for unexpected report info type")
+	}
+
+	req.TransferSceneReportInfos = []*TransferSceneReportInfo{
+		{InfoType: "活动名称", InfoContent: "新会员有礼"},
+		{InfoType: "活动名称", InfoContent: "老会员回馈"},
+		{InfoType: "奖励说明", InfoContent: "注册会员抽奖一等奖"},
+	}
+	if err := req.Validate(); err == nil {
+		t.Error("expected error for duplicate report info type")
+	}
+
+	req.TransferSceneReportInfos = []*TransferSceneReportInfo{
+		{InfoType: "活动名称", InfoContent: "新会员有礼"},
+		nil,
+	}
+	if err := req.Validate(); err == nil {
+		t.Error("expected error for nil report info")
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	defaultWatchTickInterval   = 10 * time.Second
+	defaultWatchInitialBackoff = 30 * time.Second
+	defaultWatchMaxBackoff     = 30 * time.Minute
+)
+
+// PendingTransfer 待确认结果的转账单
+type PendingTransfer struct {
+	OutBillNo       string    `json:"out_bill_no"`      // 商户单号
+	ConfirmDeadline time.Time `json:"confirm_deadline"` // 用户确认收款截止时间, 超过后仍待用户确认则自动撤销, 零值表示不自动撤销
+	CancelRequested bool      `json:"cancel_requested"` // 是否已请求撤销
+
+	vwxutils.PollSchedule
+}
+
+func pendingTransferKey(transfer *PendingTransfer) string {
+	return transfer.OutBillNo
+}
+
+// PendingTransferStore 待确认转账单存储, 实现持久化存储可在服务重启后继续跟踪转账, Remove 的 key 为商户单号
+type PendingTransferStore = vwxutils.PollStore[PendingTransfer]
+
+// MemoryPendingTransferStore 基于内存的待确认转账单存储
+type MemoryPendingTransferStore = vwxutils.MemoryPollStore[PendingTransfer, *PendingTransfer]
+
+// NewMemoryPendingTransferStore 创建基于内存的待确认转账单存储
+func NewMemoryPendingTransferStore() *MemoryPendingTransferStore {
+	return vwxutils.NewMemoryPollStore(pendingTransferKey)
+}
+
+// TransferWatchHandler 转账单进入终态(转账成功、转账失败、撤销完成)时的回调
+type TransferWatchHandler func(ctx context.Context, pending *PendingTransfer, transfer *QueryTransferResponse)
+
+// TransferGiveUpHandler 转账单超过最大查询次数仍未确认结果, 放弃跟踪时的回调
+type TransferGiveUpHandler func(ctx context.Context, pending *PendingTransfer)
+
+// transferQuerier 转账查询及撤销接口, 由 MchTransferClient 实现
+type transferQuerier interface {
+	QueryTransferByOutBillNo(ctx context.Context, outBillNo string) (*QueryTransferResponse, error)
+	CancelTransfer(ctx context.Context, outBillNo string) (*CancelTransferResponse, error)
+}
+
+// TransferWatcher 转账结果跟踪器
+// 按退避间隔轮询查询转账单, 进入终态时回调; 超过确认截止时间仍待用户确认或转账中的转账单自动撤销, 避免长时间占用运营账户余额.
+type TransferWatcher struct {
+	client          transferQuerier
+	handler         TransferWatchHandler
+	poller          *vwxutils.Poller[PendingTransfer, *PendingTransfer]
+	autoCancelAfter time.Duration
+}
+
+// TransferWatcherOption 转账跟踪器可选项
+type TransferWatcherOption func(*TransferWatcher)
+
+// WithPendingTransferStore 设置待确认转账单存储, 默认使用内存存储
+func WithPendingTransferStore(store PendingTransferStore) TransferWatcherOption {
+	return func(w *TransferWatcher) { w.poller.Store = store }
+}
+
+// WithWatchBackoff 设置轮询查询的退避间隔
+func WithWatchBackoff(initial, max time.Duration) TransferWatcherOption {
+	return func(w *TransferWatcher) { w.poller.Backoff = vwxutils.NewBackoff(initial, max) }
+}
+
+// WithWatchTickInterval 设置扫描待确认转账单的间隔
+func WithWatchTickInterval(interval time.Duration) TransferWatcherOption {
+	return func(w *TransferWatcher) { w.poller.TickInterval = interval }
+}
+
+// WithWatchMaxAttempts 设置最大查询次数, 超过后回调 handler 并放弃跟踪, 默认不限制
+func WithWatchMaxAttempts(max int, handler TransferGiveUpHandler) TransferWatcherOption {
+	return func(w *TransferWatcher) {
+		w.poller.MaxAttempts = max
+		w.poller.GiveUp = handler
+	}
+}
+
+// WithAutoCancelAfter 设置自动撤销时限, 转账单自开始跟踪起超过该时长仍待用户确认时自动撤销, 默认不自动撤销
+func WithAutoCancelAfter(d time.Duration) TransferWatcherOption {
+	return func(w *TransferWatcher) { w.autoCancelAfter = d }
+}
+
+// NewTransferWatcher 创建转账结果跟踪器, 后台任务运行在 Manager 的 Runner 上, Manager 停止时随之停止
+func NewTransferWatcher(client *MchTransferClient, handler TransferWatchHandler, opts ...TransferWatcherOption) *TransferWatcher {
+	return newTransferWatcher(client, client.mgr.Runner().NewChild(), handler, opts...)
+}
+
+func newTransferWatcher(client transferQuerier, runner *vrun.Runner, handler TransferWatchHandler, opts ...TransferWatcherOption) *TransferWatcher {
+	w := &TransferWatcher{
+		client:  client,
+		handler: handler,
+	}
+
+	w.poller = vwxutils.NewPoller("pending transfer", runner, pendingTransferKey, w.check)
+	w.poller.Backoff = vwxutils.NewBackoff(defaultWatchInitialBackoff, defaultWatchMaxBackoff)
+	w.poller.TickInterval = defaultWatchTickInterval
+	// 退避间隔较长时, 在确认截止时间及时查询以便撤销
+	w.poller.Deadline = func(transfer *PendingTransfer) time.Time {
+		if transfer.CancelRequested {
+			return time.Time{}
+		}
+		return transfer.ConfirmDeadline
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Start 启动后台轮询, 存储中已有的转账单会继续被跟踪
+func (w *TransferWatcher) Start() {
+	w.poller.Start()
+}
+
+// Stop 停止后台轮询
+func (w *TransferWatcher) Stop() {
+	w.poller.Stop()
+}
+
+// Watch 跟踪转账结果, 配置了自动撤销时限时按该时限计算确认截止时间
+func (w *TransferWatcher) Watch(ctx context.Context, outBillNo string) error {
+	var deadline time.Time
+	if w.autoCancelAfter > 0 {
+		deadline = time.Now().Add(w.autoCancelAfter)
+	}
+
+	return w.WatchWithDeadline(ctx, outBillNo, deadline)
+}
+
+// WatchWithDeadline 跟踪转账结果, 超过 deadline 仍待用户确认时自动撤销, deadline 为零值时不自动撤销
+func (w *TransferWatcher) WatchWithDeadline(ctx context.Context, outBillNo string, deadline time.Time) error {
+	vlog.Infof("watch transfer | out_bill_no: %s | confirm_deadline: %s", outBillNo, deadline.Format(time.RFC3339))
+
+	return w.poller.Schedule(ctx, &PendingTransfer{
+		OutBillNo:       outBillNo,
+		ConfirmDeadline: deadline,
+	})
+}
+
+// Unwatch 取消跟踪转账, 如已收到转账结果通知时调用
+func (w *TransferWatcher) Unwatch(ctx context.Context, outBillNo string) error {
+	return w.poller.Remove(ctx, outBillNo)
+}
+
+// check 查询转账状态, 终态时回调, 超过确认截止时间仍待用户确认时撤销, 返回转账单是否已完成
+func (w *TransferWatcher) check(ctx context.Context, pending *PendingTransfer) bool {
+	transfer, err := w.client.QueryTransferByOutBillNo(ctx, pending.OutBillNo)
+	if err != nil {
+		vlog.Errorf("watch transfer query error | out_bill_no: %s | err: %v", pending.OutBillNo, err)
+		return false
+	}
+
+	if IsStateTerminal(transfer.State) {
+		vlog.Infof("watch transfer finished | out_bill_no: %s | state: %s", pending.OutBillNo, transfer.State)
+
+		if w.handler != nil {
+			w.handler(ctx, pending, transfer)
+		}
+		return true
+	}
+
+	// 仅撤销用户未确认的转账单, 转账中的转账单已在打款, 不自动撤销
+	if transfer.State == StateWaitUserConfirm && !pending.CancelRequested &&
+		!pending.ConfirmDeadline.IsZero() && time.Now().After(pending.ConfirmDeadline) {
+		vlog.Infof("transfer confirm deadline exceeded, cancel | out_bill_no: %s | deadline: %s",
+			pending.OutBillNo, pending.ConfirmDeadline.Format(time.RFC3339))
+
+		if _, err := w.client.CancelTransfer(ctx, pending.OutBillNo); err != nil {
+			vlog.Errorf("watch transfer cancel error | out_bill_no: %s | err: %v", pending.OutBillNo, err)
+		} else {
+			// 撤销为异步处理, 继续跟踪直至撤销完成
+			pending.CancelRequested = true
+			pending.Attempts = 0
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmchtransfer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+)
+
+type fakeTransferQuerier struct {
+	states    map[string]string
+	cancelled []string
+}
+
+func (c *fakeTransferQuerier) QueryTransferByOutBillNo(_ context.Context, outBillNo string) (*QueryTransferResponse, error) {
+	state, ok := c.states[outBillNo]
+	if !ok {
+		return nil, &core.APIError{StatusCode: http.StatusNotFound, Code: "NOT_FOUND"}
+	}
+	return &QueryTransferResponse{OutBillNo: outBillNo, State: state}, nil
+}
+
+func (c *fakeTransferQuerier) CancelTransfer(_ context.Context, outBillNo string) (*CancelTransferResponse, error) {
+	c.cancelled = append(c.cancelled, outBillNo)
+	c.states[outBillNo] = StateCanceling
+	return &CancelTransferResponse{OutBillNo: outBillNo, State: StateCanceling}, nil
+}
+
+func TestTransferWatcher(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeTransferQuerier{states: map[string]string{
+		"T001": StateSuccess,
+		"T002": StateWaitUserConfirm,
+		"T003": StateWaitUserConfirm,
+		"T004": StateTransfering,
+	}}
+
+	results := map[string]string{}
+	handler := func(_ context.Context, pending *PendingTransfer, transfer *QueryTransferResponse) {
+		results[pending.OutBillNo] = transfer.State
+	}
+
+	w := newTransferWatcher(client, vrun.New(), handler, WithWatchBackoff(0, time.Minute))
+
+	_ = w.Watch(ctx, "T001")
+	_ = w.WatchWithDeadline(ctx, "T002", time.Now().Add(-time.Second))
+	_ = w.WatchWithDeadline(ctx, "T003", time.Now().Add(time.Hour))
+	_ = w.WatchWithDeadline(ctx, "T004", time.Now().Add(-time.Second))
+
+	w.poller.Poll()
+
+	if results["T001"] != StateSuccess {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	// 已进入转账中的转账单即使超过截止时间也不撤销
+	if len(client.cancelled) != 1 || client.cancelled[0] != "T002" {
+		t.Fatalf("only expired unconfirmed transfer should be cancelled: %v", client.cancelled)
+	}
+
+	// 撤销完成后回调
+	client.states["T002"] = StateCancelled
+	client.states["T004"] = StateSuccess
+	w.poller.Poll()
+
+	if results["T002"] != StateCancelled || results["T004"] != StateSuccess {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	pending, _ := w.poller.Store.List(ctx)
+	if len(pending) != 1 || pending[0].OutBillNo != "T003" || pending[0].CancelRequested {
+		t.Errorf("unexpected pending transfers: %+v", pending)
+	}
+}
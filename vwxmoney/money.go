@@ -0,0 +1,314 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmoney
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CNY 人民币, 微信支付默认货币类型
+const CNY = "CNY"
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount overflow")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Money 金额, 以分为单位保存, 并携带货币类型
+// 零值表示 0 分人民币
+// JSON 序列化为以分为单位的整数, 与微信支付接口中的金额字段保持一致, 仅支持人民币
+type Money struct {
+	fen      int64
+	currency string
+}
+
+// New 创建指定货币类型的金额
+// fen: 金额，单位为分
+// currency: 货币类型, 为空时为CNY
+func New(fen int64, currency string) Money {
+	if currency == CNY {
+		currency = ""
+	}
+	return Money{fen: fen, currency: currency}
+}
+
+// Fen 创建以分为单位的人民币金额
+func Fen(fen int64) Money {
+	return Money{fen: fen}
+}
+
+// ParseYuan 解析以元为单位的人民币金额字符串, 如 "12.34", "-0.5", "100"
+// 最多支持两位小数, 超过两位小数返回 ErrInvalidAmount
+func ParseYuan(s string) (Money, error) {
+	return ParseYuanWithCurrency(s, CNY)
+}
+
+// ParseYuanWithCurrency 解析以元为单位的指定货币类型金额字符串
+func ParseYuanWithCurrency(s, currency string) (Money, error) {
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return Money{}, fmt.Errorf("%w: empty", ErrInvalidAmount)
+	}
+
+	negative := false
+	switch text[0] {
+	case '-':
+		negative = true
+		text = text[1:]
+	case '+':
+		text = text[1:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(text, ".")
+	if intPart == "" || (hasDot && fracPart == "") || len(fracPart) > 2 ||
+		!isDigits(intPart) || !isDigits(fracPart) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	for len(fracPart) < 2 {
+		fracPart += "0"
+	}
+
+	fen, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrOverflow, s)
+	}
+
+	if negative {
+		fen = -fen
+	}
+
+	return New(fen, currency), nil
+}
+
+// MustParseYuan 解析以元为单位的人民币金额字符串, 解析失败时 panic
+func MustParseYuan(s string) Money {
+	m, err := ParseYuan(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Fen 金额，单位为分
+func (m Money) Fen() int64 {
+	return m.fen
+}
+
+// Currency 货币类型
+func (m Money) Currency() string {
+	if m.currency == "" {
+		return CNY
+	}
+	return m.currency
+}
+
+// Yuan 以元为单位的金额字符串, 保留两位小数, 如 "12.34"
+func (m Money) Yuan() string {
+	fen := m.fen
+	sign := ""
+	if fen < 0 {
+		sign = "-"
+	}
+
+	// 使用无符号数避免 math.MinInt64 取反溢出
+	abs := uint64(fen)
+	if fen < 0 {
+		abs = uint64(-(fen + 1)) + 1
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, abs/100, abs%100)
+}
+
+// String 格式化输出, 如 "12.34 CNY"
+func (m Money) String() string {
+	return m.Yuan() + " " + m.Currency()
+}
+
+// IsZero 金额是否为0
+func (m Money) IsZero() bool {
+	return m.fen == 0
+}
+
+// IsPositive 金额是否大于0
+func (m Money) IsPositive() bool {
+	return m.fen > 0
+}
+
+// IsNegative 金额是否小于0
+func (m Money) IsNegative() bool {
+	return m.fen < 0
+}
+
+// SameCurrency 货币类型是否相同
+func (m Money) SameCurrency(o Money) bool {
+	return m.Currency() == o.Currency()
+}
+
+// Add 金额相加, 货币类型不同返回 ErrCurrencyMismatch, 溢出返回 ErrOverflow
+func (m Money) Add(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrCurrencyMismatch, m.Currency(), o.Currency())
+	}
+
+	if (o.fen > 0 && m.fen > math.MaxInt64-o.fen) || (o.fen < 0 && m.fen < math.MinInt64-o.fen) {
+		return Money{}, fmt.Errorf("%w: %d + %d", ErrOverflow, m.fen, o.fen)
+	}
+
+	return Money{fen: m.fen + o.fen, currency: m.currency}, nil
+}
+
+// Sub 金额相减, 货币类型不同返回 ErrCurrencyMismatch, 溢出返回 ErrOverflow
+func (m Money) Sub(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrCurrencyMismatch, m.Currency(), o.Currency())
+	}
+
+	if (o.fen < 0 && m.fen > math.MaxInt64+o.fen) || (o.fen > 0 && m.fen < math.MinInt64+o.fen) {
+		return Money{}, fmt.Errorf("%w: %d - %d", ErrOverflow, m.fen, o.fen)
+	}
+
+	return Money{fen: m.fen - o.fen, currency: m.currency}, nil
+}
+
+// Cmp 比较金额大小, 小于返回-1, 等于返回0, 大于返回1
+func (m Money) Cmp(o Money) (int, error) {
+	if !m.SameCurrency(o) {
+		return 0, fmt.Errorf("%w: %s <> %s", ErrCurrencyMismatch, m.Currency(), o.Currency())
+	}
+
+	switch {
+	case m.fen < o.fen:
+		return -1, nil
+	case m.fen > o.fen:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Allocate 按比例分配金额, 各份额向下取整到分, 余下的分依次分配给前面的份额, 保证各份额之和等于原金额
+// ratios: 分配比例, 不能为负数且总和必须大于0
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("%w: no ratios", ErrInvalidAmount)
+	}
+
+	total := new(big.Int)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidAmount, r)
+		}
+		total.Add(total, big.NewInt(r))
+	}
+
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("%w: sum of ratios is 0", ErrInvalidAmount)
+	}
+
+	amount := big.NewInt(m.fen)
+	results := make([]Money, len(ratios))
+	remainder := m.fen
+
+	for i, r := range ratios {
+		share := new(big.Int).Mul(amount, big.NewInt(r))
+		share.Quo(share, total)
+		results[i] = Money{fen: share.Int64(), currency: m.currency}
+		remainder -= share.Int64()
+	}
+
+	// 余数的绝对值小于份额数, 逐分分配给比例不为0的份额
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(ratios) {
+		if ratios[i] == 0 {
+			continue
+		}
+		results[i].fen += step
+		remainder -= step
+	}
+
+	return results, nil
+}
+
+// Split 将金额平均分为 n 份, 余下的分依次分配给前面的份额
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: split into %d parts", ErrInvalidAmount, n)
+	}
+
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+
+	return m.Allocate(ratios...)
+}
+
+// Sum 计算多个金额之和, 货币类型必须一致
+func Sum(items ...Money) (Money, error) {
+	var total Money
+	for i, item := range items {
+		if i == 0 {
+			total = Money{currency: item.currency}
+		}
+
+		var err error
+		if total, err = total.Add(item); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// MarshalJSON 序列化为以分为单位的整数
+// 整数无法携带货币类型, 非人民币金额返回 ErrCurrencyMismatch, 避免反序列化后被当作人民币.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.currency != "" {
+		return nil, fmt.Errorf("%w: marshal %s amount as fen", ErrCurrencyMismatch, m.currency)
+	}
+	return []byte(strconv.FormatInt(m.fen, 10)), nil
+}
+
+// UnmarshalJSON 从以分为单位的整数反序列化, 货币类型为CNY
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var fen int64
+	if err := json.Unmarshal(data, &fen); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAmount, data)
+	}
+	*m = Money{fen: fen}
+	return nil
+}
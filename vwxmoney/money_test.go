@@ -0,0 +1,146 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxmoney
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseYuan(t *testing.T) {
+	cases := map[string]int64{
+		"12.34": 1234,
+		"0.5":   50,
+		"100":   10000,
+		"-0.01": -1,
+		"+1.10": 110,
+	}
+	for s, fen := range cases {
+		m, err := ParseYuan(s)
+		if err != nil {
+			t.Fatalf("parse %s error: %v", s, err)
+		}
+		if m.Fen() != fen {
+			t.Errorf("parse %s = %d, want %d", s, m.Fen(), fen)
+		}
+	}
+
+	for _, s := range []string{"", "1.234", "abc", "1.", ".5", "1,00", "99999999999999999999"} {
+		if _, err := ParseYuan(s); err == nil {
+			t.Errorf("parse %q should fail", s)
+		}
+	}
+}
+
+func TestYuan(t *testing.T) {
+	cases := map[int64]string{
+		0:             "0.00",
+		1:             "0.01",
+		1234:          "12.34",
+		-5:            "-0.05",
+		-1234:         "-12.34",
+		math.MinInt64: "-92233720368547758.08",
+	}
+	for fen, s := range cases {
+		if got := Fen(fen).Yuan(); got != s {
+			t.Errorf("yuan of %d = %s, want %s", fen, got, s)
+		}
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	sum, err := Fen(100).Add(Fen(23))
+	if err != nil || sum.Fen() != 123 {
+		t.Fatalf("add = %v, %v", sum, err)
+	}
+
+	if _, err = Fen(math.MaxInt64).Add(Fen(1)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+
+	if _, err = Fen(math.MinInt64).Sub(Fen(1)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+
+	if _, err = Fen(1).Add(New(1, "USD")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+
+	if !New(1, CNY).SameCurrency(Fen(1)) {
+		t.Errorf("CNY should equal to default currency")
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	parts, err := Fen(100).Allocate(1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parts[0].Fen() != 34 || parts[1].Fen() != 33 || parts[2].Fen() != 33 {
+		t.Errorf("allocate = %v", parts)
+	}
+
+	parts, err = Fen(-5).Allocate(1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parts[0].Fen() != -3 || parts[1].Fen() != 0 || parts[2].Fen() != -2 {
+		t.Errorf("allocate = %v", parts)
+	}
+
+	parts, err = Fen(math.MaxInt64).Allocate(math.MaxInt64, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total, _ := Sum(parts...); total.Fen() != math.MaxInt64 {
+		t.Errorf("allocate sum = %d", total.Fen())
+	}
+
+	if _, err = Fen(1).Allocate(0, 0); err == nil {
+		t.Errorf("allocate with zero ratios should fail")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type amount struct {
+		Total   Money  `json:"total"`
+		Pending *Money `json:"pending,omitempty"`
+	}
+
+	var v amount
+	if err := json.Unmarshal([]byte(`{"total":1234,"pending":5}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Total.Fen() != 1234 || v.Pending.Fen() != 5 {
+		t.Errorf("unmarshal = %v", v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"total":1234,"pending":5}` {
+		t.Errorf("marshal = %s", b)
+	}
+
+	if _, err = json.Marshal(amount{Total: New(100, "USD")}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("marshal non-CNY amount should fail: %v", err)
+	}
+}
@@ -26,6 +26,8 @@ import (
 
 	"github.com/vogo/vogo/vencoding/vjson"
 	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpayments"
 	"github.com/wechatpay-apiv3/wechatpay-go/core"
 	"github.com/wechatpay-apiv3/wechatpay-go/services/partnerpayments/jsapi"
 	"github.com/wechatpay-apiv3/wechatpay-go/utils"
@@ -35,7 +37,7 @@ import (
 // appID: 商户 appid
 // subMchID: 子商户号
 // userOpenID: 用户唯一标识
-// amount: 金额，单位为分
+// amount: 订单金额
 // outTradeNo: 商户订单号
 // description: 商品描述
 // attach: 附加数据
@@ -44,58 +46,41 @@ import (
 // profitSharing: 是否分账，可选
 func (c *PartnerJsApiClient) Prepay(ctx context.Context,
 	appID, subMchID, userOpenID string,
-	amount int64,
+	amount vwxmoney.Money,
 	outTradeNo, description, attach, callbackUrl string,
 	expireTime time.Time,
 	subAppID string,
 	profitSharing bool,
 ) (*PartnerJsApiPayParams, error) {
-	if appID == "" {
-		appID = c.mgr.Config.AppID
-	}
-
-	req := jsapi.PrepayRequest{
-		SpAppid:     core.String(appID),
-		SpMchid:     core.String(c.mgr.Config.MerchantID),
-		SubMchid:    core.String(subMchID),
-		Description: core.String(description),
-		OutTradeNo:  core.String(outTradeNo),
-		NotifyUrl:   core.String(callbackUrl),
-		Amount: &jsapi.Amount{
-			Total: core.Int64(amount),
-		},
-		TimeExpire: core.Time(expireTime),
-	}
+	order := vwxpayments.NewPrepayOrder(outTradeNo, description, userOpenID, amount, callbackUrl,
+		vwxpayments.WithAppID(appID),
+		vwxpayments.WithSubMerchant(subMchID, subAppID),
+		vwxpayments.WithAttach(attach),
+		vwxpayments.WithTimeExpire(expireTime),
+		vwxpayments.WithProfitSharing(profitSharing),
+	)
+
+	return c.PrepayOrder(ctx, order)
+}
 
-	// 设置子商户 appid（可选）
-	if subAppID != "" {
-		req.SubAppid = core.String(subAppID)
+// PrepayOrder 服务商模式 JSAPI 支付下单请求, 支持订单详情、场景信息、结算信息等全部下单字段
+// 请求前会校验下单信息, 校验失败时不会发起网络请求
+func (c *PartnerJsApiClient) PrepayOrder(ctx context.Context, order *vwxpayments.PrepayOrder) (*PartnerJsApiPayParams, error) {
+	if err := order.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid prepay order: %w", err)
 	}
 
-	// 设置附加数据（可选）
-	if attach != "" {
-		req.Attach = core.String(attach)
+	if order.SubMchID == "" {
+		return nil, fmt.Errorf("invalid prepay order: sub_mchid is empty")
 	}
 
-	// 设置是否分账（可选）
-	if profitSharing {
-		req.SettleInfo = &jsapi.SettleInfo{
-			ProfitSharing: core.Bool(true),
-		}
+	appID := order.AppID
+	if appID == "" {
+		appID = c.mgr.Config.AppID
 	}
+	outTradeNo := order.OutTradeNo
 
-	// 设置支付者信息
-	if subAppID != "" {
-		// 如果有子商户 appid，使用 sub_openid
-		req.Payer = &jsapi.Payer{
-			SubOpenid: core.String(userOpenID),
-		}
-	} else {
-		// 如果没有子商户 appid，使用服务商 appid 下的 openid
-		req.Payer = &jsapi.Payer{
-			SpOpenid: core.String(userOpenID),
-		}
-	}
+	req := c.buildPrepayRequest(appID, order)
 
 	reqData, _ := json.Marshal(req)
 	vlog.Infof("partner jsapi prepay request | body: %s", reqData)
@@ -158,3 +143,73 @@ func (c *PartnerJsApiClient) Prepay(ctx context.Context,
 		PayNo:     core.String(outTradeNo),
 	}, nil
 }
+
+// buildPrepayRequest 将下单信息转换为服务商模式 JSAPI 下单请求
+// 订单金额、结算信息、优惠详情及场景信息复用 vwxpayments 的转换, 服务商模式的同名类型结构一致, 直接类型转换.
+func (c *PartnerJsApiClient) buildPrepayRequest(appID string, order *vwxpayments.PrepayOrder) jsapi.PrepayRequest {
+	req := jsapi.PrepayRequest{
+		SpAppid:     core.String(appID),
+		SpMchid:     core.String(c.mgr.Config.MerchantID),
+		SubMchid:    core.String(order.SubMchID),
+		Description: core.String(order.Description),
+		OutTradeNo:  core.String(order.OutTradeNo),
+		NotifyUrl:   core.String(order.NotifyURL),
+		Amount:      (*jsapi.Amount)(order.JsapiAmount()),
+		SettleInfo:  (*jsapi.SettleInfo)(order.JsapiSettleInfo()),
+	}
+
+	if !order.TimeExpire.IsZero() {
+		req.TimeExpire = core.Time(order.TimeExpire)
+	}
+
+	// 设置子商户 appid（可选）
+	if order.SubAppID != "" {
+		req.SubAppid = core.String(order.SubAppID)
+	}
+
+	// 设置附加数据（可选）
+	if order.Attach != "" {
+		req.Attach = core.String(order.Attach)
+	}
+
+	if order.GoodsTag != "" {
+		req.GoodsTag = core.String(order.GoodsTag)
+	}
+
+	if order.SupportFapiao {
+		req.SupportFapiao = core.Bool(true)
+	}
+
+	// 设置支付者信息
+	if order.SubAppID != "" {
+		// 如果有子商户 appid，使用 sub_openid
+		req.Payer = &jsapi.Payer{
+			SubOpenid: core.String(order.OpenID),
+		}
+	} else {
+		// 如果没有子商户 appid，使用服务商 appid 下的 openid
+		req.Payer = &jsapi.Payer{
+			SpOpenid: core.String(order.OpenID),
+		}
+	}
+
+	if detail := order.JsapiDetail(); detail != nil {
+		req.Detail = &jsapi.Detail{
+			CostPrice: detail.CostPrice,
+			InvoiceId: detail.InvoiceId,
+		}
+		for _, goods := range detail.GoodsDetail {
+			req.Detail.GoodsDetail = append(req.Detail.GoodsDetail, jsapi.GoodsDetail(goods))
+		}
+	}
+
+	if scene := order.JsapiSceneInfo(); scene != nil {
+		req.SceneInfo = &jsapi.SceneInfo{
+			PayerClientIp: scene.PayerClientIp,
+			DeviceId:      scene.DeviceId,
+			StoreInfo:     (*jsapi.StoreInfo)(scene.StoreInfo),
+		}
+	}
+
+	return req
+}
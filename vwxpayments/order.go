@@ -0,0 +1,303 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayments
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments/jsapi"
+)
+
+const (
+	// MaxDescriptionBytes 商品描述最大字节数
+	MaxDescriptionBytes = 127
+	// MaxAttachBytes 附加数据最大字节数
+	MaxAttachBytes = 128
+)
+
+// GoodsDetail 单品列表信息
+type GoodsDetail struct {
+	MerchantGoodsID  string         `json:"merchant_goods_id"`            // 商户侧商品编码
+	WechatpayGoodsID string         `json:"wechatpay_goods_id,omitempty"` // 微信支付商品编码
+	GoodsName        string         `json:"goods_name,omitempty"`         // 商品名称
+	Quantity         int64          `json:"quantity"`                     // 商品数量
+	UnitPrice        vwxmoney.Money `json:"unit_price"`                   // 商品单价
+}
+
+// StoreInfo 商户门店信息
+type StoreInfo struct {
+	ID       string `json:"id"`                  // 门店编号
+	Name     string `json:"name,omitempty"`      // 门店名称
+	AreaCode string `json:"area_code,omitempty"` // 地区编码
+	Address  string `json:"address,omitempty"`   // 详细地址
+}
+
+// SceneInfo 支付场景描述
+type SceneInfo struct {
+	PayerClientIP string     `json:"payer_client_ip"`      // 用户终端IP
+	DeviceID      string     `json:"device_id,omitempty"`  // 商户端设备号
+	StoreInfo     *StoreInfo `json:"store_info,omitempty"` // 商户门店信息
+}
+
+// PrepayOrder 预支付下单信息, 由直连商户和服务商模式的支付客户端共用
+type PrepayOrder struct {
+	AppID         string         `json:"app_id,omitempty"`         // 应用ID, 为空时使用配置中的默认AppID
+	SubAppID      string         `json:"sub_app_id,omitempty"`     // 子商户应用ID, 仅服务商模式
+	SubMchID      string         `json:"sub_mch_id,omitempty"`     // 子商户号, 仅服务商模式
+	OpenID        string         `json:"open_id"`                  // 用户标识, 服务商模式下设置SubAppID时为子商户应用下的openid
+	OutTradeNo    string         `json:"out_trade_no"`             // 商户订单号
+	Description   string         `json:"description"`              // 商品描述
+	Amount        vwxmoney.Money `json:"amount"`                   // 订单总金额及货币类型
+	Attach        string         `json:"attach,omitempty"`         // 附加数据
+	NotifyURL     string         `json:"notify_url"`               // 回调通知地址
+	TimeExpire    time.Time      `json:"time_expire"`              // 订单失效时间
+	GoodsTag      string         `json:"goods_tag,omitempty"`      // 订单优惠标记
+	SupportFapiao bool           `json:"support_fapiao,omitempty"` // 是否展示电子发票入口
+	CostPrice     vwxmoney.Money `json:"cost_price"`               // 订单原价
+	InvoiceID     string         `json:"invoice_id,omitempty"`     // 商品小票ID
+	GoodsDetail   []*GoodsDetail `json:"goods_detail,omitempty"`   // 单品列表
+	SceneInfo     *SceneInfo     `json:"scene_info,omitempty"`     // 场景信息
+	ProfitSharing bool           `json:"profit_sharing,omitempty"` // 是否指定分账
+}
+
+// PrepayOption 预支付下单可选项
+type PrepayOption func(*PrepayOrder)
+
+// NewPrepayOrder 创建预支付下单信息
+// outTradeNo: 商户订单号
+// description: 商品描述
+// openID: 用户标识
+// amount: 订单金额
+// notifyURL: 回调通知地址
+func NewPrepayOrder(outTradeNo, description, openID string, amount vwxmoney.Money, notifyURL string, opts ...PrepayOption) *PrepayOrder {
+	order := &PrepayOrder{
+		OutTradeNo:  outTradeNo,
+		Description: description,
+		OpenID:      openID,
+		Amount:      amount,
+		NotifyURL:   notifyURL,
+	}
+
+	for _, opt := range opts {
+		opt(order)
+	}
+
+	return order
+}
+
+// WithAppID 设置应用ID
+func WithAppID(appID string) PrepayOption {
+	return func(o *PrepayOrder) { o.AppID = appID }
+}
+
+// WithSubMerchant 设置子商户号及子商户应用ID(可为空), 仅服务商模式
+func WithSubMerchant(subMchID, subAppID string) PrepayOption {
+	return func(o *PrepayOrder) {
+		o.SubMchID = subMchID
+		o.SubAppID = subAppID
+	}
+}
+
+// WithAttach 设置附加数据
+func WithAttach(attach string) PrepayOption {
+	return func(o *PrepayOrder) { o.Attach = attach }
+}
+
+// WithTimeExpire 设置订单失效时间
+func WithTimeExpire(t time.Time) PrepayOption {
+	return func(o *PrepayOrder) { o.TimeExpire = t }
+}
+
+// WithGoodsTag 设置订单优惠标记
+func WithGoodsTag(goodsTag string) PrepayOption {
+	return func(o *PrepayOrder) { o.GoodsTag = goodsTag }
+}
+
+// WithSupportFapiao 设置是否展示电子发票入口
+func WithSupportFapiao(support bool) PrepayOption {
+	return func(o *PrepayOrder) { o.SupportFapiao = support }
+}
+
+// WithCostPrice 设置订单原价
+func WithCostPrice(costPrice vwxmoney.Money) PrepayOption {
+	return func(o *PrepayOrder) { o.CostPrice = costPrice }
+}
+
+// WithInvoiceID 设置商品小票ID
+func WithInvoiceID(invoiceID string) PrepayOption {
+	return func(o *PrepayOrder) { o.InvoiceID = invoiceID }
+}
+
+// WithGoodsDetail 追加单品信息
+func WithGoodsDetail(details ...*GoodsDetail) PrepayOption {
+	return func(o *PrepayOrder) { o.GoodsDetail = append(o.GoodsDetail, details...) }
+}
+
+// WithSceneInfo 设置场景信息
+func WithSceneInfo(sceneInfo *SceneInfo) PrepayOption {
+	return func(o *PrepayOrder) { o.SceneInfo = sceneInfo }
+}
+
+// WithProfitSharing 设置是否指定分账
+func WithProfitSharing(profitSharing bool) PrepayOption {
+	return func(o *PrepayOrder) { o.ProfitSharing = profitSharing }
+}
+
+// HasDetail 是否包含优惠功能相关的订单详情
+func (o *PrepayOrder) HasDetail() bool {
+	return o.CostPrice.IsPositive() || o.InvoiceID != "" || len(o.GoodsDetail) > 0
+}
+
+// Validate 在发起请求前校验下单信息
+func (o *PrepayOrder) Validate() error {
+	if o.OutTradeNo == "" {
+		return fmt.Errorf("out_trade_no is empty")
+	}
+
+	if o.OpenID == "" {
+		return fmt.Errorf("openid is empty")
+	}
+
+	if o.NotifyURL == "" {
+		return fmt.Errorf("notify_url is empty")
+	}
+
+	if o.Description == "" {
+		return fmt.Errorf("description is empty")
+	}
+
+	if len(o.Description) > MaxDescriptionBytes {
+		return fmt.Errorf("description exceeds %d bytes: %d", MaxDescriptionBytes, len(o.Description))
+	}
+
+	if len(o.Attach) > MaxAttachBytes {
+		return fmt.Errorf("attach exceeds %d bytes: %d", MaxAttachBytes, len(o.Attach))
+	}
+
+	if !o.Amount.IsPositive() {
+		return fmt.Errorf("amount must be greater than 0: %s", o.Amount)
+	}
+
+	if !o.TimeExpire.IsZero() && !o.TimeExpire.After(time.Now()) {
+		return fmt.Errorf("time_expire must be in the future: %s", o.TimeExpire.Format(time.RFC3339))
+	}
+
+	for _, goods := range o.GoodsDetail {
+		if goods.MerchantGoodsID == "" {
+			return fmt.Errorf("merchant_goods_id of goods detail is empty")
+		}
+		if goods.Quantity <= 0 {
+			return fmt.Errorf("quantity of goods %s must be greater than 0", goods.MerchantGoodsID)
+		}
+		if !goods.UnitPrice.SameCurrency(o.Amount) {
+			return fmt.Errorf("currency of goods %s mismatch: %s", goods.MerchantGoodsID, goods.UnitPrice.Currency())
+		}
+	}
+
+	if o.SceneInfo != nil && o.SceneInfo.PayerClientIP == "" {
+		return fmt.Errorf("payer_client_ip of scene info is empty")
+	}
+
+	return nil
+}
+
+// JsapiAmount 转换为 JSAPI 下单请求的订单金额
+// 服务商模式的同名类型结构一致, 可直接类型转换, 保证两种模式的转换规则一致.
+func (o *PrepayOrder) JsapiAmount() *jsapi.Amount {
+	return &jsapi.Amount{
+		Total:    core.Int64(o.Amount.Fen()),
+		Currency: core.String(o.Amount.Currency()),
+	}
+}
+
+// JsapiSettleInfo 转换为 JSAPI 下单请求的结算信息, 未指定分账时返回 nil
+func (o *PrepayOrder) JsapiSettleInfo() *jsapi.SettleInfo {
+	if !o.ProfitSharing {
+		return nil
+	}
+
+	return &jsapi.SettleInfo{
+		ProfitSharing: core.Bool(true),
+	}
+}
+
+// JsapiDetail 转换为 JSAPI 下单请求的优惠功能详情, 无详情时返回 nil
+func (o *PrepayOrder) JsapiDetail() *jsapi.Detail {
+	if !o.HasDetail() {
+		return nil
+	}
+
+	detail := &jsapi.Detail{}
+	if o.CostPrice.IsPositive() {
+		detail.CostPrice = core.Int64(o.CostPrice.Fen())
+	}
+	if o.InvoiceID != "" {
+		detail.InvoiceId = core.String(o.InvoiceID)
+	}
+
+	for _, goods := range o.GoodsDetail {
+		g := jsapi.GoodsDetail{
+			MerchantGoodsId: core.String(goods.MerchantGoodsID),
+			Quantity:        core.Int64(goods.Quantity),
+			UnitPrice:       core.Int64(goods.UnitPrice.Fen()),
+		}
+		if goods.WechatpayGoodsID != "" {
+			g.WechatpayGoodsId = core.String(goods.WechatpayGoodsID)
+		}
+		if goods.GoodsName != "" {
+			g.GoodsName = core.String(goods.GoodsName)
+		}
+		detail.GoodsDetail = append(detail.GoodsDetail, g)
+	}
+
+	return detail
+}
+
+// JsapiSceneInfo 转换为 JSAPI 下单请求的场景信息, 未设置时返回 nil
+func (o *PrepayOrder) JsapiSceneInfo() *jsapi.SceneInfo {
+	if o.SceneInfo == nil {
+		return nil
+	}
+
+	scene := &jsapi.SceneInfo{
+		PayerClientIp: core.String(o.SceneInfo.PayerClientIP),
+	}
+	if o.SceneInfo.DeviceID != "" {
+		scene.DeviceId = core.String(o.SceneInfo.DeviceID)
+	}
+
+	if store := o.SceneInfo.StoreInfo; store != nil {
+		scene.StoreInfo = &jsapi.StoreInfo{
+			Id: core.String(store.ID),
+		}
+		if store.Name != "" {
+			scene.StoreInfo.Name = core.String(store.Name)
+		}
+		if store.AreaCode != "" {
+			scene.StoreInfo.AreaCode = core.String(store.AreaCode)
+		}
+		if store.Address != "" {
+			scene.StoreInfo.Address = core.String(store.Address)
+		}
+	}
+
+	return scene
+}
@@ -0,0 +1,82 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayments
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+func TestPrepayOrderValidate(t *testing.T) {
+	newOrder := func(opts ...PrepayOption) *PrepayOrder {
+		return NewPrepayOrder("T202401010001", "商品描述", "openid", vwxmoney.Fen(100), "https://example.com/notify", opts...)
+	}
+
+	cases := []struct {
+		name  string
+		order *PrepayOrder
+		ok    bool
+	}{
+		{"valid", newOrder(WithTimeExpire(time.Now().Add(time.Hour))), true},
+		{"description too long", newOrder(func(o *PrepayOrder) { o.Description = strings.Repeat("a", 128) }), false},
+		{"attach too long", newOrder(WithAttach(strings.Repeat("a", 129))), false},
+		{"zero amount", newOrder(func(o *PrepayOrder) { o.Amount = vwxmoney.Fen(0) }), false},
+		{"expired", newOrder(WithTimeExpire(time.Now().Add(-time.Minute))), false},
+		{"goods without quantity", newOrder(WithGoodsDetail(&GoodsDetail{MerchantGoodsID: "g1"})), false},
+		{"scene without ip", newOrder(WithSceneInfo(&SceneInfo{DeviceID: "d1"})), false},
+	}
+
+	for _, c := range cases {
+		err := c.order.Validate()
+		if c.ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("%s: expected error", c.name)
+		}
+	}
+}
+
+func TestPrepayOrderJsapi(t *testing.T) {
+	order := NewPrepayOrder("T001", "商品", "o001", vwxmoney.Fen(100), "https://example.com/notify",
+		WithCostPrice(vwxmoney.Fen(120)),
+		WithGoodsDetail(&GoodsDetail{MerchantGoodsID: "G001", Quantity: 2, UnitPrice: vwxmoney.Fen(50)}),
+		WithSceneInfo(&SceneInfo{PayerClientIP: "127.0.0.1", StoreInfo: &StoreInfo{ID: "S001"}}),
+	)
+
+	if amount := order.JsapiAmount(); *amount.Total != 100 || *amount.Currency != "CNY" {
+		t.Errorf("unexpected amount: %+v", amount)
+	}
+
+	if order.JsapiSettleInfo() != nil {
+		t.Error("settle info should be nil without profit sharing")
+	}
+
+	detail := order.JsapiDetail()
+	if *detail.CostPrice != 120 || detail.InvoiceId != nil || len(detail.GoodsDetail) != 1 || *detail.GoodsDetail[0].UnitPrice != 50 {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+
+	scene := order.JsapiSceneInfo()
+	if *scene.PayerClientIp != "127.0.0.1" || scene.DeviceId != nil || *scene.StoreInfo.Id != "S001" || scene.StoreInfo.Name != nil {
+		t.Errorf("unexpected scene info: %+v", scene)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayments
+
+import (
+	"github.com/wechatpay-apiv3/wechatpay-go/services/partnerpayments"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+)
+
+// TradeState 交易状态
+type TradeState string
+
+const (
+	TradeStateSuccess    TradeState = "SUCCESS"    // 支付成功
+	TradeStateRefund     TradeState = "REFUND"     // 转入退款
+	TradeStateNotPay     TradeState = "NOTPAY"     // 未支付
+	TradeStateClosed     TradeState = "CLOSED"     // 已关闭
+	TradeStateRevoked    TradeState = "REVOKED"    // 已撤销（仅付款码支付会返回）
+	TradeStateUserPaying TradeState = "USERPAYING" // 用户支付中（仅付款码支付会返回）
+	TradeStatePayError   TradeState = "PAYERROR"   // 支付失败（仅付款码支付会返回）
+)
+
+// tradeStateTransitions 交易状态允许的流转, 同一状态之间的流转总是允许的
+// 终态除 SUCCESS 可转入 REFUND 外不再流转, 与 IsTerminal 保持一致
+var tradeStateTransitions = map[TradeState][]TradeState{
+	TradeStateNotPay:     {TradeStateUserPaying, TradeStateSuccess, TradeStateClosed, TradeStateRevoked, TradeStatePayError},
+	TradeStateUserPaying: {TradeStateSuccess, TradeStateClosed, TradeStateRevoked, TradeStatePayError},
+	TradeStateSuccess:    {TradeStateRefund},
+}
+
+// TradeStateText 交易状态中文描述
+func TradeStateText(state string) string {
+	return TradeState(state).Text()
+}
+
+// Text 交易状态中文描述
+func (s TradeState) Text() string {
+	switch s {
+	case TradeStateSuccess:
+		return "支付成功"
+	case TradeStateRefund:
+		return "转入退款"
+	case TradeStateNotPay:
+		return "未支付"
+	case TradeStateClosed:
+		return "已关闭"
+	case TradeStateRevoked:
+		return "已撤销"
+	case TradeStateUserPaying:
+		return "用户支付中"
+	case TradeStatePayError:
+		return "支付失败"
+	default:
+		return "未知状态"
+	}
+}
+
+// EnglishText 交易状态英文描述
+func (s TradeState) EnglishText() string {
+	switch s {
+	case TradeStateSuccess:
+		return "Payment successful"
+	case TradeStateRefund:
+		return "Transferred to refund"
+	case TradeStateNotPay:
+		return "Not paid"
+	case TradeStateClosed:
+		return "Closed"
+	case TradeStateRevoked:
+		return "Revoked"
+	case TradeStateUserPaying:
+		return "User paying"
+	case TradeStatePayError:
+		return "Payment failed"
+	default:
+		return "Unknown state"
+	}
+}
+
+// IsKnown 是否为已知的交易状态
+func (s TradeState) IsKnown() bool {
+	switch s {
+	case TradeStateSuccess, TradeStateRefund, TradeStateNotPay, TradeStateClosed,
+		TradeStateRevoked, TradeStateUserPaying, TradeStatePayError:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTerminal 是否为终态, 终态表示支付结果已确定, 无需继续查询
+// 注意支付成功的订单仍可能因退款转为 REFUND 状态
+func (s TradeState) IsTerminal() bool {
+	switch s {
+	case TradeStateSuccess, TradeStateRefund, TradeStateClosed, TradeStateRevoked, TradeStatePayError:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPaid 用户是否已完成支付
+func (s TradeState) IsPaid() bool {
+	return s == TradeStateSuccess || s == TradeStateRefund
+}
+
+// IsWaitingPay 是否等待用户支付
+func (s TradeState) IsWaitingPay() bool {
+	return s == TradeStateNotPay || s == TradeStateUserPaying
+}
+
+// CanTransitionTo 是否允许从当前状态流转到目标状态
+func (s TradeState) CanTransitionTo(next TradeState) bool {
+	if s == next {
+		return true
+	}
+
+	for _, allowed := range tradeStateTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TransactionTradeState 获取直连商户订单的交易状态
+func TransactionTradeState(tx *payments.Transaction) TradeState {
+	if tx == nil || tx.TradeState == nil {
+		return ""
+	}
+	return TradeState(*tx.TradeState)
+}
+
+// PartnerTransactionTradeState 获取服务商模式订单的交易状态
+func PartnerTransactionTradeState(tx *partnerpayments.Transaction) TradeState {
+	if tx == nil || tx.TradeState == nil {
+		return ""
+	}
+	return TradeState(*tx.TradeState)
+}
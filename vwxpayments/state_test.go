@@ -0,0 +1,52 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayments
+
+import (
+	"testing"
+
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+)
+
+func TestTradeState(t *testing.T) {
+	state := TransactionTradeState(&payments.Transaction{TradeState: core.String("NOTPAY")})
+	if state != TradeStateNotPay || state.IsTerminal() || !state.IsWaitingPay() {
+		t.Errorf("unexpected state: %s", state)
+	}
+
+	if !state.CanTransitionTo(TradeStateSuccess) || !state.CanTransitionTo(TradeStateNotPay) {
+		t.Errorf("NOTPAY should transit to SUCCESS")
+	}
+
+	if TradeStateClosed.CanTransitionTo(TradeStateSuccess) || TradeStateRefund.CanTransitionTo(TradeStateSuccess) {
+		t.Errorf("terminal state should not transit to SUCCESS")
+	}
+
+	if TradeStatePayError.CanTransitionTo(TradeStateClosed) || TradeStatePayError.CanTransitionTo(TradeStateRevoked) {
+		t.Errorf("terminal PAYERROR should not transit")
+	}
+
+	if !TradeStateSuccess.CanTransitionTo(TradeStateRefund) || !TradeStateRefund.IsPaid() {
+		t.Errorf("SUCCESS should transit to REFUND")
+	}
+
+	if TradeStateText("USERPAYING") != "用户支付中" || TradeState("UNKNOWN").IsKnown() {
+		t.Errorf("unexpected text")
+	}
+}
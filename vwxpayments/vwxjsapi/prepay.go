@@ -27,35 +27,44 @@ import (
 
 	"github.com/vogo/vogo/vencoding/vjson"
 	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpayments"
 	"github.com/wechatpay-apiv3/wechatpay-go/core"
 	"github.com/wechatpay-apiv3/wechatpay-go/services/payments/jsapi"
 )
 
+// Prepay JSAPI 支付下单请求
+// appID: 商户 appid, 为空时使用配置中的默认AppID
+// openId: 用户唯一标识
+// amount: 订单金额
+// outTradeNo: 商户订单号
+// description: 商品描述
+// attach: 附加数据
+// callbackUrl: 回调通知地址
+// expireTime: 订单失效时间
 func (s *JsApiClient) Prepay(ctx context.Context,
-	appID, openId string, amount int64,
+	appID, openId string, amount vwxmoney.Money,
 	outTradeNo, description, attach, callbackUrl string,
 	expireTime time.Time,
 ) (*JsApiPayParams, error) {
-	if appID == "" {
-		appID = s.mgr.Config.AppID
-	}
+	order := vwxpayments.NewPrepayOrder(outTradeNo, description, openId, amount, callbackUrl,
+		vwxpayments.WithAppID(appID),
+		vwxpayments.WithAttach(attach),
+		vwxpayments.WithTimeExpire(expireTime),
+	)
 
-	prepayRequest := jsapi.PrepayRequest{
-		Appid:       core.String(appID),
-		Mchid:       core.String(s.mgr.Config.MerchantID),
-		Description: core.String(description),
-		OutTradeNo:  core.String(outTradeNo),
-		Attach:      core.String(attach),
-		NotifyUrl:   core.String(callbackUrl),
-		Amount: &jsapi.Amount{
-			Total: core.Int64(amount),
-		},
-		Payer: &jsapi.Payer{
-			Openid: core.String(openId),
-		},
-		TimeExpire: core.Time(expireTime),
+	return s.PrepayOrder(ctx, order)
+}
+
+// PrepayOrder JSAPI 支付下单请求, 支持订单详情、场景信息、结算信息等全部下单字段
+// 请求前会校验下单信息, 校验失败时不会发起网络请求
+func (s *JsApiClient) PrepayOrder(ctx context.Context, order *vwxpayments.PrepayOrder) (*JsApiPayParams, error) {
+	if err := order.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid prepay order: %w", err)
 	}
 
+	prepayRequest := s.buildPrepayRequest(order)
+
 	reqData, _ := json.Marshal(prepayRequest)
 
 	vlog.Infof("jsapi prepay request | body: %s", reqData)
@@ -87,6 +96,47 @@ func (s *JsApiClient) Prepay(ctx context.Context,
 		Package:   resp.Package,
 		SignType:  resp.SignType,
 		PaySign:   resp.PaySign,
-		PayNo:     &outTradeNo,
+		PayNo:     core.String(order.OutTradeNo),
 	}, nil
 }
+
+// buildPrepayRequest 将下单信息转换为 JSAPI 下单请求
+func (s *JsApiClient) buildPrepayRequest(order *vwxpayments.PrepayOrder) jsapi.PrepayRequest {
+	appID := order.AppID
+	if appID == "" {
+		appID = s.mgr.Config.AppID
+	}
+
+	req := jsapi.PrepayRequest{
+		Appid:       core.String(appID),
+		Mchid:       core.String(s.mgr.Config.MerchantID),
+		Description: core.String(order.Description),
+		OutTradeNo:  core.String(order.OutTradeNo),
+		NotifyUrl:   core.String(order.NotifyURL),
+		Amount:      order.JsapiAmount(),
+		Payer: &jsapi.Payer{
+			Openid: core.String(order.OpenID),
+		},
+		SettleInfo: order.JsapiSettleInfo(),
+		Detail:     order.JsapiDetail(),
+		SceneInfo:  order.JsapiSceneInfo(),
+	}
+
+	if order.Attach != "" {
+		req.Attach = core.String(order.Attach)
+	}
+
+	if !order.TimeExpire.IsZero() {
+		req.TimeExpire = core.Time(order.TimeExpire)
+	}
+
+	if order.GoodsTag != "" {
+		req.GoodsTag = core.String(order.GoodsTag)
+	}
+
+	if order.SupportFapiao {
+		req.SupportFapiao = core.Bool(true)
+	}
+
+	return req
+}
@@ -0,0 +1,195 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxjsapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+)
+
+const (
+	defaultWatchTickInterval   = 5 * time.Second
+	defaultWatchInitialBackoff = 10 * time.Second
+	defaultWatchMaxBackoff     = 5 * time.Minute
+)
+
+// PendingOrder 待确认支付结果的订单
+type PendingOrder struct {
+	OutTradeNo string    `json:"out_trade_no"` // 商户订单号
+	ExpireTime time.Time `json:"expire_time"`  // 订单失效时间, 超过后自动关单
+
+	vwxutils.PollSchedule
+}
+
+func pendingOrderKey(order *PendingOrder) string {
+	return order.OutTradeNo
+}
+
+// PendingOrderStore 待确认订单存储, 实现持久化存储可在服务重启后继续跟踪订单, Remove 的 key 为商户订单号
+type PendingOrderStore = vwxutils.PollStore[PendingOrder]
+
+// MemoryPendingOrderStore 基于内存的待确认订单存储
+type MemoryPendingOrderStore = vwxutils.MemoryPollStore[PendingOrder, *PendingOrder]
+
+// NewMemoryPendingOrderStore 创建基于内存的待确认订单存储
+func NewMemoryPendingOrderStore() *MemoryPendingOrderStore {
+	return vwxutils.NewMemoryPollStore(pendingOrderKey)
+}
+
+// OrderWatchHandler 订单进入终态(支付成功、已关闭等)时的回调
+// 超时关单后 tx 的交易状态为 CLOSED
+type OrderWatchHandler func(ctx context.Context, order *PendingOrder, tx *payments.Transaction)
+
+// OrderGiveUpHandler 订单超过最大查询次数仍未确认结果, 放弃跟踪时的回调
+type OrderGiveUpHandler func(ctx context.Context, order *PendingOrder)
+
+// orderClient 订单查询及关单接口, 由 JsApiClient 实现
+type orderClient interface {
+	QueryOrderByOutTradeNo(ctx context.Context, outTradeNo string) (*payments.Transaction, error)
+	CloseOrder(ctx context.Context, outTradeNo string) error
+}
+
+// OrderWatcher 未支付订单跟踪器
+// 对未收到支付通知的订单按退避间隔轮询查询订单状态, 进入终态时回调, 超过失效时间仍未支付时自动关单
+type OrderWatcher struct {
+	client  orderClient
+	handler OrderWatchHandler
+	poller  *vwxutils.Poller[PendingOrder, *PendingOrder]
+}
+
+// OrderWatcherOption 订单跟踪器可选项
+type OrderWatcherOption func(*OrderWatcher)
+
+// WithPendingOrderStore 设置待确认订单存储, 默认使用内存存储
+func WithPendingOrderStore(store PendingOrderStore) OrderWatcherOption {
+	return func(w *OrderWatcher) { w.poller.Store = store }
+}
+
+// WithWatchBackoff 设置轮询查询的退避间隔
+func WithWatchBackoff(initial, max time.Duration) OrderWatcherOption {
+	return func(w *OrderWatcher) { w.poller.Backoff = vwxutils.NewBackoff(initial, max) }
+}
+
+// WithWatchTickInterval 设置扫描待确认订单的间隔
+func WithWatchTickInterval(interval time.Duration) OrderWatcherOption {
+	return func(w *OrderWatcher) { w.poller.TickInterval = interval }
+}
+
+// WithWatchMaxAttempts 设置最大查询次数, 超过后回调 handler 并放弃跟踪, 默认不限制
+func WithWatchMaxAttempts(max int, handler OrderGiveUpHandler) OrderWatcherOption {
+	return func(w *OrderWatcher) {
+		w.poller.MaxAttempts = max
+		w.poller.GiveUp = handler
+	}
+}
+
+// NewOrderWatcher 创建未支付订单跟踪器, 后台任务运行在 Manager 的 Runner 上, Manager 停止时随之停止
+func NewOrderWatcher(client *JsApiClient, handler OrderWatchHandler, opts ...OrderWatcherOption) *OrderWatcher {
+	return newOrderWatcher(client, client.mgr.Runner().NewChild(), handler, opts...)
+}
+
+func newOrderWatcher(client orderClient, runner *vrun.Runner, handler OrderWatchHandler, opts ...OrderWatcherOption) *OrderWatcher {
+	w := &OrderWatcher{
+		client:  client,
+		handler: handler,
+	}
+
+	w.poller = vwxutils.NewPoller("pending order", runner, pendingOrderKey, w.check)
+	w.poller.Backoff = vwxutils.NewBackoff(defaultWatchInitialBackoff, defaultWatchMaxBackoff)
+	w.poller.TickInterval = defaultWatchTickInterval
+	// 下次查询时间不晚于失效时间, 保证及时关单
+	w.poller.Deadline = func(order *PendingOrder) time.Time { return order.ExpireTime }
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Start 启动后台轮询, 存储中已有的订单会继续被跟踪
+func (w *OrderWatcher) Start() {
+	w.poller.Start()
+}
+
+// Stop 停止后台轮询
+func (w *OrderWatcher) Stop() {
+	w.poller.Stop()
+}
+
+// Watch 跟踪订单支付结果
+// outTradeNo: 商户订单号
+// expireTime: 订单失效时间, 超过后仍未支付则自动关单
+func (w *OrderWatcher) Watch(ctx context.Context, outTradeNo string, expireTime time.Time) error {
+	vlog.Infof("watch order | out_trade_no: %s | expire_time: %s", outTradeNo, expireTime.Format(time.RFC3339))
+
+	return w.poller.Schedule(ctx, &PendingOrder{
+		OutTradeNo: outTradeNo,
+		ExpireTime: expireTime,
+	})
+}
+
+// Unwatch 取消跟踪订单, 如已收到支付通知时调用
+func (w *OrderWatcher) Unwatch(ctx context.Context, outTradeNo string) error {
+	return w.poller.Remove(ctx, outTradeNo)
+}
+
+// check 查询订单状态, 终态时回调, 超时未支付时关单, 返回订单是否已完成
+func (w *OrderWatcher) check(ctx context.Context, order *PendingOrder) bool {
+	tx, err := w.client.QueryOrderByOutTradeNo(ctx, order.OutTradeNo)
+	if err != nil {
+		vlog.Errorf("watch order query error | out_trade_no: %s | err: %v", order.OutTradeNo, err)
+		return false
+	}
+
+	state := vwxpayments.TransactionTradeState(tx)
+	if state.IsTerminal() {
+		w.finish(ctx, order, tx)
+		return true
+	}
+
+	if !order.ExpireTime.IsZero() && !time.Now().Before(order.ExpireTime) {
+		// 关单失败(如用户恰好完成支付)时等待下次查询确认最终状态
+		if err = w.client.CloseOrder(ctx, order.OutTradeNo); err != nil {
+			vlog.Errorf("watch order close error | out_trade_no: %s | err: %v", order.OutTradeNo, err)
+			return false
+		}
+
+		tx.TradeState = core.String(string(vwxpayments.TradeStateClosed))
+		w.finish(ctx, order, tx)
+		return true
+	}
+
+	return false
+}
+
+func (w *OrderWatcher) finish(ctx context.Context, order *PendingOrder, tx *payments.Transaction) {
+	vlog.Infof("watch order finished | out_trade_no: %s | trade_state: %s",
+		order.OutTradeNo, vwxpayments.TransactionTradeState(tx))
+
+	if w.handler != nil {
+		w.handler(ctx, order, tx)
+	}
+}
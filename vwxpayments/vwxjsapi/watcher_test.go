@@ -0,0 +1,112 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxjsapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+)
+
+type fakeOrderClient struct {
+	states map[string]string
+	closed []string
+}
+
+func (c *fakeOrderClient) QueryOrderByOutTradeNo(_ context.Context, outTradeNo string) (*payments.Transaction, error) {
+	return &payments.Transaction{
+		OutTradeNo: core.String(outTradeNo),
+		TradeState: core.String(c.states[outTradeNo]),
+	}, nil
+}
+
+func (c *fakeOrderClient) CloseOrder(_ context.Context, outTradeNo string) error {
+	c.closed = append(c.closed, outTradeNo)
+	return nil
+}
+
+func TestOrderWatcher(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeOrderClient{states: map[string]string{
+		"paid":    "SUCCESS",
+		"expired": "NOTPAY",
+		"waiting": "NOTPAY",
+	}}
+
+	results := map[string]vwxpayments.TradeState{}
+	handler := func(_ context.Context, order *PendingOrder, tx *payments.Transaction) {
+		results[order.OutTradeNo] = vwxpayments.TransactionTradeState(tx)
+	}
+
+	w := newOrderWatcher(client, vrun.New(), handler, WithWatchBackoff(0, time.Minute))
+
+	_ = w.Watch(ctx, "paid", time.Now().Add(time.Hour))
+	_ = w.Watch(ctx, "expired", time.Now().Add(-time.Second))
+	_ = w.Watch(ctx, "waiting", time.Now().Add(time.Hour))
+
+	w.poller.Poll()
+
+	if results["paid"] != vwxpayments.TradeStateSuccess {
+		t.Errorf("paid order state: %s", results["paid"])
+	}
+
+	if results["expired"] != vwxpayments.TradeStateClosed || len(client.closed) != 1 || client.closed[0] != "expired" {
+		t.Errorf("expired order should be closed: %s, %v", results["expired"], client.closed)
+	}
+
+	if _, ok := results["waiting"]; ok {
+		t.Errorf("waiting order should not finish")
+	}
+
+	orders, _ := w.poller.Store.List(ctx)
+	if len(orders) != 1 || orders[0].OutTradeNo != "waiting" || orders[0].Attempts != 1 {
+		t.Errorf("unexpected pending orders: %v", orders)
+	}
+}
+
+func TestOrderWatcherMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeOrderClient{states: map[string]string{"waiting": "NOTPAY"}}
+
+	var gaveUp []string
+	w := newOrderWatcher(client, vrun.New(), nil, WithWatchBackoff(0, time.Minute),
+		WithWatchMaxAttempts(2, func(_ context.Context, order *PendingOrder) {
+			gaveUp = append(gaveUp, order.OutTradeNo)
+		}))
+
+	_ = w.Watch(ctx, "waiting", time.Now().Add(time.Hour))
+
+	w.poller.Poll()
+	if len(gaveUp) != 0 {
+		t.Fatalf("should not give up after first attempt")
+	}
+
+	w.poller.Poll()
+	if len(gaveUp) != 1 || gaveUp[0] != "waiting" {
+		t.Fatalf("should give up after max attempts: %v", gaveUp)
+	}
+
+	if orders, _ := w.poller.Store.List(ctx); len(orders) != 0 {
+		t.Errorf("order should be removed: %v", orders)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vogo/vwechatpay"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/vogo/vwechatpay/vwxpayments/vwxjsapi"
+	"github.com/vogo/vwechatpay/vwxrefund"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+var _ PaymentService = (*DirectPaymentService)(nil)
+
+// DirectPaymentService 直连商户支付服务
+type DirectPaymentService struct {
+	jsapi  *vwxjsapi.JsApiClient
+	refund *vwxrefund.RefundClient
+}
+
+// NewDirectPaymentService 创建直连商户支付服务
+func NewDirectPaymentService(mgr *vwechatpay.Manager) *DirectPaymentService {
+	return &DirectPaymentService{
+		jsapi:  vwxjsapi.NewJsApiClient(mgr),
+		refund: vwxrefund.NewRefundClient(mgr),
+	}
+}
+
+func (s *DirectPaymentService) Mode() Mode {
+	return ModeDirect
+}
+
+func (s *DirectPaymentService) Prepay(ctx context.Context, order *vwxpayments.PrepayOrder) (*PayParams, error) {
+	params, err := s.jsapi.PrepayOrder(ctx, order)
+	if err != nil {
+		if errors.Is(err, vwxjsapi.ErrOrderPaid) {
+			return nil, ErrOrderPaid
+		}
+		return nil, err
+	}
+
+	return &PayParams{
+		AppID:     stringValue(params.AppID),
+		TimeStamp: stringValue(params.TimeStamp),
+		NonceStr:  stringValue(params.NonceStr),
+		Package:   stringValue(params.Package),
+		SignType:  stringValue(params.SignType),
+		PaySign:   stringValue(params.PaySign),
+		PayNo:     stringValue(params.PayNo),
+	}, nil
+}
+
+func (s *DirectPaymentService) QueryOrder(ctx context.Context, outTradeNo string) (*Order, error) {
+	tx, err := s.jsapi.QueryOrderByOutTradeNo(ctx, outTradeNo)
+	if err != nil {
+		return nil, err
+	}
+	return OrderFromTransaction(tx), nil
+}
+
+func (s *DirectPaymentService) QueryOrderByTransactionID(ctx context.Context, transactionID string) (*Order, error) {
+	tx, err := s.jsapi.QueryOrderById(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return OrderFromTransaction(tx), nil
+}
+
+func (s *DirectPaymentService) CloseOrder(ctx context.Context, outTradeNo string) error {
+	return s.jsapi.CloseOrder(ctx, outTradeNo)
+}
+
+func (s *DirectPaymentService) Refund(ctx context.Context, req *RefundRequest) (*refunddomestic.Refund, error) {
+	createReq, err := buildRefundRequest(req, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.refund.CreateRefund(ctx, createReq)
+}
+
+func (s *DirectPaymentService) ParseNotify(headerFetcher func(string) string, body []byte) (*notify.Request, *Order, error) {
+	req, _, err := s.jsapi.JsApiNotifyParse(headerFetcher, body)
+	if err != nil {
+		return req, nil, err
+	}
+
+	var tx payments.Transaction
+	if err := json.Unmarshal([]byte(req.Resource.Plaintext), &tx); err != nil {
+		return req, nil, fmt.Errorf("unmarshal transaction error: %w", err)
+	}
+
+	return req, OrderFromTransaction(&tx), nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayservice
+
+import (
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/partnerpayments"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+)
+
+// OrderFromTransaction 将直连商户订单转换为统一的订单信息
+func OrderFromTransaction(tx *payments.Transaction) *Order {
+	order := &Order{
+		Mode:           ModeDirect,
+		AppID:          stringValue(tx.Appid),
+		MchID:          stringValue(tx.Mchid),
+		OutTradeNo:     stringValue(tx.OutTradeNo),
+		TransactionID:  stringValue(tx.TransactionId),
+		TradeType:      stringValue(tx.TradeType),
+		TradeState:     vwxpayments.TransactionTradeState(tx),
+		TradeStateDesc: stringValue(tx.TradeStateDesc),
+		BankType:       stringValue(tx.BankType),
+		Attach:         stringValue(tx.Attach),
+		SuccessTime:    stringValue(tx.SuccessTime),
+	}
+
+	if tx.Payer != nil {
+		order.OpenID = stringValue(tx.Payer.Openid)
+	}
+
+	if tx.Amount != nil {
+		order.Amount = vwxmoney.New(int64Value(tx.Amount.Total), stringValue(tx.Amount.Currency))
+		order.PayerAmount = vwxmoney.New(int64Value(tx.Amount.PayerTotal), stringValue(tx.Amount.PayerCurrency))
+	}
+
+	return order
+}
+
+// OrderFromPartnerTransaction 将服务商模式订单转换为统一的订单信息
+func OrderFromPartnerTransaction(tx *partnerpayments.Transaction) *Order {
+	order := &Order{
+		Mode:           ModePartner,
+		AppID:          stringValue(tx.SpAppid),
+		MchID:          stringValue(tx.SpMchid),
+		SubAppID:       stringValue(tx.SubAppid),
+		SubMchID:       stringValue(tx.SubMchid),
+		OutTradeNo:     stringValue(tx.OutTradeNo),
+		TransactionID:  stringValue(tx.TransactionId),
+		TradeType:      stringValue(tx.TradeType),
+		TradeState:     vwxpayments.PartnerTransactionTradeState(tx),
+		TradeStateDesc: stringValue(tx.TradeStateDesc),
+		BankType:       stringValue(tx.BankType),
+		Attach:         stringValue(tx.Attach),
+		SuccessTime:    stringValue(tx.SuccessTime),
+	}
+
+	if tx.Payer != nil {
+		order.OpenID = stringValue(tx.Payer.SubOpenid)
+		if order.OpenID == "" {
+			order.OpenID = stringValue(tx.Payer.SpOpenid)
+		}
+	}
+
+	if tx.Amount != nil {
+		order.Amount = vwxmoney.New(int64Value(tx.Amount.Total), stringValue(tx.Amount.Currency))
+		order.PayerAmount = vwxmoney.New(int64Value(tx.Amount.PayerTotal), stringValue(tx.Amount.PayerCurrency))
+	}
+
+	return order
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func int64Value(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
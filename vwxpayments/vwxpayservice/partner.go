@@ -0,0 +1,154 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vogo/vwechatpay"
+	"github.com/vogo/vwechatpay/vwxpartners/vwxpartnerjsapi"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/vogo/vwechatpay/vwxrefund"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/partnerpayments"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+var _ PaymentService = (*PartnerPaymentService)(nil)
+
+// PartnerPaymentService 服务商模式下单个特约商户的支付服务
+type PartnerPaymentService struct {
+	subMchID string
+	subAppID string
+	jsapi    *vwxpartnerjsapi.PartnerJsApiClient
+	refund   *vwxrefund.RefundClient
+}
+
+// NewPartnerPaymentService 创建服务商模式支付服务
+// subMchID: 子商户号
+// subAppID: 子商户应用ID, 可为空, 为空时使用服务商应用下的openid
+func NewPartnerPaymentService(mgr *vwechatpay.Manager, subMchID, subAppID string) *PartnerPaymentService {
+	return &PartnerPaymentService{
+		subMchID: subMchID,
+		subAppID: subAppID,
+		jsapi:    vwxpartnerjsapi.NewPartnerJsApiClient(mgr),
+		refund:   vwxrefund.NewRefundClient(mgr),
+	}
+}
+
+func (s *PartnerPaymentService) Mode() Mode {
+	return ModePartner
+}
+
+// SubMchID 子商户号
+func (s *PartnerPaymentService) SubMchID() string {
+	return s.subMchID
+}
+
+// Prepay 为当前服务的子商户下单, 下单信息中的子商户号与当前服务不一致时返回 ErrSubMchIDMismatch,
+// 否则该订单的支付通知会被 ParseNotify 拒绝.
+func (s *PartnerPaymentService) Prepay(ctx context.Context, order *vwxpayments.PrepayOrder) (*PayParams, error) {
+	if order.SubMchID != "" && order.SubMchID != s.subMchID {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrSubMchIDMismatch, s.subMchID, order.SubMchID)
+	}
+
+	// 复制下单信息, 避免修改调用方的数据
+	o := *order
+	o.SubMchID = s.subMchID
+	if o.SubAppID == "" {
+		o.SubAppID = s.subAppID
+	}
+
+	params, err := s.jsapi.PrepayOrder(ctx, &o)
+	if err != nil {
+		if errors.Is(err, vwxpartnerjsapi.ErrOrderPaid) {
+			return nil, ErrOrderPaid
+		}
+		return nil, err
+	}
+
+	return &PayParams{
+		AppID:     stringValue(params.AppID),
+		TimeStamp: stringValue(params.TimeStamp),
+		NonceStr:  stringValue(params.NonceStr),
+		Package:   stringValue(params.Package),
+		SignType:  stringValue(params.SignType),
+		PaySign:   stringValue(params.PaySign),
+		PayNo:     stringValue(params.PayNo),
+	}, nil
+}
+
+func (s *PartnerPaymentService) QueryOrder(ctx context.Context, outTradeNo string) (*Order, error) {
+	tx, err := s.jsapi.QueryOrderByOutTradeNo(ctx, s.subMchID, outTradeNo)
+	if err != nil {
+		return nil, err
+	}
+	return OrderFromPartnerTransaction(tx), nil
+}
+
+func (s *PartnerPaymentService) QueryOrderByTransactionID(ctx context.Context, transactionID string) (*Order, error) {
+	tx, err := s.jsapi.QueryOrderById(ctx, s.subMchID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return OrderFromPartnerTransaction(tx), nil
+}
+
+func (s *PartnerPaymentService) CloseOrder(ctx context.Context, outTradeNo string) error {
+	return s.jsapi.CloseOrder(ctx, s.subMchID, outTradeNo)
+}
+
+func (s *PartnerPaymentService) Refund(ctx context.Context, req *RefundRequest) (*refunddomestic.Refund, error) {
+	createReq, err := buildRefundRequest(req, s.subMchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.refund.CreateRefund(ctx, createReq)
+}
+
+// ParseNotify 验证并解析支付回调通知, 通知的子商户号与当前服务不一致时返回 ErrSubMchIDMismatch
+// 避免将其他特约商户的支付结果误记到当前商户的订单上.
+func (s *PartnerPaymentService) ParseNotify(headerFetcher func(string) string, body []byte) (*notify.Request, *Order, error) {
+	req, _, err := s.jsapi.PartnerJsApiNotifyParse(headerFetcher, body)
+	if err != nil {
+		return req, nil, err
+	}
+
+	var tx partnerpayments.Transaction
+	if err := json.Unmarshal([]byte(req.Resource.Plaintext), &tx); err != nil {
+		return req, nil, fmt.Errorf("unmarshal transaction error: %w", err)
+	}
+
+	order := OrderFromPartnerTransaction(&tx)
+	if err := s.checkSubMchID(order); err != nil {
+		return req, nil, err
+	}
+
+	return req, order, nil
+}
+
+// checkSubMchID 校验订单属于当前服务的子商户
+func (s *PartnerPaymentService) checkSubMchID(order *Order) error {
+	if order.SubMchID != s.subMchID {
+		return fmt.Errorf("%w: expected %s, got %s", ErrSubMchIDMismatch, s.subMchID, order.SubMchID)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayservice
+
+import (
+	"fmt"
+
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+// buildRefundRequest 构建退款请求, 服务商模式下需传递子商户号
+func buildRefundRequest(req *RefundRequest, subMchID string) (*refunddomestic.CreateRequest, error) {
+	if req.OutRefundNo == "" {
+		return nil, fmt.Errorf("out_refund_no is empty")
+	}
+
+	if req.TransactionID == "" && req.OutTradeNo == "" {
+		return nil, fmt.Errorf("transaction_id and out_trade_no are both empty")
+	}
+
+	if !req.RefundAmount.IsPositive() {
+		return nil, fmt.Errorf("refund amount must be greater than 0: %s", req.RefundAmount)
+	}
+
+	cmp, err := req.RefundAmount.Cmp(req.TotalAmount)
+	if err != nil {
+		return nil, err
+	}
+	if cmp > 0 {
+		return nil, fmt.Errorf("refund amount %s exceeds total amount %s", req.RefundAmount, req.TotalAmount)
+	}
+
+	createReq := &refunddomestic.CreateRequest{
+		OutRefundNo: core.String(req.OutRefundNo),
+		Amount: &refunddomestic.AmountReq{
+			Refund:   core.Int64(req.RefundAmount.Fen()),
+			Total:    core.Int64(req.TotalAmount.Fen()),
+			Currency: core.String(req.RefundAmount.Currency()),
+		},
+	}
+
+	// 优先使用微信支付订单号
+	if req.TransactionID != "" {
+		createReq.TransactionId = core.String(req.TransactionID)
+	} else {
+		createReq.OutTradeNo = core.String(req.OutTradeNo)
+	}
+
+	if req.Reason != "" {
+		createReq.Reason = core.String(req.Reason)
+	}
+
+	if req.NotifyURL != "" {
+		createReq.NotifyUrl = core.String(req.NotifyURL)
+	}
+
+	if req.FundsAccount != "" {
+		createReq.FundsAccount = refunddomestic.ReqFundsAccount(req.FundsAccount).Ptr()
+	}
+
+	if subMchID != "" {
+		createReq.SubMchid = core.String(subMchID)
+	}
+
+	return createReq, nil
+}
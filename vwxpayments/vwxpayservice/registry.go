@@ -0,0 +1,65 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayservice
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry 按商户选择支付服务, 商户标识由业务自定义(如门店ID)
+type Registry struct {
+	mu       sync.RWMutex
+	services map[string]PaymentService
+}
+
+// NewRegistry 创建支付服务注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		services: make(map[string]PaymentService),
+	}
+}
+
+// Register 注册商户对应的支付服务, 已存在时覆盖
+func (r *Registry) Register(merchantKey string, service PaymentService) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.services[merchantKey] = service
+}
+
+// Remove 移除商户对应的支付服务
+func (r *Registry) Remove(merchantKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.services, merchantKey)
+}
+
+// Get 获取商户对应的支付服务
+func (r *Registry) Get(merchantKey string) (PaymentService, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	service, ok := r.services[merchantKey]
+	if !ok {
+		return nil, fmt.Errorf("payment service not found for merchant: %s", merchantKey)
+	}
+
+	return service, nil
+}
@@ -0,0 +1,108 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayservice
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+// Mode 收款模式
+type Mode string
+
+const (
+	ModeDirect  Mode = "DIRECT"  // 直连商户
+	ModePartner Mode = "PARTNER" // 服务商特约商户
+)
+
+var ErrOrderPaid = errors.New("订单已支付")
+
+// ErrSubMchIDMismatch 下单信息或支付通知的子商户号与支付服务的子商户号不一致
+var ErrSubMchIDMismatch = errors.New("子商户号不匹配")
+
+// PaymentService 统一的支付服务, 屏蔽直连商户和服务商模式在接口签名和返回类型上的差异
+type PaymentService interface {
+	// Mode 收款模式
+	Mode() Mode
+
+	// Prepay 下单并返回前端调起支付所需的参数
+	Prepay(ctx context.Context, order *vwxpayments.PrepayOrder) (*PayParams, error)
+
+	// QueryOrder 根据商户订单号查询订单
+	QueryOrder(ctx context.Context, outTradeNo string) (*Order, error)
+
+	// QueryOrderByTransactionID 根据微信支付订单号查询订单
+	QueryOrderByTransactionID(ctx context.Context, transactionID string) (*Order, error)
+
+	// CloseOrder 关闭订单
+	CloseOrder(ctx context.Context, outTradeNo string) error
+
+	// Refund 申请退款
+	Refund(ctx context.Context, req *RefundRequest) (*refunddomestic.Refund, error)
+
+	// ParseNotify 验证并解析支付回调通知
+	ParseNotify(headerFetcher func(string) string, body []byte) (*notify.Request, *Order, error)
+}
+
+// PayParams 前端调起支付所需的参数
+type PayParams struct {
+	AppID     string `json:"appId"`
+	TimeStamp string `json:"timeStamp"`
+	NonceStr  string `json:"nonceStr"`
+	Package   string `json:"package"`
+	SignType  string `json:"signType"`
+	PaySign   string `json:"paySign"`
+	PayNo     string `json:"payNo"`
+}
+
+// Order 统一的订单信息
+type Order struct {
+	Mode           Mode                   `json:"mode"`             // 收款模式
+	AppID          string                 `json:"app_id"`           // 应用ID, 服务商模式下为服务商应用ID
+	MchID          string                 `json:"mch_id"`           // 商户号, 服务商模式下为服务商商户号
+	SubAppID       string                 `json:"sub_app_id"`       // 子商户应用ID
+	SubMchID       string                 `json:"sub_mch_id"`       // 子商户号
+	OutTradeNo     string                 `json:"out_trade_no"`     // 商户订单号
+	TransactionID  string                 `json:"transaction_id"`   // 微信支付订单号
+	TradeType      string                 `json:"trade_type"`       // 交易类型
+	TradeState     vwxpayments.TradeState `json:"trade_state"`      // 交易状态
+	TradeStateDesc string                 `json:"trade_state_desc"` // 交易状态描述
+	BankType       string                 `json:"bank_type"`        // 付款银行
+	Attach         string                 `json:"attach"`           // 附加数据
+	SuccessTime    string                 `json:"success_time"`     // 支付完成时间
+	OpenID         string                 `json:"open_id"`          // 用户标识, 服务商模式下优先为子商户应用下的openid
+	Amount         vwxmoney.Money         `json:"amount"`           // 订单金额
+	PayerAmount    vwxmoney.Money         `json:"payer_amount"`     // 用户实际支付金额
+}
+
+// RefundRequest 统一的退款请求
+type RefundRequest struct {
+	OutRefundNo   string         `json:"out_refund_no"`  // 商户退款单号
+	OutTradeNo    string         `json:"out_trade_no"`   // 商户订单号, 与微信支付订单号二选一
+	TransactionID string         `json:"transaction_id"` // 微信支付订单号, 与商户订单号二选一
+	Reason        string         `json:"reason"`         // 退款原因
+	RefundAmount  vwxmoney.Money `json:"refund_amount"`  // 退款金额
+	TotalAmount   vwxmoney.Money `json:"total_amount"`   // 原订单金额
+	NotifyURL     string         `json:"notify_url"`     // 退款结果回调地址
+	FundsAccount  string         `json:"funds_account"`  // 退款资金来源, 如 AVAILABLE
+}
@@ -0,0 +1,106 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxpayservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpayments"
+)
+
+func TestBuildRefundRequest(t *testing.T) {
+	req := &RefundRequest{
+		OutRefundNo:   "R001",
+		OutTradeNo:    "T001",
+		TransactionID: "4200000001",
+		RefundAmount:  vwxmoney.Fen(50),
+		TotalAmount:   vwxmoney.Fen(100),
+		FundsAccount:  "AVAILABLE",
+	}
+
+	createReq, err := buildRefundRequest(req, "1900000109")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *createReq.TransactionId != "4200000001" || createReq.OutTradeNo != nil {
+		t.Errorf("transaction_id should take precedence over out_trade_no")
+	}
+	if *createReq.SubMchid != "1900000109" {
+		t.Errorf("sub_mchid = %s", *createReq.SubMchid)
+	}
+	if *createReq.Amount.Refund != 50 || *createReq.Amount.Total != 100 || *createReq.Amount.Currency != "CNY" {
+		t.Errorf("unexpected amount: %+v", createReq.Amount)
+	}
+
+	directReq, err := buildRefundRequest(req, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if directReq.SubMchid != nil {
+		t.Errorf("sub_mchid should be empty in direct mode")
+	}
+
+	req.RefundAmount = vwxmoney.Fen(101)
+	if _, err := buildRefundRequest(req, ""); err == nil {
+		t.Errorf("expected error when refund amount exceeds total")
+	}
+
+	req.RefundAmount = vwxmoney.New(50, "USD")
+	if _, err := buildRefundRequest(req, ""); err == nil {
+		t.Errorf("expected error on currency mismatch")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.Get("store-1"); err == nil {
+		t.Errorf("expected error for unknown merchant")
+	}
+
+	svc := &DirectPaymentService{}
+	r.Register("store-1", svc)
+	got, err := r.Get("store-1")
+	if err != nil || got.Mode() != ModeDirect {
+		t.Errorf("unexpected service: %v, %v", got, err)
+	}
+
+	r.Remove("store-1")
+	if _, err := r.Get("store-1"); err == nil {
+		t.Errorf("expected error after remove")
+	}
+}
+
+func TestPartnerCheckSubMchID(t *testing.T) {
+	s := &PartnerPaymentService{subMchID: "1900000109"}
+
+	if err := s.checkSubMchID(&Order{SubMchID: "1900000109"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := s.checkSubMchID(&Order{SubMchID: "1900000110"}); !errors.Is(err, ErrSubMchIDMismatch) {
+		t.Errorf("expected sub_mchid mismatch: %v", err)
+	}
+
+	// 不能通过当前服务为其他子商户下单
+	if _, err := s.Prepay(context.Background(), &vwxpayments.PrepayOrder{SubMchID: "1900000110"}); !errors.Is(err, ErrSubMchIDMismatch) {
+		t.Errorf("expected sub_mchid mismatch on prepay: %v", err)
+	}
+}
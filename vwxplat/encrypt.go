@@ -0,0 +1,186 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxplat
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/consts"
+)
+
+const (
+	// EncryptionTag 敏感字段标签名, 与官方SDK一致, 如 `encryption:"EM_APIV3"`
+	EncryptionTag = "encryption"
+	// EncryptionAPIv3 使用平台证书加密的敏感字段标签值
+	EncryptionAPIv3 = "EM_APIV3"
+)
+
+// ErrAlreadyEncrypted 敏感字段看起来已是平台证书加密后的密文, 调用方应传入明文
+var ErrAlreadyEncrypted = errors.New("sensitive field is already encrypted")
+
+// EncryptRequest 加密请求中的敏感字段
+// 返回请求的副本, 副本中标记 `encryption:"EM_APIV3"` 的非空字符串及字符串指针字段(含嵌套结构体、指针及切片中的字段)已使用平台证书加密, 调用方的明文不变.
+// 标记在其他类型字段上时返回错误, 字段值已是密文时返回 ErrAlreadyEncrypted, 避免重复加密.
+// 存在加密字段时返回携带 Wechatpay-Serial 的请求头, 否则返回的请求头为 nil.
+func (c *PlatManager) EncryptRequest(req any) (any, http.Header, error) {
+	cert := c.LoadCert()
+	return encryptRequest(req, cert)
+}
+
+func encryptRequest(req any, cert *x509.Certificate) (any, http.Header, error) {
+	e := &fieldEncryptor{publicKey: cert.PublicKey.(*rsa.PublicKey)}
+
+	v, err := e.copyValue(reflect.ValueOf(req))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !e.encrypted {
+		return req, nil, nil
+	}
+
+	header := http.Header{}
+	header.Set(consts.WechatPaySerial, vwxutils.GetCertificateSerialNumber(cert))
+
+	return v.Interface(), header, nil
+}
+
+// fieldEncryptor 复制请求并加密敏感字段
+type fieldEncryptor struct {
+	publicKey *rsa.PublicKey
+	encrypted bool
+}
+
+// copyValue 返回值的副本, 结构体、指针及切片会被逐层复制, 其余类型直接共享
+func (e *fieldEncryptor) copyValue(v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := e.copyValue(v.Elem())
+		if err != nil {
+			return v, err
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(elem)
+		return p, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		return e.copyValue(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem, err := e.copyValue(v.Index(i))
+			if err != nil {
+				return v, err
+			}
+			s.Index(i).Set(elem)
+		}
+		return s, nil
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			if field.Tag.Get(EncryptionTag) == EncryptionAPIv3 {
+				f, err := e.encryptField(field, v.Field(i))
+				if err != nil {
+					return v, err
+				}
+				s.Field(i).Set(f)
+				continue
+			}
+
+			f, err := e.copyValue(v.Field(i))
+			if err != nil {
+				return v, err
+			}
+			s.Field(i).Set(f)
+		}
+		return s, nil
+	default:
+		return v, nil
+	}
+}
+
+// encryptField 加密敏感字段, 支持字符串及字符串指针, 空值保持不变
+func (e *fieldEncryptor) encryptField(field reflect.StructField, v reflect.Value) (reflect.Value, error) {
+	switch {
+	case v.Kind() == reflect.String:
+		if v.String() == "" {
+			return v, nil
+		}
+
+		ciphertext, err := e.encrypt(field.Name, v.String())
+		if err != nil {
+			return v, err
+		}
+
+		s := reflect.New(v.Type()).Elem()
+		s.SetString(ciphertext)
+		return s, nil
+	case v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.String:
+		if v.IsNil() || v.Elem().String() == "" {
+			return v, nil
+		}
+
+		ciphertext, err := e.encrypt(field.Name, v.Elem().String())
+		if err != nil {
+			return v, err
+		}
+
+		p := reflect.New(v.Type().Elem())
+		p.Elem().SetString(ciphertext)
+		return p, nil
+	default:
+		return v, fmt.Errorf("encrypt field %s error: unsupported type %s", field.Name, field.Type)
+	}
+}
+
+func (e *fieldEncryptor) encrypt(name, plaintext string) (string, error) {
+	// 平台证书加密的密文为密钥长度的 base64 编码, 明文敏感信息不会是这种形式
+	if raw, err := base64.StdEncoding.DecodeString(plaintext); err == nil && len(raw) == e.publicKey.Size() {
+		return "", fmt.Errorf("%w: field %s", ErrAlreadyEncrypted, name)
+	}
+
+	ciphertext, err := vwxutils.EncryptRSA(plaintext, e.publicKey)
+	if err != nil {
+		return "", fmt.Errorf("encrypt field %s error: %w", name, err)
+	}
+
+	e.encrypted = true
+
+	return ciphertext, nil
+}
@@ -0,0 +1,147 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxplat
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/consts"
+)
+
+type sensitiveItem struct {
+	Account string `json:"account"`
+	Name    string `json:"name" encryption:"EM_APIV3"`
+}
+
+type sensitiveRequest struct {
+	OutBillNo string           `json:"out_bill_no"`
+	UserName  string           `json:"user_name" encryption:"EM_APIV3"`
+	Empty     string           `json:"empty" encryption:"EM_APIV3"`
+	IDNumber  *string          `json:"id_number" encryption:"EM_APIV3"`
+	Items     []*sensitiveItem `json:"items"`
+	Item      *sensitiveItem   `json:"item"`
+}
+
+func newTestCert(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(0x1234ABCD),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, cert
+}
+
+func TestEncryptRequest(t *testing.T) {
+	key, cert := newTestCert(t)
+
+	idNumber := "110101199003071234"
+	req := &sensitiveRequest{
+		OutBillNo: "T001",
+		UserName:  "张三",
+		IDNumber:  &idNumber,
+		Items:     []*sensitiveItem{{Account: "A", Name: "李四"}},
+		Item:      &sensitiveItem{Account: "B"},
+	}
+
+	body, header, err := encryptRequest(req, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Get(consts.WechatPaySerial) != vwxutils.GetCertificateSerialNumber(cert) {
+		t.Errorf("unexpected serial header: %v", header)
+	}
+
+	encrypted := body.(*sensitiveRequest)
+	if req.UserName != "张三" || req.Items[0].Name != "李四" || *req.IDNumber != idNumber {
+		t.Fatalf("original request should not be modified: %+v", req)
+	}
+
+	for want, ciphertext := range map[string]string{"张三": encrypted.UserName, "李四": encrypted.Items[0].Name, idNumber: *encrypted.IDNumber} {
+		plaintext, err := vwxutils.DecryptRSA(ciphertext, key)
+		if err != nil || plaintext != want {
+			t.Errorf("decrypt %s = %s, %v", want, plaintext, err)
+		}
+	}
+
+	if encrypted.OutBillNo != "T001" || encrypted.Empty != "" || encrypted.Item.Account != "B" || encrypted.Item.Name != "" {
+		t.Errorf("unexpected encrypted request: %+v", encrypted)
+	}
+
+	// 无敏感字段时不设置请求头
+	body, header, err = encryptRequest(&sensitiveItem{Account: "A"}, cert)
+	if err != nil || header != nil || body.(*sensitiveItem).Account != "A" {
+		t.Errorf("unexpected result without sensitive fields: %v %v %v", body, header, err)
+	}
+}
+
+func TestEncryptRequestRejects(t *testing.T) {
+	_, cert := newTestCert(t)
+
+	type namedNumber int
+
+	tests := []struct {
+		name string
+		req  any
+	}{
+		{"string slice", &struct {
+			Names []string `encryption:"EM_APIV3"`
+		}{Names: []string{"张三"}}},
+		{"named non-string", &struct {
+			Number namedNumber `encryption:"EM_APIV3"`
+		}{Number: 1}},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := encryptRequest(tt.req, cert); err == nil {
+			t.Errorf("%s: expected unsupported type error", tt.name)
+		}
+	}
+
+	// 已加密的字段不再重复加密
+	encrypted, _, err := encryptRequest(&sensitiveItem{Name: "张三"}, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := encryptRequest(encrypted, cert); !errors.Is(err, ErrAlreadyEncrypted) {
+		t.Errorf("expected ErrAlreadyEncrypted, got %v", err)
+	}
+}
@@ -32,6 +32,9 @@ import (
 	"github.com/wechatpay-apiv3/wechatpay-go/utils"
 )
 
+// certReloadInterval 平台证书重新下载间隔, 与SDK自动更新平台证书的间隔一致
+const certReloadInterval = 24 * time.Hour
+
 type PlatManager struct {
 	mux            sync.Mutex
 	client         *core.Client
@@ -87,25 +90,50 @@ func (c *PlatManager) reloadCert() {
 
 	vlog.Infof("download certificates response | status: %d | resp: %s", result.Response.StatusCode, resp)
 
-	// 解析返回数据获得公钥证书
-	encryptCert := resp.Data[0].EncryptCertificate
-	keyText, err := utils.DecryptAES256GCM(c.apiV3Key, *encryptCert.AssociatedData,
-		*encryptCert.Nonce, *encryptCert.Ciphertext)
-	if err != nil {
-		vlog.Fatalf("failed to decrypt wechat platform merchantCert | err: %v", err)
-		return
+	// 解析返回数据获得公钥证书, 证书轮换期间会同时返回新旧证书
+	certs := make([]*x509.Certificate, 0, len(resp.Data))
+	var platCert *x509.Certificate
+	for _, data := range resp.Data {
+		encryptCert := data.EncryptCertificate
+		keyText, err := utils.DecryptAES256GCM(c.apiV3Key, *encryptCert.AssociatedData,
+			*encryptCert.Nonce, *encryptCert.Ciphertext)
+		if err != nil {
+			vlog.Fatalf("failed to decrypt wechat platform merchantCert | err: %v", err)
+			return
+		}
+
+		// 解码证书
+		cert, err := utils.LoadCertificate(keyText)
+		if err != nil {
+			vlog.Fatalf("failed to load wechat platform merchantCert | err: %v", err)
+			return
+		}
+
+		certs = append(certs, cert)
+
+		// 与SDK一致, 使用生效时间最新的证书加密敏感信息, 保证与SDK请求头中的 Wechatpay-Serial 匹配
+		if platCert == nil || cert.NotBefore.After(platCert.NotBefore) {
+			platCert = cert
+		}
 	}
 
-	// 解码证书
-	platCert, err := utils.LoadCertificate(keyText)
-	if err != nil {
-		vlog.Fatalf("failed to load wechat platform merchantCert | err: %v", err)
+	if platCert == nil {
+		vlog.Fatalf("no wechat platform merchantCert downloaded")
 		return
 	}
 
 	c.platformCert = platCert
-	c.verifier = verifiers.NewSHA256WithRSAVerifier(core.NewCertificateMapWithList([]*x509.Certificate{platCert}))
-	c.expireTime = (*resp.Data[0].ExpireTime).Add(-60 * time.Second)
+	c.verifier = verifiers.NewSHA256WithRSAVerifier(core.NewCertificateMapWithList(certs))
+	// 定期重新下载, 及时使用轮换后的新证书
+	c.expireTime = platCert.NotAfter.Add(-60 * time.Second)
+	if reloadTime := time.Now().Add(certReloadInterval); reloadTime.Before(c.expireTime) {
+		c.expireTime = reloadTime
+	}
+}
+
+// SerialNo 加密敏感信息所用平台证书的序列号, 请求时需通过 Wechatpay-Serial 头传递
+func (c *PlatManager) SerialNo() string {
+	return vwxutils.GetCertificateSerialNumber(c.LoadCert())
 }
 
 // EncryptSensitiveInfo 使用微信支付平台证书加密敏感信息
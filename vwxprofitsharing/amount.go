@@ -0,0 +1,78 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+// RemainingAmount 订单剩余待分金额
+type RemainingAmount struct {
+	TransactionID string         `json:"transaction_id"` // 微信支付订单号
+	UnsplitAmount vwxmoney.Money `json:"unsplit_amount"` // 订单剩余待分金额
+}
+
+// QueryRemainingAmount 查询订单剩余待分金额
+func (c *ProfitSharingClient) QueryRemainingAmount(ctx context.Context, transactionID string) (*RemainingAmount, error) {
+	if transactionID == "" {
+		return nil, fmt.Errorf("transaction_id is empty")
+	}
+
+	vlog.Infof("query profit sharing remaining amount | transaction_id: %s", transactionID)
+
+	var amount RemainingAmount
+	path := "/transactions/" + url.PathEscape(transactionID) + "/amounts"
+	if err := c.request(ctx, http.MethodGet, path, nil, nil, nil, &amount); err != nil {
+		return nil, err
+	}
+
+	return &amount, nil
+}
+
+// MerchantRatio 子商户允许服务商分账的最大比例
+type MerchantRatio struct {
+	SubMchID string `json:"sub_mchid"` // 子商户号
+	MaxRatio int64  `json:"max_ratio"` // 最大分账比例, 单位万分比, 如 2000 表示 20%
+}
+
+// MaxAmount 按最大分账比例计算订单金额可分账的最大金额, 向下取整到分
+func (r *MerchantRatio) MaxAmount(total vwxmoney.Money) vwxmoney.Money {
+	return vwxmoney.New(total.Fen()*r.MaxRatio/MaxRatioBase, total.Currency())
+}
+
+// QueryMaxRatio 查询子商户允许服务商分账的最大比例, 仅服务商模式
+func (c *ProfitSharingClient) QueryMaxRatio(ctx context.Context, subMchID string) (*MerchantRatio, error) {
+	if subMchID == "" {
+		return nil, fmt.Errorf("sub_mchid is empty")
+	}
+
+	vlog.Infof("query profit sharing max ratio | sub_mchid: %s", subMchID)
+
+	var ratio MerchantRatio
+	if err := c.request(ctx, http.MethodGet, "/merchant-configs/"+url.PathEscape(subMchID), nil, nil, nil, &ratio); err != nil {
+		return nil, err
+	}
+
+	return &ratio, nil
+}
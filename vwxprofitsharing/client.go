@@ -0,0 +1,45 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/vogo/vwechatpay"
+)
+
+// APIBaseURL 分账接口地址前缀
+const APIBaseURL = "https://api.mch.weixin.qq.com/v3/profitsharing"
+
+// ProfitSharingClient 微信分账客户端, 直连商户和服务商模式共用, 服务商模式下请求中需传入子商户号
+type ProfitSharingClient struct {
+	mgr *vwechatpay.Manager
+}
+
+func NewProfitSharingClient(mgr *vwechatpay.Manager) *ProfitSharingClient {
+	return &ProfitSharingClient{
+		mgr: mgr,
+	}
+}
+
+// request 发送请求并将响应解析到 resp
+func (c *ProfitSharingClient) request(ctx context.Context, method, path string, header http.Header, query url.Values, body, resp any) error {
+	return c.mgr.RequestJSON(ctx, "profit sharing", method, APIBaseURL+path, header, query, body, resp)
+}
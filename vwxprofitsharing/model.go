@@ -0,0 +1,142 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"unicode/utf8"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+const (
+	// MaxReceivers 单次分账请求最多分账接收方数量
+	MaxReceivers = 50
+	// MaxDescriptionChars 分账描述最大字符数
+	MaxDescriptionChars = 80
+	// MaxRatioBase 最大分账比例的基数, 最大分账比例以万分比表示
+	MaxRatioBase = 10000
+)
+
+// ReceiverType 分账接收方类型
+type ReceiverType string
+
+const (
+	ReceiverTypeMerchantID        ReceiverType = "MERCHANT_ID"         // 商户号
+	ReceiverTypePersonalOpenID    ReceiverType = "PERSONAL_OPENID"     // 个人openid(由服务商或直连商户的APPID转换得到)
+	ReceiverTypePersonalSubOpenID ReceiverType = "PERSONAL_SUB_OPENID" // 个人sub_openid(由子商户的APPID转换得到), 仅服务商模式
+)
+
+// RelationType 与分账方的关系类型
+type RelationType string
+
+const (
+	RelationTypeStore       RelationType = "STORE"       // 门店
+	RelationTypeStaff       RelationType = "STAFF"       // 员工
+	RelationTypeStoreOwner  RelationType = "STORE_OWNER" // 店主
+	RelationTypePartner     RelationType = "PARTNER"     // 合作伙伴
+	RelationTypeHeadquarter RelationType = "HEADQUARTER" // 总部
+	RelationTypeBrand       RelationType = "BRAND"       // 品牌方
+	RelationTypeDistributor RelationType = "DISTRIBUTOR" // 分销商
+	RelationTypeUser        RelationType = "USER"        // 用户
+	RelationTypeSupplier    RelationType = "SUPPLIER"    // 供应商
+	RelationTypeCustom      RelationType = "CUSTOM"      // 自定义
+)
+
+// OrderState 分账单状态
+type OrderState string
+
+const (
+	OrderStateProcessing OrderState = "PROCESSING" // 处理中
+	OrderStateFinished   OrderState = "FINISHED"   // 分账完成
+)
+
+// DetailResult 分账接收方的分账结果
+type DetailResult string
+
+const (
+	DetailResultPending DetailResult = "PENDING" // 待分账
+	DetailResultSuccess DetailResult = "SUCCESS" // 分账成功
+	DetailResultClosed  DetailResult = "CLOSED"  // 已关闭
+)
+
+// ReturnResult 分账回退结果
+type ReturnResult string
+
+const (
+	ReturnResultProcessing ReturnResult = "PROCESSING" // 处理中
+	ReturnResultSuccess    ReturnResult = "SUCCESS"    // 已成功
+	ReturnResultFailed     ReturnResult = "FAILED"     // 已失败
+)
+
+// OrderReceiver 请求分账的分账接收方
+type OrderReceiver struct {
+	Type        ReceiverType   `json:"type"`                                 // 分账接收方类型
+	Account     string         `json:"account"`                              // 分账接收方账号, 商户号或openid
+	Name        string         `json:"name,omitempty" encryption:"EM_APIV3"` // 分账个人接收方姓名, 传明文, 请求时自动加密
+	Amount      vwxmoney.Money `json:"amount"`                               // 分账金额
+	Description string         `json:"description"`                          // 分账描述
+}
+
+// OrderReceiverDetail 分账单中分账接收方的分账结果
+type OrderReceiverDetail struct {
+	Type        ReceiverType   `json:"type"`        // 分账接收方类型
+	Account     string         `json:"account"`     // 分账接收方账号
+	Amount      vwxmoney.Money `json:"amount"`      // 分账金额
+	Description string         `json:"description"` // 分账描述
+	Result      DetailResult   `json:"result"`      // 分账结果
+	FailReason  string         `json:"fail_reason"` // 分账失败原因
+	CreateTime  string         `json:"create_time"` // 分账创建时间
+	FinishTime  string         `json:"finish_time"` // 分账完成时间
+	DetailID    string         `json:"detail_id"`   // 分账明细单号
+}
+
+// Order 分账单
+type Order struct {
+	SubMchID      string                 `json:"sub_mchid"`      // 子商户号, 服务商模式下返回
+	TransactionID string                 `json:"transaction_id"` // 微信支付订单号
+	OutOrderNo    string                 `json:"out_order_no"`   // 商户分账单号
+	OrderID       string                 `json:"order_id"`       // 微信分账单号
+	State         OrderState             `json:"state"`          // 分账单状态
+	Receivers     []*OrderReceiverDetail `json:"receivers"`      // 分账接收方列表
+}
+
+// IsFinished 分账单是否已处理完成
+func (o *Order) IsFinished() bool {
+	return o.State == OrderStateFinished
+}
+
+// ReturnOrder 分账回退单
+type ReturnOrder struct {
+	SubMchID    string         `json:"sub_mchid"`     // 子商户号, 服务商模式下返回
+	OrderID     string         `json:"order_id"`      // 微信分账单号
+	OutOrderNo  string         `json:"out_order_no"`  // 商户分账单号
+	OutReturnNo string         `json:"out_return_no"` // 商户回退单号
+	ReturnID    string         `json:"return_id"`     // 微信回退单号
+	ReturnMchID string         `json:"return_mchid"`  // 回退商户号
+	Amount      vwxmoney.Money `json:"amount"`        // 回退金额
+	Description string         `json:"description"`   // 回退描述
+	Result      ReturnResult   `json:"result"`        // 回退结果
+	FailReason  string         `json:"fail_reason"`   // 失败原因
+	CreateTime  string         `json:"create_time"`   // 创建时间
+	FinishTime  string         `json:"finish_time"`   // 完成时间
+}
+
+// validDescription 校验分账或回退描述
+func validDescription(description string) bool {
+	return description != "" && utf8.RuneCountInString(description) <= MaxDescriptionChars
+}
@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"context"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+)
+
+const (
+	// NotifyEventSuccess 分账成功通知
+	NotifyEventSuccess = "PROFITSHARING.SUCCESS"
+	// NotifyEventClosed 分账失败(已关闭)通知
+	NotifyEventClosed = "PROFITSHARING.CLOSED"
+)
+
+// NotifyReceiver 分账动账通知中的分账接收方
+type NotifyReceiver struct {
+	Type        ReceiverType   `json:"type"`        // 分账接收方类型
+	Account     string         `json:"account"`     // 分账接收方账号
+	Amount      vwxmoney.Money `json:"amount"`      // 分账金额
+	Description string         `json:"description"` // 分账描述
+}
+
+// ProfitSharingNotify 分账动账通知, 每个分账接收方单独通知
+type ProfitSharingNotify struct {
+	MchID         string          `json:"mchid"`          // 直连商户号, 直连商户模式下返回
+	SpMchID       string          `json:"sp_mchid"`       // 服务商商户号, 服务商模式下返回
+	SubMchID      string          `json:"sub_mchid"`      // 子商户号, 服务商模式下返回
+	TransactionID string          `json:"transaction_id"` // 微信支付订单号
+	OrderID       string          `json:"order_id"`       // 微信分账单号
+	OutOrderNo    string          `json:"out_order_no"`   // 商户分账单号
+	Receiver      *NotifyReceiver `json:"receiver"`       // 分账接收方
+	SuccessTime   string          `json:"success_time"`   // 成功时间
+}
+
+// ParseProfitSharingNotify 解析分账动账通知
+// 商户需要验证签名，确保回调通知的真实性
+func (c *ProfitSharingClient) ParseProfitSharingNotify(headerFetcher func(string) string, body []byte) (*notify.Request, *ProfitSharingNotify, error) {
+	ctx := context.Background()
+
+	// 验证回调通知签名
+	err := c.mgr.PlatManager.VerifyRequestMessage(ctx, headerFetcher, body)
+	if err != nil {
+		vlog.Errorf("validate http message failed | err: %v", err)
+		return nil, nil, err
+	}
+
+	return c.ParseProfitSharingNotifyBody(body)
+}
+
+// ParseProfitSharingNotifyBody 解析分账动账通知体
+func (c *ProfitSharingClient) ParseProfitSharingNotifyBody(body []byte) (*notify.Request, *ProfitSharingNotify, error) {
+	return vwxutils.ParseNotifyBody[ProfitSharingNotify](c.mgr.Config.MerchantAPIv3Key, "profit sharing", body)
+}
@@ -0,0 +1,419 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/vogo/vwechatpay/vwxpayments/vwxpayservice"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	defaultTickInterval   = 10 * time.Second
+	defaultInitialBackoff = 30 * time.Second
+	defaultMaxBackoff     = 30 * time.Minute
+	// defaultTaskRetention 已完成分账任务的默认保留时长, 覆盖微信支付重复发送支付通知的时间范围
+	defaultTaskRetention = 48 * time.Hour
+
+	// unfreezeDescription 自动解冻剩余资金的解冻原因
+	unfreezeDescription = "解冻全部剩余资金"
+)
+
+// TaskStage 分账任务阶段
+type TaskStage string
+
+const (
+	TaskStageSharing    TaskStage = "SHARING"    // 分账处理中
+	TaskStageUnfreezing TaskStage = "UNFREEZING" // 解冻剩余资金处理中
+)
+
+// SharingTask 订单分账任务
+type SharingTask struct {
+	SubMchID        string    `json:"sub_mchid"`                   // 子商户号, 服务商模式下使用
+	TransactionID   string    `json:"transaction_id"`              // 微信支付订单号
+	OutTradeNo      string    `json:"out_trade_no"`                // 商户订单号
+	OutOrderNo      string    `json:"out_order_no,omitempty"`      // 商户分账单号, 无分账接收方时为空
+	UnfreezeOrderNo string    `json:"unfreeze_order_no,omitempty"` // 解冻剩余资金的商户分账单号
+	Stage           TaskStage `json:"stage"`                       // 任务阶段
+	Order           *Order    `json:"order,omitempty"`             // 分账完成后的分账单
+	FailedReported  bool      `json:"failed_reported,omitempty"`   // 分账失败的接收方是否已回调
+
+	vwxutils.PollSchedule // 当前阶段的查询进度
+}
+
+// Key 分账任务在存储中的唯一标识
+func (t *SharingTask) Key() string {
+	return t.TransactionID
+}
+
+// SharingOutOrderNo 根据微信支付订单号生成确定的商户分账单号, 重复处理同一订单时不会重复分账
+func SharingOutOrderNo(transactionID string) string {
+	return "PS" + transactionID
+}
+
+// UnfreezeOutOrderNo 根据微信支付订单号生成确定的解冻剩余资金商户分账单号
+func UnfreezeOutOrderNo(transactionID string) string {
+	return "UF" + transactionID
+}
+
+// SharingTaskStore 分账任务存储, 实现持久化存储可在服务重启后继续跟踪分账, Get 及 Remove 的 key 为微信支付订单号
+type SharingTaskStore = vwxutils.PollStore[SharingTask]
+
+// MemorySharingTaskStore 基于内存的分账任务存储
+type MemorySharingTaskStore = vwxutils.MemoryPollStore[SharingTask, *SharingTask]
+
+// NewMemorySharingTaskStore 创建基于内存的分账任务存储
+func NewMemorySharingTaskStore() *MemorySharingTaskStore {
+	return vwxutils.NewMemoryPollStore((*SharingTask).Key)
+}
+
+// RuleProvider 获取子商户的分账规则, 直连商户模式下 subMchID 为空, 返回 nil 表示不分账
+type RuleProvider func(ctx context.Context, subMchID string) (*RuleSet, error)
+
+// StaticRules 所有订单使用同一套分账规则
+func StaticRules(rules *RuleSet) RuleProvider {
+	return func(context.Context, string) (*RuleSet, error) {
+		return rules, nil
+	}
+}
+
+// SharingFinishHandler 分账及解冻剩余资金全部完成时的回调, 无分账接收方时 task.Order 为空
+type SharingFinishHandler func(ctx context.Context, task *SharingTask)
+
+// ReceiverFailedHandler 分账完成但部分接收方分账失败(已关闭)时的回调, 在解冻剩余资金前调用
+// 解冻后这部分资金将退回商户, 需要业务补偿时应在回调中记录.
+type ReceiverFailedHandler func(ctx context.Context, task *SharingTask, failed []*OrderReceiverDetail)
+
+// SharingGiveUpHandler 分账任务超过最大查询次数仍未完成, 放弃跟踪时的回调
+type SharingGiveUpHandler func(ctx context.Context, task *SharingTask)
+
+// sharingClient 分账接口, 由 ProfitSharingClient 实现
+type sharingClient interface {
+	CreateOrder(ctx context.Context, req *CreateOrderRequest) (*Order, error)
+	QueryOrder(ctx context.Context, subMchID, transactionID, outOrderNo string) (*Order, error)
+	UnfreezeOrder(ctx context.Context, req *UnfreezeOrderRequest) (*Order, error)
+	QueryRemainingAmount(ctx context.Context, transactionID string) (*RemainingAmount, error)
+	QueryMaxRatio(ctx context.Context, subMchID string) (*MerchantRatio, error)
+}
+
+// Orchestrator 分账编排器
+// 订单支付成功后按分账规则计算各接收方金额并请求分账, 跟踪分账单直至完成, 再自动解冻剩余资金.
+type Orchestrator struct {
+	client  sharingClient
+	rules   RuleProvider
+	handler SharingFinishHandler
+	failed  ReceiverFailedHandler
+	poller  *vwxutils.Poller[SharingTask, *SharingTask]
+}
+
+// OrchestratorOption 分账编排器可选项
+type OrchestratorOption func(*Orchestrator)
+
+// WithSharingTaskStore 设置分账任务存储, 默认使用内存存储
+func WithSharingTaskStore(store SharingTaskStore) OrchestratorOption {
+	return func(o *Orchestrator) { o.poller.Store = store }
+}
+
+// WithReceiverFailedHandler 设置接收方分账失败的回调, 未设置时仅记录错误日志
+func WithReceiverFailedHandler(handler ReceiverFailedHandler) OrchestratorOption {
+	return func(o *Orchestrator) { o.failed = handler }
+}
+
+// WithSharingBackoff 设置轮询查询分账单的退避间隔
+func WithSharingBackoff(initial, max time.Duration) OrchestratorOption {
+	return func(o *Orchestrator) { o.poller.Backoff = vwxutils.NewBackoff(initial, max) }
+}
+
+// WithSharingTickInterval 设置扫描分账任务的间隔
+func WithSharingTickInterval(interval time.Duration) OrchestratorOption {
+	return func(o *Orchestrator) { o.poller.TickInterval = interval }
+}
+
+// WithSharingTaskRetention 设置已完成分账任务的保留时长, 保留期内重复处理同一订单不会重复回调, 默认48小时
+func WithSharingTaskRetention(retention time.Duration) OrchestratorOption {
+	return func(o *Orchestrator) { o.poller.Retention = retention }
+}
+
+// WithSharingMaxAttempts 设置每个阶段的最大查询次数, 超过后回调 handler 并放弃跟踪, 默认不限制
+func WithSharingMaxAttempts(max int, handler SharingGiveUpHandler) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.poller.MaxAttempts = max
+		o.poller.GiveUp = handler
+	}
+}
+
+// NewOrchestrator 创建分账编排器, 后台任务运行在 Manager 的 Runner 上, Manager 停止时随之停止
+func NewOrchestrator(client *ProfitSharingClient, rules RuleProvider, handler SharingFinishHandler, opts ...OrchestratorOption) *Orchestrator {
+	return newOrchestrator(client, client.mgr.Runner().NewChild(), rules, handler, opts...)
+}
+
+func newOrchestrator(client sharingClient, runner *vrun.Runner, rules RuleProvider, handler SharingFinishHandler, opts ...OrchestratorOption) *Orchestrator {
+	o := &Orchestrator{
+		client:  client,
+		rules:   rules,
+		handler: handler,
+	}
+
+	o.poller = vwxutils.NewPoller("profit sharing task", runner, (*SharingTask).Key, o.check)
+	o.poller.Backoff = vwxutils.NewBackoff(defaultInitialBackoff, defaultMaxBackoff)
+	o.poller.TickInterval = defaultTickInterval
+	o.poller.Retention = defaultTaskRetention
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Start 启动后台轮询, 存储中已有的分账任务会继续被跟踪
+func (o *Orchestrator) Start() {
+	o.poller.Start()
+}
+
+// Stop 停止后台轮询
+func (o *Orchestrator) Stop() {
+	o.poller.Stop()
+}
+
+// HandleTransaction 处理支付成功的订单, 在收到 TRANSACTION.SUCCESS 支付通知后调用
+// 以订单剩余待分金额(扣除手续费及商户出资的优惠)为基数按分账规则计算各接收方金额并请求分账, 无分账接收方时直接解冻全部资金.
+// 同一订单重复调用(如重复的支付通知)时返回已保存的分账任务, 由后台轮询继续跟踪, 回调不会重复触发;
+// 任务已不在存储中时先查询已有的分账单, 不会重复分账.
+func (o *Orchestrator) HandleTransaction(ctx context.Context, order *vwxpayservice.Order) (*SharingTask, error) {
+	if order.TradeState != vwxpayments.TradeStateSuccess {
+		return nil, fmt.Errorf("order %s is not paid: %s", order.OutTradeNo, order.TradeState)
+	}
+
+	if order.TransactionID == "" {
+		return nil, fmt.Errorf("transaction_id of order %s is empty", order.OutTradeNo)
+	}
+
+	stored, err := o.poller.Get(ctx, order.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("get profit sharing task error: %w", err)
+	}
+	if stored != nil {
+		vlog.Infof("profit sharing task exists | transaction_id: %s | stage: %s | done: %t", stored.TransactionID, stored.Stage, stored.Done)
+		return stored, nil
+	}
+
+	task := &SharingTask{
+		SubMchID:      order.SubMchID,
+		TransactionID: order.TransactionID,
+		OutTradeNo:    order.OutTradeNo,
+		OutOrderNo:    SharingOutOrderNo(order.TransactionID),
+		Stage:         TaskStageSharing,
+	}
+
+	// 已请求过分账时继续跟踪已有的分账单
+	existing, err := o.client.QueryOrder(ctx, order.SubMchID, order.TransactionID, task.OutOrderNo)
+	if err == nil {
+		return task, o.trackSharing(ctx, task, existing)
+	}
+	if !vwxutils.IsNotFound(err) {
+		return nil, fmt.Errorf("query profit sharing order error: %w", err)
+	}
+
+	rules, err := o.rules(ctx, order.SubMchID)
+	if err != nil {
+		return nil, fmt.Errorf("get profit sharing rules error: %w", err)
+	}
+
+	remaining, err := o.client.QueryRemainingAmount(ctx, order.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("query remaining amount error: %w", err)
+	}
+
+	var receivers []*OrderReceiver
+	if rules != nil && remaining.UnsplitAmount.IsPositive() {
+		var maxRatio int64
+		if rules.CapByMaxRatio && order.SubMchID != "" {
+			ratio, err := o.client.QueryMaxRatio(ctx, order.SubMchID)
+			if err != nil {
+				return nil, fmt.Errorf("query max ratio error: %w", err)
+			}
+			maxRatio = ratio.MaxRatio
+		}
+
+		// 分账金额及最大分账比例均以剩余待分金额计算
+		if receivers, err = rules.Calculate(remaining.UnsplitAmount, maxRatio); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(receivers) == 0 {
+		vlog.Infof("no profit sharing receivers, unfreeze order | transaction_id: %s", order.TransactionID)
+		task.Stage = ""
+		task.OutOrderNo = ""
+
+		done, err := o.unfreeze(ctx, task)
+		if err != nil {
+			return task, err
+		}
+		if done {
+			return task, o.poller.Complete(ctx, task)
+		}
+		return task, o.poller.Schedule(ctx, task)
+	}
+
+	sharingOrder, err := o.client.CreateOrder(ctx, &CreateOrderRequest{
+		SubMchID:      order.SubMchID,
+		SubAppID:      order.SubAppID,
+		TransactionID: order.TransactionID,
+		OutOrderNo:    task.OutOrderNo,
+		Receivers:     receivers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create profit sharing order error: %w", err)
+	}
+
+	return task, o.trackSharing(ctx, task, sharingOrder)
+}
+
+// trackSharing 分账单已完成时解冻剩余资金, 否则由后台轮询跟踪
+func (o *Orchestrator) trackSharing(ctx context.Context, task *SharingTask, order *Order) error {
+	if order.IsFinished() {
+		done, err := o.sharingFinished(ctx, task, order)
+		if err != nil {
+			// 分账已完成, 解冻失败时由后台轮询重试
+			vlog.Errorf("unfreeze profit sharing order error | transaction_id: %s | err: %v", task.TransactionID, err)
+		} else if done {
+			return o.poller.Complete(ctx, task)
+		}
+	}
+
+	return o.poller.Schedule(ctx, task)
+}
+
+// check 查询当前阶段的分账单, 完成时进入下一阶段, 返回分账任务是否已全部完成
+func (o *Orchestrator) check(ctx context.Context, task *SharingTask) bool {
+	outOrderNo := task.OutOrderNo
+	if task.Stage == TaskStageUnfreezing {
+		outOrderNo = task.UnfreezeOrderNo
+	}
+
+	order, err := o.client.QueryOrder(ctx, task.SubMchID, task.TransactionID, outOrderNo)
+	if err != nil {
+		vlog.Errorf("query profit sharing order error | out_order_no: %s | err: %v", outOrderNo, err)
+		return false
+	}
+
+	if !order.IsFinished() {
+		return false
+	}
+
+	if task.Stage == TaskStageUnfreezing {
+		o.finish(ctx, task)
+		return true
+	}
+
+	done, err := o.sharingFinished(ctx, task, order)
+	if err != nil {
+		vlog.Errorf("unfreeze profit sharing order error | transaction_id: %s | err: %v", task.TransactionID, err)
+		return false
+	}
+
+	return done
+}
+
+// sharingFinished 分账完成后回调分账失败的接收方, 再解冻剩余资金, 返回分账任务是否已全部完成
+func (o *Orchestrator) sharingFinished(ctx context.Context, task *SharingTask, order *Order) (bool, error) {
+	task.Order = order
+
+	vlog.Infof("profit sharing order finished | transaction_id: %s | out_order_no: %s", task.TransactionID, task.OutOrderNo)
+
+	if !task.FailedReported {
+		o.reportFailed(ctx, task, order)
+		task.FailedReported = true
+	}
+
+	return o.unfreeze(ctx, task)
+}
+
+// reportFailed 回调分账失败(已关闭)的接收方, 解冻后这部分资金将退回商户
+func (o *Orchestrator) reportFailed(ctx context.Context, task *SharingTask, order *Order) {
+	var failed []*OrderReceiverDetail
+	for _, receiver := range order.Receivers {
+		if receiver.Result == DetailResultClosed {
+			failed = append(failed, receiver)
+		}
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	for _, receiver := range failed {
+		vlog.Errorf("profit sharing receiver failed | transaction_id: %s | account: %s | amount: %s | reason: %s",
+			task.TransactionID, receiver.Account, receiver.Amount, receiver.FailReason)
+	}
+
+	if o.failed != nil {
+		o.failed(ctx, task, failed)
+	}
+}
+
+// unfreeze 解冻剩余资金, 无剩余待分金额时直接完成任务, 返回分账任务是否已全部完成
+func (o *Orchestrator) unfreeze(ctx context.Context, task *SharingTask) (bool, error) {
+	amount, err := o.client.QueryRemainingAmount(ctx, task.TransactionID)
+	if err != nil {
+		return false, fmt.Errorf("query remaining amount error: %w", err)
+	}
+
+	if !amount.UnsplitAmount.IsPositive() {
+		o.finish(ctx, task)
+		return true, nil
+	}
+
+	outOrderNo := UnfreezeOutOrderNo(task.TransactionID)
+	order, err := o.client.UnfreezeOrder(ctx, &UnfreezeOrderRequest{
+		SubMchID:      task.SubMchID,
+		TransactionID: task.TransactionID,
+		OutOrderNo:    outOrderNo,
+		Description:   unfreezeDescription,
+	})
+	if err != nil {
+		return false, fmt.Errorf("unfreeze order error: %w", err)
+	}
+
+	task.Stage = TaskStageUnfreezing
+	task.UnfreezeOrderNo = outOrderNo
+	task.Attempts = 0
+
+	if order.IsFinished() {
+		o.finish(ctx, task)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (o *Orchestrator) finish(ctx context.Context, task *SharingTask) {
+	vlog.Infof("profit sharing task finished | transaction_id: %s | stage: %s", task.TransactionID, task.Stage)
+
+	if o.handler != nil {
+		o.handler(ctx, task)
+	}
+}
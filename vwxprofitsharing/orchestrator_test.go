@@ -0,0 +1,216 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/vogo/vwechatpay/vwxpayments/vwxpayservice"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+)
+
+func TestRuleSetCalculate(t *testing.T) {
+	rules := &RuleSet{Rules: []*Rule{
+		{Kind: RuleKindPercentage, Type: ReceiverTypeMerchantID, Account: "A", Description: "门店", Ratio: 3333},
+		{Kind: RuleKindFixed, Type: ReceiverTypeMerchantID, Account: "B", Description: "运费", Amount: vwxmoney.Fen(100)},
+		{Kind: RuleKindPercentage, Type: ReceiverTypeMerchantID, Account: "C", Description: "零", Ratio: 1},
+	}}
+
+	receivers, err := rules.Calculate(vwxmoney.Fen(999), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receivers) != 2 || receivers[0].Amount != vwxmoney.Fen(332) || receivers[1].Amount != vwxmoney.Fen(100) {
+		t.Errorf("unexpected receivers: %+v %+v", receivers[0], receivers[1])
+	}
+
+	// 最大分账比例 30% 即 299 分, 按 332:100 扣减
+	receivers, err = rules.Calculate(vwxmoney.Fen(999), 3000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, _ := vwxmoney.Sum(receivers[0].Amount, receivers[1].Amount)
+	if sum != vwxmoney.Fen(299) || receivers[0].Amount != vwxmoney.Fen(230) {
+		t.Errorf("unexpected capped receivers: %s %s", receivers[0].Amount, receivers[1].Amount)
+	}
+
+	if _, err := rules.Calculate(vwxmoney.Fen(50), 0); !errors.Is(err, ErrRulesExceedAmount) {
+		t.Errorf("expected ErrRulesExceedAmount, got %v", err)
+	}
+}
+
+type fakeSharingClient struct {
+	orders   map[string]*Order
+	unsplit  vwxmoney.Money
+	created  []*CreateOrderRequest
+	unfrozen []*UnfreezeOrderRequest
+	maxRatio int64
+}
+
+func (c *fakeSharingClient) CreateOrder(_ context.Context, req *CreateOrderRequest) (*Order, error) {
+	c.created = append(c.created, req)
+	order := &Order{TransactionID: req.TransactionID, OutOrderNo: req.OutOrderNo, State: OrderStateProcessing}
+	c.orders[req.OutOrderNo] = order
+	return order, nil
+}
+
+func (c *fakeSharingClient) QueryOrder(_ context.Context, _, _, outOrderNo string) (*Order, error) {
+	order, ok := c.orders[outOrderNo]
+	if !ok {
+		return nil, &core.APIError{StatusCode: http.StatusNotFound, Code: "RESOURCE_NOT_EXISTS"}
+	}
+	return order, nil
+}
+
+func (c *fakeSharingClient) UnfreezeOrder(_ context.Context, req *UnfreezeOrderRequest) (*Order, error) {
+	c.unfrozen = append(c.unfrozen, req)
+	order := &Order{TransactionID: req.TransactionID, OutOrderNo: req.OutOrderNo, State: OrderStateProcessing}
+	c.orders[req.OutOrderNo] = order
+	return order, nil
+}
+
+func (c *fakeSharingClient) QueryRemainingAmount(_ context.Context, transactionID string) (*RemainingAmount, error) {
+	return &RemainingAmount{TransactionID: transactionID, UnsplitAmount: c.unsplit}, nil
+}
+
+func (c *fakeSharingClient) QueryMaxRatio(_ context.Context, subMchID string) (*MerchantRatio, error) {
+	return &MerchantRatio{SubMchID: subMchID, MaxRatio: c.maxRatio}, nil
+}
+
+func TestOrchestrator(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeSharingClient{orders: map[string]*Order{}, unsplit: vwxmoney.Fen(700), maxRatio: 2000}
+	rules := &RuleSet{CapByMaxRatio: true, Rules: []*Rule{
+		{Kind: RuleKindPercentage, Type: ReceiverTypeMerchantID, Account: "1900000110", Description: "门店", Ratio: 3000},
+	}}
+
+	var finished []*SharingTask
+	var failed []*OrderReceiverDetail
+	o := newOrchestrator(client, vrun.New(), StaticRules(rules), func(_ context.Context, task *SharingTask) {
+		finished = append(finished, task)
+	}, WithSharingBackoff(0, time.Minute), WithReceiverFailedHandler(func(_ context.Context, _ *SharingTask, receivers []*OrderReceiverDetail) {
+		failed = append(failed, receivers...)
+	}))
+
+	order := &vwxpayservice.Order{
+		SubMchID:      "1900000109",
+		OutTradeNo:    "T001",
+		TransactionID: "4200000000000000000000000001",
+		TradeState:    vwxpayments.TradeStateSuccess,
+		Amount:        vwxmoney.Fen(1000),
+	}
+
+	task, err := o.HandleTransaction(ctx, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 以剩余待分金额 700 分为基数, 30% 为 210 分, 最大分账比例 20% 即 140 分
+	if task.Stage != TaskStageSharing || len(client.created) != 1 || client.created[0].Receivers[0].Amount != vwxmoney.Fen(140) {
+		t.Fatalf("unexpected sharing request: %+v", client.created)
+	}
+
+	// 分账处理中, 继续跟踪
+	o.poller.Poll()
+	if len(client.unfrozen) != 0 {
+		t.Fatal("should not unfreeze before sharing finished")
+	}
+
+	// 重复处理同一订单时继续跟踪已有的分账单
+	if _, err := o.HandleTransaction(ctx, order); err != nil || len(client.created) != 1 {
+		t.Fatalf("should not create sharing order again: %v, %d", err, len(client.created))
+	}
+
+	// 分账完成后回调失败的接收方, 再解冻剩余资金
+	client.orders[task.OutOrderNo].State = OrderStateFinished
+	client.orders[task.OutOrderNo].Receivers = []*OrderReceiverDetail{
+		{Account: "1900000110", Amount: vwxmoney.Fen(140), Result: DetailResultClosed, FailReason: "ACCOUNT_ABNORMAL"},
+	}
+	o.poller.Poll()
+	if len(failed) != 1 || failed[0].Account != "1900000110" {
+		t.Fatalf("unexpected failed receivers: %+v", failed)
+	}
+	if len(client.unfrozen) != 1 || client.unfrozen[0].OutOrderNo != UnfreezeOutOrderNo(order.TransactionID) {
+		t.Fatalf("unexpected unfreeze request: %+v", client.unfrozen)
+	}
+	if len(finished) != 0 {
+		t.Fatal("should not finish before unfreeze finished")
+	}
+
+	// 解冻处理中重复处理同一订单不会覆盖任务阶段
+	if task, err = o.HandleTransaction(ctx, order); err != nil || task.Stage != TaskStageUnfreezing {
+		t.Fatalf("should resume unfreezing task: %v, %+v", err, task)
+	}
+
+	client.orders[UnfreezeOutOrderNo(order.TransactionID)].State = OrderStateFinished
+	o.poller.Poll()
+	if len(finished) != 1 || finished[0].Order == nil || !finished[0].Order.IsFinished() {
+		t.Fatalf("unexpected finished tasks: %+v", finished)
+	}
+
+	// 已完成的任务保留在存储中, 重复的支付通知不会再次回调
+	if task, err = o.HandleTransaction(ctx, order); err != nil || !task.Done || len(finished) != 1 || len(failed) != 1 {
+		t.Errorf("finished task should not be handled again: %v, %+v", err, task)
+	}
+
+	// 未支付订单不能分账
+	order.TradeState = vwxpayments.TradeStateNotPay
+	if _, err := o.HandleTransaction(ctx, order); err == nil {
+		t.Error("expected error for unpaid order")
+	}
+}
+
+func TestOrchestratorRedelivery(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeSharingClient{orders: map[string]*Order{}}
+
+	var finished int
+	o := newOrchestrator(client, vrun.New(), StaticRules(nil), func(context.Context, *SharingTask) {
+		finished++
+	}, WithSharingTaskRetention(time.Millisecond))
+
+	order := &vwxpayservice.Order{
+		OutTradeNo:    "T002",
+		TransactionID: "4200000000000000000000000002",
+		TradeState:    vwxpayments.TradeStateSuccess,
+		Amount:        vwxmoney.Fen(1000),
+	}
+
+	// 无分账接收方且无剩余待分金额时直接完成, 重复通知不再回调
+	for i := 0; i < 2; i++ {
+		if _, err := o.HandleTransaction(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if finished != 1 || len(client.unfrozen) != 0 {
+		t.Fatalf("finish handler should fire once: %d, %d", finished, len(client.unfrozen))
+	}
+
+	// 保留期满后删除已完成的任务
+	time.Sleep(2 * time.Millisecond)
+	o.poller.Poll()
+	if tasks, _ := o.poller.Store.List(ctx); len(tasks) != 0 {
+		t.Errorf("expired task should be removed: %+v", tasks)
+	}
+}
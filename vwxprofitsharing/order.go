@@ -0,0 +1,171 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/vogo/vogo/vlog"
+)
+
+// CreateOrderRequest 请求分账
+type CreateOrderRequest struct {
+	SubMchID        string           `json:"sub_mchid,omitempty"` // 子商户号, 服务商模式下使用
+	AppID           string           `json:"appid"`               // 应用ID, 为空时使用配置中的默认AppID
+	SubAppID        string           `json:"sub_appid,omitempty"` // 子商户应用ID, 分账接收方类型为 PERSONAL_SUB_OPENID 时必填
+	TransactionID   string           `json:"transaction_id"`      // 微信支付订单号
+	OutOrderNo      string           `json:"out_order_no"`        // 商户分账单号
+	Receivers       []*OrderReceiver `json:"receivers"`           // 分账接收方列表
+	UnfreezeUnsplit bool             `json:"unfreeze_unsplit"`    // 是否解冻剩余未分资金
+}
+
+// Validate 校验请求分账参数
+func (r *CreateOrderRequest) Validate() error {
+	if r.TransactionID == "" {
+		return fmt.Errorf("transaction_id is empty")
+	}
+
+	if r.OutOrderNo == "" {
+		return fmt.Errorf("out_order_no is empty")
+	}
+
+	if len(r.Receivers) == 0 {
+		return fmt.Errorf("receivers is empty")
+	}
+
+	if len(r.Receivers) > MaxReceivers {
+		return fmt.Errorf("receivers exceeds %d: %d", MaxReceivers, len(r.Receivers))
+	}
+
+	for _, receiver := range r.Receivers {
+		if receiver.Account == "" {
+			return fmt.Errorf("account of receiver is empty")
+		}
+		if receiver.Type == ReceiverTypePersonalSubOpenID && r.SubAppID == "" {
+			return fmt.Errorf("sub_appid is required for receiver type %s", receiver.Type)
+		}
+		if !receiver.Amount.IsPositive() {
+			return fmt.Errorf("amount of receiver %s must be greater than 0: %s", receiver.Account, receiver.Amount)
+		}
+		if !validDescription(receiver.Description) {
+			return fmt.Errorf("description of receiver %s must be 1-%d characters", receiver.Account, MaxDescriptionChars)
+		}
+	}
+
+	return nil
+}
+
+// CreateOrder 请求分账
+// 分账接收方姓名传明文, 请求时自动使用平台证书加密.
+// 分账为异步处理, 返回的分账单状态多为 PROCESSING, 需调用 QueryOrder 查询或等待分账动账通知.
+func (c *ProfitSharingClient) CreateOrder(ctx context.Context, req *CreateOrderRequest) (*Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	body := *req
+	if body.AppID == "" {
+		body.AppID = c.mgr.Config.AppID
+	}
+
+	encrypted, header, err := c.mgr.PlatManager.EncryptRequest(&body)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt profit sharing request error: %w", err)
+	}
+
+	vlog.Infof("create profit sharing order | sub_mchid: %s | transaction_id: %s | out_order_no: %s | receivers: %d",
+		req.SubMchID, req.TransactionID, req.OutOrderNo, len(req.Receivers))
+
+	var order Order
+	if err := c.request(ctx, http.MethodPost, "/orders", header, nil, encrypted, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// QueryOrder 查询分账结果
+// subMchID: 子商户号, 服务商模式下使用
+// transactionID: 微信支付订单号
+// outOrderNo: 商户分账单号
+func (c *ProfitSharingClient) QueryOrder(ctx context.Context, subMchID, transactionID, outOrderNo string) (*Order, error) {
+	if transactionID == "" || outOrderNo == "" {
+		return nil, fmt.Errorf("transaction_id and out_order_no are required")
+	}
+
+	query := url.Values{}
+	query.Set("transaction_id", transactionID)
+	if subMchID != "" {
+		query.Set("sub_mchid", subMchID)
+	}
+
+	vlog.Infof("query profit sharing order | sub_mchid: %s | transaction_id: %s | out_order_no: %s", subMchID, transactionID, outOrderNo)
+
+	var order Order
+	if err := c.request(ctx, http.MethodGet, "/orders/"+url.PathEscape(outOrderNo), nil, query, nil, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// UnfreezeOrderRequest 解冻剩余资金
+type UnfreezeOrderRequest struct {
+	SubMchID      string `json:"sub_mchid,omitempty"` // 子商户号, 服务商模式下使用
+	TransactionID string `json:"transaction_id"`      // 微信支付订单号
+	OutOrderNo    string `json:"out_order_no"`        // 商户分账单号, 解冻操作也需使用新的单号
+	Description   string `json:"description"`         // 解冻原因
+}
+
+// Validate 校验解冻剩余资金参数
+func (r *UnfreezeOrderRequest) Validate() error {
+	if r.TransactionID == "" {
+		return fmt.Errorf("transaction_id is empty")
+	}
+
+	if r.OutOrderNo == "" {
+		return fmt.Errorf("out_order_no is empty")
+	}
+
+	if !validDescription(r.Description) {
+		return fmt.Errorf("description must be 1-%d characters", MaxDescriptionChars)
+	}
+
+	return nil
+}
+
+// UnfreezeOrder 解冻剩余资金
+// 不需要继续分账时, 将订单中剩余待分金额全部解冻给特约商户或直连商户
+func (c *ProfitSharingClient) UnfreezeOrder(ctx context.Context, req *UnfreezeOrderRequest) (*Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	vlog.Infof("unfreeze profit sharing order | sub_mchid: %s | transaction_id: %s | out_order_no: %s",
+		req.SubMchID, req.TransactionID, req.OutOrderNo)
+
+	var order Order
+	if err := c.request(ctx, http.MethodPost, "/orders/unfreeze", nil, nil, req, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
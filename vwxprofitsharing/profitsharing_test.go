@@ -0,0 +1,129 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+func TestCreateOrderRequestValidate(t *testing.T) {
+	receiver := func(typ ReceiverType, amount int64, description string) *OrderReceiver {
+		return &OrderReceiver{Type: typ, Account: "1900000109", Amount: vwxmoney.Fen(amount), Description: description}
+	}
+
+	tests := []struct {
+		name    string
+		req     CreateOrderRequest
+		wantErr bool
+	}{
+		{"valid", CreateOrderRequest{TransactionID: "4208450740201411110007820472", OutOrderNo: "P001", Receivers: []*OrderReceiver{receiver(ReceiverTypeMerchantID, 100, "分给商户")}}, false},
+		{"missing transaction id", CreateOrderRequest{OutOrderNo: "P001", Receivers: []*OrderReceiver{receiver(ReceiverTypeMerchantID, 100, "分给商户")}}, true},
+		{"no receivers", CreateOrderRequest{TransactionID: "4208450740201411110007820472", OutOrderNo: "P001"}, true},
+		{"zero amount", CreateOrderRequest{TransactionID: "4208450740201411110007820472", OutOrderNo: "P001", Receivers: []*OrderReceiver{receiver(ReceiverTypeMerchantID, 0, "分给商户")}}, true},
+		{"empty description", CreateOrderRequest{TransactionID: "4208450740201411110007820472", OutOrderNo: "P001", Receivers: []*OrderReceiver{receiver(ReceiverTypeMerchantID, 100, "")}}, true},
+		{"sub openid without sub appid", CreateOrderRequest{TransactionID: "4208450740201411110007820472", OutOrderNo: "P001", Receivers: []*OrderReceiver{receiver(ReceiverTypePersonalSubOpenID, 100, "分给用户")}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddReceiverRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     AddReceiverRequest
+		wantErr bool
+	}{
+		{"merchant", AddReceiverRequest{Type: ReceiverTypeMerchantID, Account: "1900000109", Name: "腾讯科技", RelationType: RelationTypeStore}, false},
+		{"merchant without name", AddReceiverRequest{Type: ReceiverTypeMerchantID, Account: "1900000109", RelationType: RelationTypeStore}, true},
+		{"openid", AddReceiverRequest{Type: ReceiverTypePersonalOpenID, Account: "oUpF8uMuAJO_M2pxb1Q9zNjWeS6o", RelationType: RelationTypeUser}, false},
+		{"custom without relation", AddReceiverRequest{Type: ReceiverTypePersonalOpenID, Account: "oUpF8uMuAJO_M2pxb1Q9zNjWeS6o", RelationType: RelationTypeCustom}, true},
+		{"invalid type", AddReceiverRequest{Type: "BANK", Account: "1900000109", RelationType: RelationTypeStore}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMerchantRatioMaxAmount(t *testing.T) {
+	ratio := &MerchantRatio{MaxRatio: 3000}
+	if got := ratio.MaxAmount(vwxmoney.Fen(999)); got != vwxmoney.Fen(299) {
+		t.Errorf("MaxAmount() = %s, want 2.99", got)
+	}
+}
+
+func TestParseNotifyBody(t *testing.T) {
+	const apiV3Key = "0123456789abcdef0123456789abcdef"
+
+	plaintext := `{"sp_mchid":"1900000100","sub_mchid":"1900000109","transaction_id":"4200000000000000000000000000","order_id":"3008450740201411110007820472","out_order_no":"P20150806125346","receiver":{"type":"MERCHANT_ID","account":"1900000110","amount":888,"description":"运费"},"success_time":"2018-06-08T10:34:56+08:00"}`
+
+	block, err := aes.NewCipher([]byte(apiV3Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, associatedData := "0123456789ab", "profitsharing"
+	ciphertext := gcm.Seal(nil, []byte(nonce), []byte(plaintext), []byte(associatedData))
+
+	body, _ := json.Marshal(map[string]any{
+		"id":         "EV-2018022511223320873",
+		"event_type": NotifyEventSuccess,
+		"resource": map[string]string{
+			"algorithm":       "AEAD_AES_256_GCM",
+			"ciphertext":      base64.StdEncoding.EncodeToString(ciphertext),
+			"nonce":           nonce,
+			"associated_data": associatedData,
+		},
+	})
+
+	req, n, err := vwxutils.ParseNotifyBody[ProfitSharingNotify](apiV3Key, "profit sharing", body)
+	if err != nil {
+		t.Fatalf("ParseNotifyBody() error = %v", err)
+	}
+
+	if req.EventType != NotifyEventSuccess {
+		t.Errorf("event type = %s", req.EventType)
+	}
+	if n.OutOrderNo != "P20150806125346" || n.Receiver == nil || n.Receiver.Amount != vwxmoney.Fen(888) {
+		t.Errorf("unexpected notify: %+v", n)
+	}
+
+	if _, _, err := vwxutils.ParseNotifyBody[ProfitSharingNotify]("fedcba9876543210fedcba9876543210", "profit sharing", body); err == nil {
+		t.Error("expected decrypt error with wrong key")
+	}
+}
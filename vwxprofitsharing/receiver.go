@@ -0,0 +1,137 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/vogo/vogo/vlog"
+)
+
+// AddReceiverRequest 添加分账接收方
+type AddReceiverRequest struct {
+	SubMchID       string       `json:"sub_mchid,omitempty"`                  // 子商户号, 服务商模式下使用
+	AppID          string       `json:"appid"`                                // 应用ID, 为空时使用配置中的默认AppID
+	SubAppID       string       `json:"sub_appid,omitempty"`                  // 子商户应用ID, 分账接收方类型为 PERSONAL_SUB_OPENID 时必填
+	Type           ReceiverType `json:"type"`                                 // 分账接收方类型
+	Account        string       `json:"account"`                              // 分账接收方账号
+	Name           string       `json:"name,omitempty" encryption:"EM_APIV3"` // 分账接收方全称, 类型为 MERCHANT_ID 时必填, 传明文, 请求时自动加密
+	RelationType   RelationType `json:"relation_type"`                        // 与分账方的关系类型
+	CustomRelation string       `json:"custom_relation,omitempty"`            // 自定义的分账关系, 关系类型为 CUSTOM 时必填
+}
+
+// Validate 校验添加分账接收方参数
+func (r *AddReceiverRequest) Validate() error {
+	switch r.Type {
+	case ReceiverTypeMerchantID:
+		if r.Name == "" {
+			return fmt.Errorf("name is required for receiver type %s", r.Type)
+		}
+	case ReceiverTypePersonalOpenID:
+	case ReceiverTypePersonalSubOpenID:
+		if r.SubAppID == "" {
+			return fmt.Errorf("sub_appid is required for receiver type %s", r.Type)
+		}
+	default:
+		return fmt.Errorf("invalid receiver type: %s", r.Type)
+	}
+
+	if r.Account == "" {
+		return fmt.Errorf("account is empty")
+	}
+
+	if r.RelationType == "" {
+		return fmt.Errorf("relation_type is empty")
+	}
+
+	if r.RelationType == RelationTypeCustom && r.CustomRelation == "" {
+		return fmt.Errorf("custom_relation is required for relation type %s", r.RelationType)
+	}
+
+	return nil
+}
+
+// Receiver 分账接收方
+type Receiver struct {
+	SubMchID       string       `json:"sub_mchid"`       // 子商户号
+	Type           ReceiverType `json:"type"`            // 分账接收方类型
+	Account        string       `json:"account"`         // 分账接收方账号
+	Name           string       `json:"name"`            // 分账接收方全称, 密文
+	RelationType   RelationType `json:"relation_type"`   // 与分账方的关系类型
+	CustomRelation string       `json:"custom_relation"` // 自定义的分账关系
+}
+
+// AddReceiver 添加分账接收方
+// 请求分账前需先添加分账接收方, 分账接收方姓名传明文, 请求时自动使用平台证书加密.
+func (c *ProfitSharingClient) AddReceiver(ctx context.Context, req *AddReceiverRequest) (*Receiver, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	body := *req
+	if body.AppID == "" {
+		body.AppID = c.mgr.Config.AppID
+	}
+
+	encrypted, header, err := c.mgr.PlatManager.EncryptRequest(&body)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt receiver request error: %w", err)
+	}
+
+	vlog.Infof("add profit sharing receiver | sub_mchid: %s | type: %s | account: %s | relation_type: %s",
+		req.SubMchID, req.Type, req.Account, req.RelationType)
+
+	var receiver Receiver
+	if err := c.request(ctx, http.MethodPost, "/receivers/add", header, nil, encrypted, &receiver); err != nil {
+		return nil, err
+	}
+
+	return &receiver, nil
+}
+
+// DeleteReceiverRequest 删除分账接收方
+type DeleteReceiverRequest struct {
+	SubMchID string       `json:"sub_mchid,omitempty"` // 子商户号, 服务商模式下使用
+	AppID    string       `json:"appid"`               // 应用ID, 为空时使用配置中的默认AppID
+	SubAppID string       `json:"sub_appid,omitempty"` // 子商户应用ID, 分账接收方类型为 PERSONAL_SUB_OPENID 时必填
+	Type     ReceiverType `json:"type"`                // 分账接收方类型
+	Account  string       `json:"account"`             // 分账接收方账号
+}
+
+// DeleteReceiver 删除分账接收方
+func (c *ProfitSharingClient) DeleteReceiver(ctx context.Context, req *DeleteReceiverRequest) (*Receiver, error) {
+	if req.Type == "" || req.Account == "" {
+		return nil, fmt.Errorf("type and account are required")
+	}
+
+	body := *req
+	if body.AppID == "" {
+		body.AppID = c.mgr.Config.AppID
+	}
+
+	vlog.Infof("delete profit sharing receiver | sub_mchid: %s | type: %s | account: %s", req.SubMchID, req.Type, req.Account)
+
+	var receiver Receiver
+	if err := c.request(ctx, http.MethodPost, "/receivers/delete", nil, nil, &body, &receiver); err != nil {
+		return nil, err
+	}
+
+	return &receiver, nil
+}
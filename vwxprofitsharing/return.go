@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+// CreateReturnOrderRequest 请求分账回退
+type CreateReturnOrderRequest struct {
+	SubMchID    string         `json:"sub_mchid,omitempty"`    // 子商户号, 服务商模式下使用
+	OrderID     string         `json:"order_id,omitempty"`     // 微信分账单号, 与商户分账单号二选一
+	OutOrderNo  string         `json:"out_order_no,omitempty"` // 商户分账单号, 与微信分账单号二选一
+	OutReturnNo string         `json:"out_return_no"`          // 商户回退单号
+	ReturnMchID string         `json:"return_mchid"`           // 回退商户号, 只能回退分账给商户的资金
+	Amount      vwxmoney.Money `json:"amount"`                 // 回退金额
+	Description string         `json:"description"`            // 回退描述
+}
+
+// Validate 校验分账回退参数
+func (r *CreateReturnOrderRequest) Validate() error {
+	if r.OrderID == "" && r.OutOrderNo == "" {
+		return fmt.Errorf("order_id or out_order_no is required")
+	}
+
+	if r.OutReturnNo == "" {
+		return fmt.Errorf("out_return_no is empty")
+	}
+
+	if r.ReturnMchID == "" {
+		return fmt.Errorf("return_mchid is empty")
+	}
+
+	if !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be greater than 0: %s", r.Amount)
+	}
+
+	if !validDescription(r.Description) {
+		return fmt.Errorf("description must be 1-%d characters", MaxDescriptionChars)
+	}
+
+	return nil
+}
+
+// CreateReturnOrder 请求分账回退
+// 订单已分账给商户的资金, 可在退款前回退给分账方. 回退结果为 PROCESSING 时需调用 QueryReturnOrder 查询.
+func (c *ProfitSharingClient) CreateReturnOrder(ctx context.Context, req *CreateReturnOrderRequest) (*ReturnOrder, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	vlog.Infof("create profit sharing return order | sub_mchid: %s | out_order_no: %s | out_return_no: %s | amount: %s",
+		req.SubMchID, req.OutOrderNo, req.OutReturnNo, req.Amount)
+
+	var order ReturnOrder
+	if err := c.request(ctx, http.MethodPost, "/return-orders", nil, nil, req, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// QueryReturnOrder 查询分账回退结果
+// subMchID: 子商户号, 服务商模式下使用
+// outOrderNo: 商户分账单号
+// outReturnNo: 商户回退单号
+func (c *ProfitSharingClient) QueryReturnOrder(ctx context.Context, subMchID, outOrderNo, outReturnNo string) (*ReturnOrder, error) {
+	if outOrderNo == "" || outReturnNo == "" {
+		return nil, fmt.Errorf("out_order_no and out_return_no are required")
+	}
+
+	query := url.Values{}
+	query.Set("out_order_no", outOrderNo)
+	if subMchID != "" {
+		query.Set("sub_mchid", subMchID)
+	}
+
+	vlog.Infof("query profit sharing return order | sub_mchid: %s | out_order_no: %s | out_return_no: %s", subMchID, outOrderNo, outReturnNo)
+
+	var order ReturnOrder
+	if err := c.request(ctx, http.MethodGet, "/return-orders/"+url.PathEscape(outReturnNo), nil, query, nil, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
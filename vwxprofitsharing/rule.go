@@ -0,0 +1,147 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxprofitsharing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+// ErrRulesExceedAmount 分账规则计算的分账总额超过订单金额
+var ErrRulesExceedAmount = errors.New("profit sharing rules exceed order amount")
+
+// RuleKind 分账规则类型
+type RuleKind string
+
+const (
+	RuleKindFixed      RuleKind = "FIXED"      // 固定金额
+	RuleKindPercentage RuleKind = "PERCENTAGE" // 按订单金额比例
+)
+
+// Rule 分账接收方规则
+type Rule struct {
+	Kind        RuleKind       `json:"kind"`           // 规则类型
+	Type        ReceiverType   `json:"type"`           // 分账接收方类型
+	Account     string         `json:"account"`        // 分账接收方账号
+	Name        string         `json:"name,omitempty"` // 分账个人接收方姓名, 可选
+	Description string         `json:"description"`    // 分账描述
+	Amount      vwxmoney.Money `json:"amount"`         // 固定分账金额, 规则类型为 FIXED 时使用
+	Ratio       int64          `json:"ratio"`          // 分账比例, 单位万分比, 规则类型为 PERCENTAGE 时使用
+}
+
+// RuleSet 分账规则集合
+type RuleSet struct {
+	Rules         []*Rule `json:"rules"`            // 分账接收方规则, 按顺序计算
+	CapByMaxRatio bool    `json:"cap_by_max_ratio"` // 是否按子商户允许的最大分账比例限制分账总额, 仅服务商模式
+}
+
+// Validate 校验分账规则
+func (s *RuleSet) Validate() error {
+	if len(s.Rules) > MaxReceivers {
+		return fmt.Errorf("rules exceeds %d: %d", MaxReceivers, len(s.Rules))
+	}
+
+	for _, rule := range s.Rules {
+		if rule.Account == "" {
+			return fmt.Errorf("account of rule is empty")
+		}
+
+		if !validDescription(rule.Description) {
+			return fmt.Errorf("description of rule %s must be 1-%d characters", rule.Account, MaxDescriptionChars)
+		}
+
+		switch rule.Kind {
+		case RuleKindFixed:
+			if rule.Amount.IsNegative() {
+				return fmt.Errorf("amount of rule %s is negative: %s", rule.Account, rule.Amount)
+			}
+		case RuleKindPercentage:
+			if rule.Ratio < 0 || rule.Ratio > MaxRatioBase {
+				return fmt.Errorf("ratio of rule %s must be 0-%d: %d", rule.Account, MaxRatioBase, rule.Ratio)
+			}
+		default:
+			return fmt.Errorf("invalid kind of rule %s: %s", rule.Account, rule.Kind)
+		}
+	}
+
+	return nil
+}
+
+// Calculate 按规则计算各分账接收方的分账金额
+// 按比例分账的金额向下取整到分, 分账总额不能超过订单金额.
+// maxRatio 为允许的最大分账比例(万分比), 大于0时分账总额超过上限的部分按各接收方金额比例扣减, 扣减后总额恰好等于上限.
+// 分账金额为0的接收方不会出现在结果中.
+func (s *RuleSet) Calculate(total vwxmoney.Money, maxRatio int64) ([]*OrderReceiver, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	shares := make([]vwxmoney.Money, len(s.Rules))
+	for i, rule := range s.Rules {
+		switch rule.Kind {
+		case RuleKindFixed:
+			if !rule.Amount.IsZero() && !rule.Amount.SameCurrency(total) {
+				return nil, fmt.Errorf("%w: rule %s", vwxmoney.ErrCurrencyMismatch, rule.Account)
+			}
+			shares[i] = vwxmoney.New(rule.Amount.Fen(), total.Currency())
+		case RuleKindPercentage:
+			shares[i] = vwxmoney.New(total.Fen()*rule.Ratio/MaxRatioBase, total.Currency())
+		}
+	}
+
+	sum, err := vwxmoney.Sum(shares...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c, _ := sum.Cmp(total); c > 0 {
+		return nil, fmt.Errorf("%w: %s > %s", ErrRulesExceedAmount, sum, total)
+	}
+
+	if maxRatio > 0 {
+		limit := (&MerchantRatio{MaxRatio: maxRatio}).MaxAmount(total)
+		if c, _ := sum.Cmp(limit); c > 0 {
+			ratios := make([]int64, len(shares))
+			for i, share := range shares {
+				ratios[i] = share.Fen()
+			}
+			if shares, err = limit.Allocate(ratios...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	receivers := make([]*OrderReceiver, 0, len(shares))
+	for i, rule := range s.Rules {
+		if !shares[i].IsPositive() {
+			continue
+		}
+
+		receivers = append(receivers, &OrderReceiver{
+			Type:        rule.Type,
+			Account:     rule.Account,
+			Name:        rule.Name,
+			Amount:      shares[i],
+			Description: rule.Description,
+		})
+	}
+
+	return receivers, nil
+}
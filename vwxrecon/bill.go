@@ -0,0 +1,124 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrecon
+
+import (
+	"strings"
+
+	"github.com/vogo/vwechatpay/vwxbill"
+)
+
+// TradeBillRecords 将交易账单明细转换为对账记录
+// 支付成功的明细转换为支付记录(订单金额), 退款明细转换为退款记录(申请退款金额), 其他状态的明细忽略.
+func TradeBillRecords(reader *vwxbill.TradeBillReader, fn func(*Record) error) error {
+	for reader.Next() {
+		if record := tradeBillRecord(reader.Row()); record != nil {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	return reader.Err()
+}
+
+func tradeBillRecord(row *vwxbill.TradeBillRow) *Record {
+	mchID := row.SubMchID
+	if mchID == "" || mchID == "0" {
+		mchID = row.MchID
+	}
+
+	if row.IsRefund() {
+		amount := row.RefundApplyAmount
+		if amount.IsZero() {
+			amount = row.RefundAmount
+		}
+
+		refundTime := row.RefundSuccessTime
+		if refundTime.IsZero() {
+			refundTime = row.TradeTime
+		}
+
+		return &Record{
+			Type:          RecordTypeRefund,
+			MchID:         mchID,
+			TradeNo:       row.OutRefundNo,
+			TransactionID: row.RefundID,
+			Amount:        amount,
+			Time:          refundTime,
+		}
+	}
+
+	if row.TradeState != "SUCCESS" {
+		return nil
+	}
+
+	amount := row.OrderAmount
+	if amount.IsZero() {
+		amount = row.SettlementTotal
+	}
+
+	return &Record{
+		Type:          RecordTypePayment,
+		MchID:         mchID,
+		TradeNo:       row.OutTradeNo,
+		TransactionID: row.TransactionID,
+		Amount:        amount,
+		Time:          row.TradeTime,
+	}
+}
+
+// TransferMatcher 判断资金账单明细是否为商家转账
+type TransferMatcher func(record *vwxbill.FundFlowRecord) bool
+
+// DefaultTransferMatcher 业务名称或业务类型包含"转账"的支出明细视为商家转账
+func DefaultTransferMatcher(record *vwxbill.FundFlowRecord) bool {
+	return record.FlowType == vwxbill.FundFlowExpense &&
+		(strings.Contains(record.BizName, "转账") || strings.Contains(record.BizType, "转账"))
+}
+
+// FundFlowTransferRecords 将资金账单中的商家转账明细转换为对账记录, 业务凭证号为商户转账单号
+// mchID: 资金账单所属商户号, 资金账单中不包含商户号
+// matcher: 商家转账明细判断, 为nil时使用 DefaultTransferMatcher
+func FundFlowTransferRecords(reader *vwxbill.FundFlowBillReader, mchID string, matcher TransferMatcher, fn func(*Record) error) error {
+	if matcher == nil {
+		matcher = DefaultTransferMatcher
+	}
+
+	for reader.Next() {
+		flow := reader.Record()
+		if !matcher(flow) {
+			continue
+		}
+
+		record := &Record{
+			Type:          RecordTypeTransfer,
+			MchID:         mchID,
+			TradeNo:       flow.BizVoucherID,
+			TransactionID: flow.TransactionID,
+			Amount:        flow.Amount,
+			Time:          flow.AccountingTime,
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return reader.Err()
+}
@@ -0,0 +1,122 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrecon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/vogo/vwechatpay/vwxbill"
+)
+
+// Reconciler 对账器
+// 先加载微信支付账单记录, 再逐条比对本地记录, 本地记录以流的方式读取, 账单记录保存在内存中.
+type Reconciler struct {
+	remote map[string]*Record
+}
+
+// NewReconciler 创建对账器
+func NewReconciler() *Reconciler {
+	return &Reconciler{
+		remote: make(map[string]*Record),
+	}
+}
+
+// AddRemote 添加微信支付账单记录, 相同标识的记录金额累加, 货币类型不一致时返回错误
+func (r *Reconciler) AddRemote(record *Record) error {
+	if record.TradeNo == "" {
+		return fmt.Errorf("trade no of %s record is empty", record.Type)
+	}
+
+	key := record.Key()
+	if existing, ok := r.remote[key]; ok {
+		sum, err := existing.Amount.Add(record.Amount)
+		if err != nil {
+			return fmt.Errorf("add amount of %s record %s error: %w", record.Type, record.TradeNo, err)
+		}
+		existing.Amount = sum
+		return nil
+	}
+
+	rec := *record
+	r.remote[key] = &rec
+
+	return nil
+}
+
+// AddTradeBill 添加交易账单中的支付及退款记录
+func (r *Reconciler) AddTradeBill(reader *vwxbill.TradeBillReader) error {
+	return TradeBillRecords(reader, r.AddRemote)
+}
+
+// AddFundFlowBill 添加资金账单中的商家转账记录
+// mchID: 资金账单所属商户号
+// matcher: 商家转账明细判断, 为nil时使用 DefaultTransferMatcher
+func (r *Reconciler) AddFundFlowBill(reader *vwxbill.FundFlowBillReader, mchID string, matcher TransferMatcher) error {
+	return FundFlowTransferRecords(reader, mchID, matcher, r.AddRemote)
+}
+
+// Reconcile 比对本地记录与账单记录, 生成对账报告
+// 可重复调用, 每次调用独立比对已加载的全部账单记录.
+func (r *Reconciler) Reconcile(ctx context.Context, local LocalSource) (*Report, error) {
+	report := newReport()
+	seen := make(map[string]bool, len(r.remote))
+
+	err := local.EachRecord(ctx, func(record *Record) error {
+		key := record.Key()
+		if seen[key] {
+			return fmt.Errorf("duplicate local record: %s", key)
+		}
+		seen[key] = true
+
+		remote, ok := r.remote[key]
+		if !ok {
+			return report.add(newResult(ResultMissingRemote, record, nil))
+		}
+
+		cmp, err := record.Amount.Cmp(remote.Amount)
+		if err != nil || cmp != 0 {
+			return report.add(newResult(ResultAmountMismatch, record, remote))
+		}
+
+		return report.add(newResult(ResultMatched, record, remote))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reconcile local records error: %w", err)
+	}
+
+	// 账单中存在而本地缺失的记录, 按标识排序保证报告稳定
+	var keys []string
+	for key := range r.remote {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := report.add(newResult(ResultMissingLocal, nil, r.remote[key])); err != nil {
+			return nil, err
+		}
+	}
+
+	report.finish()
+
+	return report, nil
+}
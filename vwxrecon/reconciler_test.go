@@ -0,0 +1,137 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrecon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxbill"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const testTradeBill = "交易时间,公众账号ID,商户号,特约商户号,设备号,微信订单号,商户订单号,用户标识,交易类型,交易状态,付款银行,货币种类,应结订单金额,代金券金额,微信退款单号,商户退款单号,退款金额,充值券退款金额,退款类型,退款状态,商品名称,商户数据包,手续费,费率,订单金额,申请退款金额,费率备注\n" +
+	"`2019-06-11 10:00:00,`wx01,`1900000001,`0,`,`4201,`T001,`o1,`JSAPI,`SUCCESS,`OTHERS,`CNY,`10.00,`0.00,`0,`0,`0.00,`0.00,`,`,`A,`,`0.06000,`0.60%,`10.00,`0.00,`\n" +
+	"`2019-06-11 11:00:00,`wx01,`1900000001,`0,`,`4202,`T002,`o1,`JSAPI,`SUCCESS,`OTHERS,`CNY,`20.00,`0.00,`0,`0,`0.00,`0.00,`,`,`B,`,`0.12000,`0.60%,`20.00,`0.00,`\n" +
+	"`2019-06-11 12:00:00,`wx01,`1900000001,`0,`,`4203,`T003,`o1,`JSAPI,`SUCCESS,`OTHERS,`CNY,`30.00,`0.00,`0,`0,`0.00,`0.00,`,`,`C,`,`0.18000,`0.60%,`30.00,`0.00,`\n" +
+	"`2019-06-11 13:00:00,`wx01,`1900000001,`0,`,`4201,`T001,`o1,`JSAPI,`REFUND,`OTHERS,`CNY,`0.00,`0.00,`5001,`R001,`5.00,`0.00,`ORIGINAL,`SUCCESS,`A,`,`-0.03000,`0.60%,`0.00,`5.00,`\n" +
+	"总交易单数,应结订单总金额,退款总金额,充值券退款总金额,手续费总金额,订单总金额,申请退款总金额\n" +
+	"`4,`60.00,`5.00,`0.00,`0.33000,`60.00,`5.00\n"
+
+func TestReconcile(t *testing.T) {
+	reader, err := vwxbill.NewTradeBillReader(strings.NewReader(testTradeBill))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReconciler()
+	if err := r.AddTradeBill(reader); err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2019, 6, 11, 10, 0, 0, 0, vwxutils.ChinaLocation)
+	local := SliceSource{
+		{Type: RecordTypePayment, MchID: "1900000001", TradeNo: "T001", Amount: vwxmoney.Fen(1000), Time: day},
+		{Type: RecordTypePayment, MchID: "1900000001", TradeNo: "T002", Amount: vwxmoney.Fen(1999), Time: day},
+		{Type: RecordTypePayment, MchID: "1900000001", TradeNo: "T004", Amount: vwxmoney.Fen(4000), Time: day},
+		{Type: RecordTypeRefund, MchID: "1900000001", TradeNo: "R001", Amount: vwxmoney.Fen(500), Time: day},
+	}
+
+	report, err := r.Reconcile(context.Background(), local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]ResultStatus{}
+	for _, result := range report.Results {
+		statuses[result.TradeNo] = result.Status
+	}
+
+	expected := map[string]ResultStatus{
+		"T001": ResultMatched,
+		"T002": ResultAmountMismatch,
+		"T003": ResultMissingLocal,
+		"T004": ResultMissingRemote,
+		"R001": ResultMatched,
+	}
+	for no, status := range expected {
+		if statuses[no] != status {
+			t.Errorf("%s status = %s, want %s", no, statuses[no], status)
+		}
+	}
+
+	if !report.HasDifference() || len(report.Filter(ResultMissingLocal)) != 1 {
+		t.Errorf("unexpected report: %+v", report.Results)
+	}
+
+	if len(report.Totals) != 2 {
+		t.Fatalf("totals = %d", len(report.Totals))
+	}
+
+	payment := report.Totals[0]
+	if payment.Day != "2019-06-11" || payment.Type != RecordTypePayment || payment.MatchedCount != 1 ||
+		payment.MismatchCount != 1 || payment.MissingLocalCount != 1 || payment.MissingRemoteCount != 1 ||
+		payment.LocalAmount.Fen() != 6999 || payment.RemoteAmount.Fen() != 6000 {
+		t.Errorf("unexpected payment total: %+v", payment)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteTotalsCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "2019-06-11,1900000001,PAYMENT,1,1,1,1,69.99,60.00") {
+		t.Errorf("unexpected totals csv: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Results) != 5 {
+		t.Errorf("unexpected json report: %v", err)
+	}
+
+	if _, err := r.Reconcile(context.Background(), SliceSource{local[0], local[0]}); err == nil {
+		t.Errorf("expected error on duplicate local record")
+	}
+}
+
+func TestAddRemoteCurrencyMismatch(t *testing.T) {
+	r := NewReconciler()
+	record := &Record{Type: RecordTypePayment, MchID: "1900000001", TradeNo: "T001", Amount: vwxmoney.Fen(1000)}
+	if err := r.AddRemote(record); err != nil {
+		t.Fatal(err)
+	}
+
+	usd := *record
+	usd.Amount = vwxmoney.New(100, "USD")
+	if err := r.AddRemote(&usd); !errors.Is(err, vwxmoney.ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch: %v", err)
+	}
+
+	if r.remote[record.Key()].Amount.Fen() != 1000 {
+		t.Errorf("amount should not change: %s", r.remote[record.Key()].Amount)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package vwxrecon 对账, 比对本地订单、退款、转账记录与微信支付账单.
+package vwxrecon
+
+import (
+	"context"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const dayLayout = "2006-01-02"
+
+// RecordType 记录类型
+type RecordType string
+
+const (
+	RecordTypePayment  RecordType = "PAYMENT"  // 支付
+	RecordTypeRefund   RecordType = "REFUND"   // 退款
+	RecordTypeTransfer RecordType = "TRANSFER" // 商家转账
+)
+
+// Record 参与对账的记录, 本地记录和账单记录统一转换为该结构
+type Record struct {
+	Type          RecordType     `json:"type"`           // 记录类型
+	MchID         string         `json:"mchid"`          // 商户号, 服务商模式下为子商户号
+	TradeNo       string         `json:"trade_no"`       // 商户单号: 支付为商户订单号, 退款为商户退款单号, 转账为商户转账单号
+	TransactionID string         `json:"transaction_id"` // 微信支付单号
+	Amount        vwxmoney.Money `json:"amount"`         // 金额
+	Time          time.Time      `json:"time"`           // 交易时间, 用于按天汇总
+}
+
+// Key 对账使用的唯一标识
+func (r *Record) Key() string {
+	return string(r.Type) + "|" + r.MchID + "|" + r.TradeNo
+}
+
+// Day 交易日期(北京时间)
+func (r *Record) Day() string {
+	if r.Time.IsZero() {
+		return ""
+	}
+	return r.Time.In(vwxutils.ChinaLocation).Format(dayLayout)
+}
+
+// LocalSource 本地记录来源, 由业务实现, 如分页查询数据库中指定日期的订单、退款、转账记录
+type LocalSource interface {
+	// EachRecord 逐条输出本地记录, fn 返回错误时停止
+	EachRecord(ctx context.Context, fn func(*Record) error) error
+}
+
+// LocalSourceFunc 函数形式的本地记录来源
+type LocalSourceFunc func(ctx context.Context, fn func(*Record) error) error
+
+func (f LocalSourceFunc) EachRecord(ctx context.Context, fn func(*Record) error) error {
+	return f(ctx, fn)
+}
+
+// SliceSource 基于切片的本地记录来源
+type SliceSource []*Record
+
+func (s SliceSource) EachRecord(ctx context.Context, fn func(*Record) error) error {
+	for _, record := range s {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
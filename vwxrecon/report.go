@@ -0,0 +1,252 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrecon
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+// ResultStatus 对账结果
+type ResultStatus string
+
+const (
+	ResultMatched        ResultStatus = "MATCHED"         // 一致
+	ResultMissingLocal   ResultStatus = "MISSING_LOCAL"   // 本地缺失, 账单中存在
+	ResultMissingRemote  ResultStatus = "MISSING_REMOTE"  // 账单缺失, 本地存在
+	ResultAmountMismatch ResultStatus = "AMOUNT_MISMATCH" // 金额不一致
+)
+
+// Result 单条记录的对账结果
+type Result struct {
+	Status        ResultStatus   `json:"status"`         // 对账结果
+	Type          RecordType     `json:"type"`           // 记录类型
+	Day           string         `json:"day"`            // 交易日期
+	MchID         string         `json:"mchid"`          // 商户号
+	TradeNo       string         `json:"trade_no"`       // 商户单号
+	TransactionID string         `json:"transaction_id"` // 微信支付单号
+	LocalAmount   vwxmoney.Money `json:"local_amount"`   // 本地金额
+	RemoteAmount  vwxmoney.Money `json:"remote_amount"`  // 账单金额
+}
+
+func newResult(status ResultStatus, local, remote *Record) *Result {
+	base := local
+	if base == nil {
+		base = remote
+	}
+
+	result := &Result{
+		Status:        status,
+		Type:          base.Type,
+		Day:           base.Day(),
+		MchID:         base.MchID,
+		TradeNo:       base.TradeNo,
+		TransactionID: base.TransactionID,
+	}
+
+	if local != nil {
+		result.LocalAmount = local.Amount
+	}
+
+	if remote != nil {
+		result.RemoteAmount = remote.Amount
+		if result.TransactionID == "" {
+			result.TransactionID = remote.TransactionID
+		}
+		// 以账单中的交易时间为准
+		if day := remote.Day(); day != "" {
+			result.Day = day
+		}
+	}
+
+	return result
+}
+
+// Total 按日期、商户及记录类型汇总的对账结果
+type Total struct {
+	Day                string         `json:"day"`                  // 交易日期
+	MchID              string         `json:"mchid"`                // 商户号
+	Type               RecordType     `json:"type"`                 // 记录类型
+	MatchedCount       int64          `json:"matched_count"`        // 一致笔数
+	MissingLocalCount  int64          `json:"missing_local_count"`  // 本地缺失笔数
+	MissingRemoteCount int64          `json:"missing_remote_count"` // 账单缺失笔数
+	MismatchCount      int64          `json:"mismatch_count"`       // 金额不一致笔数
+	LocalAmount        vwxmoney.Money `json:"local_amount"`         // 本地总金额
+	RemoteAmount       vwxmoney.Money `json:"remote_amount"`        // 账单总金额
+
+	hasLocal, hasRemote bool // 是否已累加过金额, 首笔金额决定汇总的货币类型
+}
+
+// Report 对账报告
+type Report struct {
+	Results []*Result `json:"results"` // 全部对账结果
+	Totals  []*Total  `json:"totals"`  // 按日期、商户及记录类型的汇总
+
+	totals map[string]*Total
+}
+
+func newReport() *Report {
+	return &Report{
+		totals: make(map[string]*Total),
+	}
+}
+
+// add 添加对账结果并累加汇总金额, 同一汇总中货币类型不一致时返回错误
+func (r *Report) add(result *Result) error {
+	r.Results = append(r.Results, result)
+
+	key := result.Day + "|" + result.MchID + "|" + string(result.Type)
+	total, ok := r.totals[key]
+	if !ok {
+		total = &Total{Day: result.Day, MchID: result.MchID, Type: result.Type}
+		r.totals[key] = total
+	}
+
+	switch result.Status {
+	case ResultMatched:
+		total.MatchedCount++
+	case ResultMissingLocal:
+		total.MissingLocalCount++
+	case ResultMissingRemote:
+		total.MissingRemoteCount++
+	case ResultAmountMismatch:
+		total.MismatchCount++
+	}
+
+	var err error
+	if result.Status != ResultMissingLocal {
+		if total.LocalAmount, err = sumAmount(total.LocalAmount, result.LocalAmount, total.hasLocal); err != nil {
+			return fmt.Errorf("sum local amount of %s %s error: %w", result.Type, result.TradeNo, err)
+		}
+		total.hasLocal = true
+	}
+
+	if result.Status != ResultMissingRemote {
+		if total.RemoteAmount, err = sumAmount(total.RemoteAmount, result.RemoteAmount, total.hasRemote); err != nil {
+			return fmt.Errorf("sum remote amount of %s %s error: %w", result.Type, result.TradeNo, err)
+		}
+		total.hasRemote = true
+	}
+
+	return nil
+}
+
+// sumAmount 累加汇总金额, 尚未累加过时以首笔金额为初始值
+func sumAmount(total, amount vwxmoney.Money, started bool) (vwxmoney.Money, error) {
+	if !started {
+		return amount, nil
+	}
+	return total.Add(amount)
+}
+
+func (r *Report) finish() {
+	r.Totals = make([]*Total, 0, len(r.totals))
+	for _, total := range r.totals {
+		r.Totals = append(r.Totals, total)
+	}
+
+	sort.Slice(r.Totals, func(i, j int) bool {
+		a, b := r.Totals[i], r.Totals[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.MchID != b.MchID {
+			return a.MchID < b.MchID
+		}
+		return a.Type < b.Type
+	})
+}
+
+// Filter 按对账结果筛选
+func (r *Report) Filter(status ResultStatus) []*Result {
+	var list []*Result
+	for _, result := range r.Results {
+		if result.Status == status {
+			list = append(list, result)
+		}
+	}
+	return list
+}
+
+// HasDifference 是否存在差异
+func (r *Report) HasDifference() bool {
+	for _, result := range r.Results {
+		if result.Status != ResultMatched {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON 以JSON格式输出对账报告, 金额单位为分
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteResultsCSV 以CSV格式输出对账明细, 金额单位为元
+func (r *Report) WriteResultsCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	_ = writer.Write([]string{"对账结果", "记录类型", "交易日期", "商户号", "商户单号", "微信支付单号", "本地金额", "账单金额"})
+	for _, result := range r.Results {
+		_ = writer.Write([]string{
+			string(result.Status),
+			string(result.Type),
+			result.Day,
+			result.MchID,
+			result.TradeNo,
+			result.TransactionID,
+			result.LocalAmount.Yuan(),
+			result.RemoteAmount.Yuan(),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteTotalsCSV 以CSV格式输出对账汇总, 金额单位为元
+func (r *Report) WriteTotalsCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	_ = writer.Write([]string{"交易日期", "商户号", "记录类型", "一致笔数", "本地缺失笔数", "账单缺失笔数", "金额不一致笔数", "本地总金额", "账单总金额"})
+	for _, total := range r.Totals {
+		_ = writer.Write([]string{
+			total.Day,
+			total.MchID,
+			string(total.Type),
+			strconv.FormatInt(total.MatchedCount, 10),
+			strconv.FormatInt(total.MissingLocalCount, 10),
+			strconv.FormatInt(total.MissingRemoteCount, 10),
+			strconv.FormatInt(total.MismatchCount, 10),
+			total.LocalAmount.Yuan(),
+			total.RemoteAmount.Yuan(),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrefund
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+// ApplyAbnormalRefundURL 发起异常退款URL
+const ApplyAbnormalRefundURL = "https://api.mch.weixin.qq.com/v3/refund/domestic/refunds/%s/apply-abnormal-refund"
+
+// AbnormalRefundType 异常退款处理方式
+type AbnormalRefundType string
+
+const (
+	AbnormalRefundTypeUserBankCard     AbnormalRefundType = "USER_BANK_CARD"     // 退款到用户银行卡
+	AbnormalRefundTypeMerchantBankCard AbnormalRefundType = "MERCHANT_BANK_CARD" // 退款至交易商户银行账户
+)
+
+// AbnormalRefundRequest 发起异常退款请求
+type AbnormalRefundRequest struct {
+	RefundID    string             `json:"-"`                                            // 微信支付退款单号
+	SubMchID    string             `json:"sub_mchid,omitempty"`                          // 子商户号, 服务商模式下使用
+	OutRefundNo string             `json:"out_refund_no"`                                // 商户退款单号
+	Type        AbnormalRefundType `json:"type"`                                         // 异常退款处理方式
+	BankType    string             `json:"bank_type,omitempty"`                          // 开户银行, 退款到用户银行卡时必填, 如 ICBC_DEBIT
+	BankAccount string             `json:"bank_account,omitempty" encryption:"EM_APIV3"` // 收款银行卡号, 退款到用户银行卡时必填, 传明文, 请求时自动加密
+	RealName    string             `json:"real_name,omitempty" encryption:"EM_APIV3"`    // 收款用户姓名, 退款到用户银行卡时必填, 传明文, 请求时自动加密
+}
+
+// Validate 校验异常退款请求
+func (r *AbnormalRefundRequest) Validate() error {
+	if r.RefundID == "" {
+		return fmt.Errorf("refund_id is empty")
+	}
+
+	if r.OutRefundNo == "" {
+		return fmt.Errorf("out_refund_no is empty")
+	}
+
+	switch r.Type {
+	case AbnormalRefundTypeUserBankCard:
+		if r.BankType == "" || r.BankAccount == "" || r.RealName == "" {
+			return fmt.Errorf("bank_type, bank_account and real_name are required for %s", r.Type)
+		}
+	case AbnormalRefundTypeMerchantBankCard:
+	default:
+		return fmt.Errorf("invalid abnormal refund type: %s", r.Type)
+	}
+
+	return nil
+}
+
+// ApplyAbnormalRefund 发起异常退款
+// 退款状态为 ABNORMAL(如用户银行卡已注销或冻结)时, 可将退款转至用户其他银行卡或商户银行账户.
+// 银行卡号和姓名使用平台证书加密, 请求时通过 Wechatpay-Serial 头传递证书序列号, 请求参数中传明文即可.
+func (c *RefundClient) ApplyAbnormalRefund(ctx context.Context, req *AbnormalRefundRequest) (*refunddomestic.Refund, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	vlog.Infof("apply abnormal refund | refund_id: %s | out_refund_no: %s | type: %s", req.RefundID, req.OutRefundNo, req.Type)
+
+	body, header, err := c.mgr.PlatManager.EncryptRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt abnormal refund request error: %w", err)
+	}
+
+	url := fmt.Sprintf(ApplyAbnormalRefundURL, req.RefundID)
+	result, err := c.mgr.Client.Request(ctx, http.MethodPost, url, header, nil, body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(result.Response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body error: %w", err)
+	}
+
+	vlog.Infof("apply abnormal refund response | body: %s", respBody)
+
+	var resp refunddomestic.Refund
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal response error: %w", err)
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrefund
+
+import "testing"
+
+func TestAbnormalRefundRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     AbnormalRefundRequest
+		wantErr bool
+	}{
+		{"merchant bank card", AbnormalRefundRequest{RefundID: "50000000382019052709732678859", OutRefundNo: "R001", Type: AbnormalRefundTypeMerchantBankCard}, false},
+		{"user bank card", AbnormalRefundRequest{RefundID: "50000000382019052709732678859", OutRefundNo: "R001", Type: AbnormalRefundTypeUserBankCard, BankType: "ICBC_DEBIT", BankAccount: "6222000000000000", RealName: "张三"}, false},
+		{"user bank card without account", AbnormalRefundRequest{RefundID: "50000000382019052709732678859", OutRefundNo: "R001", Type: AbnormalRefundTypeUserBankCard, BankType: "ICBC_DEBIT"}, true},
+		{"missing refund id", AbnormalRefundRequest{OutRefundNo: "R001", Type: AbnormalRefundTypeMerchantBankCard}, true},
+		{"invalid type", AbnormalRefundRequest{RefundID: "50000000382019052709732678859", OutRefundNo: "R001", Type: "BALANCE"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrefund
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpartners/vwxpartnerjsapi"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/vogo/vwechatpay/vwxpayments/vwxjsapi"
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+var (
+	// ErrOverRefund 累计退款金额超过订单支付金额
+	ErrOverRefund = errors.New("累计退款金额超过订单支付金额")
+	// ErrOrderNotPaid 订单未支付, 不能退款
+	ErrOrderNotPaid = errors.New("订单未支付")
+)
+
+// RefundGoodsDetail 退款商品信息
+type RefundGoodsDetail struct {
+	MerchantGoodsID  string         `json:"merchant_goods_id"`            // 商户侧商品编码
+	WechatpayGoodsID string         `json:"wechatpay_goods_id,omitempty"` // 微信支付商品编码
+	GoodsName        string         `json:"goods_name,omitempty"`         // 商品名称
+	UnitPrice        vwxmoney.Money `json:"unit_price"`                   // 商品单价
+	RefundAmount     vwxmoney.Money `json:"refund_amount"`                // 商品退款金额
+	RefundQuantity   int64          `json:"refund_quantity"`              // 商品退货数量
+}
+
+// LedgerRefundRequest 通过退款台账申请退款的请求
+type LedgerRefundRequest struct {
+	SubMchID      string               `json:"sub_mchid"`      // 子商户号, 服务商模式下使用
+	OutTradeNo    string               `json:"out_trade_no"`   // 商户订单号
+	TransactionID string               `json:"transaction_id"` // 微信支付订单号, 可为空
+	RefundKey     string               `json:"refund_key"`     // 业务退款标识(如售后单号), 用于生成确定的商户退款单号, 重试时保持不变
+	OutRefundNo   string               `json:"out_refund_no"`  // 商户退款单号, 为空时根据 RefundKey 生成
+	Reason        string               `json:"reason"`         // 退款原因
+	Amount        vwxmoney.Money       `json:"amount"`         // 退款金额
+	NotifyURL     string               `json:"notify_url"`     // 退款结果回调地址
+	FundsAccount  string               `json:"funds_account"`  // 退款资金来源, 如 AVAILABLE 表示使用可用余额退款
+	GoodsDetail   []*RefundGoodsDetail `json:"goods_detail"`   // 退款商品
+}
+
+// Validate 校验退款请求
+func (r *LedgerRefundRequest) Validate() error {
+	if r.OutTradeNo == "" {
+		return fmt.Errorf("out_trade_no is empty")
+	}
+
+	if r.OutRefundNo == "" && r.RefundKey == "" {
+		return fmt.Errorf("out_refund_no and refund_key are both empty")
+	}
+
+	if !r.Amount.IsPositive() {
+		return fmt.Errorf("refund amount must be greater than 0: %s", r.Amount)
+	}
+
+	for _, goods := range r.GoodsDetail {
+		if goods.MerchantGoodsID == "" {
+			return fmt.Errorf("merchant_goods_id of goods detail is empty")
+		}
+		if goods.RefundQuantity <= 0 {
+			return fmt.Errorf("refund quantity of goods %s must be greater than 0", goods.MerchantGoodsID)
+		}
+		if !goods.RefundAmount.SameCurrency(r.Amount) || !goods.UnitPrice.SameCurrency(r.Amount) {
+			return fmt.Errorf("currency of goods %s mismatch", goods.MerchantGoodsID)
+		}
+	}
+
+	return nil
+}
+
+// GenerateOutRefundNo 根据商户订单号和业务退款标识生成确定的商户退款单号
+// 同一业务退款重试时生成相同的单号, 微信支付对相同单号的退款只处理一次.
+func GenerateOutRefundNo(outTradeNo, refundKey string) string {
+	sum := sha256.Sum256([]byte(outTradeNo + "|" + refundKey))
+	return "RF" + hex.EncodeToString(sum[:15])
+}
+
+// RefundLedgerEntry 退款台账记录
+type RefundLedgerEntry struct {
+	SubMchID    string         `json:"sub_mchid"`     // 子商户号
+	OutTradeNo  string         `json:"out_trade_no"`  // 商户订单号
+	OutRefundNo string         `json:"out_refund_no"` // 商户退款单号
+	Amount      vwxmoney.Money `json:"amount"`        // 退款金额
+	Status      RefundStatus   `json:"status"`        // 退款状态, 退款关闭的记录不计入累计退款金额
+	CreateTime  time.Time      `json:"create_time"`   // 登记时间
+}
+
+// RefundLedgerStore 退款台账存储
+type RefundLedgerStore interface {
+	// Reserve 原子地校验并登记退款: 该订单未关闭的退款金额加上本次金额不能超过 paidAmount, 否则返回 ErrOverRefund.
+	// 相同退款单号重复登记时返回已有记录, 金额不一致时返回错误; 已关闭的记录重新登记时需再次校验金额.
+	Reserve(ctx context.Context, entry *RefundLedgerEntry, paidAmount vwxmoney.Money) (*RefundLedgerEntry, error)
+	// UpdateStatus 更新退款状态
+	UpdateStatus(ctx context.Context, subMchID, outRefundNo string, status RefundStatus) error
+	// List 列出订单的全部退款记录
+	List(ctx context.Context, subMchID, outTradeNo string) ([]*RefundLedgerEntry, error)
+}
+
+// MemoryRefundLedgerStore 基于内存的退款台账存储
+type MemoryRefundLedgerStore struct {
+	mu      sync.Mutex
+	entries map[string]*RefundLedgerEntry // key: 子商户号/商户退款单号
+}
+
+// NewMemoryRefundLedgerStore 创建基于内存的退款台账存储
+func NewMemoryRefundLedgerStore() *MemoryRefundLedgerStore {
+	return &MemoryRefundLedgerStore{
+		entries: make(map[string]*RefundLedgerEntry),
+	}
+}
+
+func (s *MemoryRefundLedgerStore) Reserve(_ context.Context, entry *RefundLedgerEntry, paidAmount vwxmoney.Money) (*RefundLedgerEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pendingRefundKey(entry.SubMchID, entry.OutRefundNo)
+	existing, ok := s.entries[key]
+	if ok {
+		if existing.OutTradeNo != entry.OutTradeNo || existing.Amount != entry.Amount {
+			return nil, fmt.Errorf("out_refund_no %s already used with amount %s", entry.OutRefundNo, existing.Amount)
+		}
+		if !existing.Status.IsClosed() {
+			e := *existing
+			return &e, nil
+		}
+	}
+
+	refunded := vwxmoney.New(0, entry.Amount.Currency())
+	for k, e := range s.entries {
+		if k == key || e.SubMchID != entry.SubMchID || e.OutTradeNo != entry.OutTradeNo || e.Status.IsClosed() {
+			continue
+		}
+
+		var err error
+		if refunded, err = refunded.Add(e.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	total, err := refunded.Add(entry.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp, err := total.Cmp(paidAmount)
+	if err != nil {
+		return nil, err
+	}
+	if cmp > 0 {
+		return nil, fmt.Errorf("%w: refunded %s, refund %s, paid %s", ErrOverRefund, refunded, entry.Amount, paidAmount)
+	}
+
+	e := *entry
+	s.entries[key] = &e
+
+	return entry, nil
+}
+
+func (s *MemoryRefundLedgerStore) UpdateStatus(_ context.Context, subMchID, outRefundNo string, status RefundStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[pendingRefundKey(subMchID, outRefundNo)]
+	if !ok {
+		return fmt.Errorf("refund ledger entry not found: %s", outRefundNo)
+	}
+
+	entry.Status = status
+
+	return nil
+}
+
+func (s *MemoryRefundLedgerStore) List(_ context.Context, subMchID, outTradeNo string) ([]*RefundLedgerEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []*RefundLedgerEntry
+	for _, entry := range s.entries {
+		if entry.SubMchID == subMchID && entry.OutTradeNo == outTradeNo {
+			e := *entry
+			list = append(list, &e)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreateTime.Before(list[j].CreateTime)
+	})
+
+	return list, nil
+}
+
+// PaidAmountQuerier 查询订单支付金额, 订单未支付时返回 ErrOrderNotPaid
+type PaidAmountQuerier func(ctx context.Context, subMchID, outTradeNo string) (vwxmoney.Money, error)
+
+// JsApiPaidAmountQuerier 通过直连商户JSAPI客户端查询订单支付金额
+func JsApiPaidAmountQuerier(client *vwxjsapi.JsApiClient) PaidAmountQuerier {
+	return func(ctx context.Context, _, outTradeNo string) (vwxmoney.Money, error) {
+		tx, err := client.QueryOrderByOutTradeNo(ctx, outTradeNo)
+		if err != nil {
+			return vwxmoney.Money{}, err
+		}
+
+		if !vwxpayments.TransactionTradeState(tx).IsPaid() || tx.Amount == nil || tx.Amount.Total == nil {
+			return vwxmoney.Money{}, fmt.Errorf("%w: %s", ErrOrderNotPaid, outTradeNo)
+		}
+
+		return vwxmoney.New(*tx.Amount.Total, stringValue(tx.Amount.Currency)), nil
+	}
+}
+
+// PartnerJsApiPaidAmountQuerier 通过服务商JSAPI客户端查询子商户订单支付金额
+func PartnerJsApiPaidAmountQuerier(client *vwxpartnerjsapi.PartnerJsApiClient) PaidAmountQuerier {
+	return func(ctx context.Context, subMchID, outTradeNo string) (vwxmoney.Money, error) {
+		tx, err := client.QueryOrderByOutTradeNo(ctx, subMchID, outTradeNo)
+		if err != nil {
+			return vwxmoney.Money{}, err
+		}
+
+		if !vwxpayments.PartnerTransactionTradeState(tx).IsPaid() || tx.Amount == nil || tx.Amount.Total == nil {
+			return vwxmoney.Money{}, fmt.Errorf("%w: %s", ErrOrderNotPaid, outTradeNo)
+		}
+
+		return vwxmoney.New(*tx.Amount.Total, stringValue(tx.Amount.Currency)), nil
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// refundCreator 申请退款接口, 由 RefundClient 实现
+type refundCreator interface {
+	CreateRefund(ctx context.Context, req *refunddomestic.CreateRequest) (*refunddomestic.Refund, error)
+}
+
+// RefundLedger 退款台账
+// 按订单登记退款记录, 申请退款前校验累计退款金额不超过订单支付金额, 避免多个服务重复或超额退款.
+type RefundLedger struct {
+	client     refundCreator
+	paidAmount PaidAmountQuerier
+	store      RefundLedgerStore
+}
+
+// RefundLedgerOption 退款台账可选项
+type RefundLedgerOption func(*RefundLedger)
+
+// WithRefundLedgerStore 设置退款台账存储, 默认使用内存存储, 多实例部署时需使用共享存储
+func WithRefundLedgerStore(store RefundLedgerStore) RefundLedgerOption {
+	return func(l *RefundLedger) { l.store = store }
+}
+
+// NewRefundLedger 创建退款台账
+// paidAmount: 订单支付金额查询, 可使用 JsApiPaidAmountQuerier 或 PartnerJsApiPaidAmountQuerier
+func NewRefundLedger(client *RefundClient, paidAmount PaidAmountQuerier, opts ...RefundLedgerOption) *RefundLedger {
+	return newRefundLedger(client, paidAmount, opts...)
+}
+
+func newRefundLedger(client refundCreator, paidAmount PaidAmountQuerier, opts ...RefundLedgerOption) *RefundLedger {
+	l := &RefundLedger{
+		client:     client,
+		paidAmount: paidAmount,
+		store:      NewMemoryRefundLedgerStore(),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Refund 校验累计退款金额并申请退款
+// 申请明确失败(请求参数错误等)时释放登记的金额, 结果未知时保留登记, 可使用相同的 RefundKey 重试.
+func (l *RefundLedger) Refund(ctx context.Context, req *LedgerRefundRequest) (*refunddomestic.Refund, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	outRefundNo := req.OutRefundNo
+	if outRefundNo == "" {
+		outRefundNo = GenerateOutRefundNo(req.OutTradeNo, req.RefundKey)
+	}
+
+	paidAmount, err := l.paidAmount(ctx, req.SubMchID, req.OutTradeNo)
+	if err != nil {
+		return nil, err
+	}
+
+	if !paidAmount.SameCurrency(req.Amount) {
+		return nil, fmt.Errorf("%w: paid %s, refund %s", vwxmoney.ErrCurrencyMismatch, paidAmount, req.Amount)
+	}
+
+	_, err = l.store.Reserve(ctx, &RefundLedgerEntry{
+		SubMchID:    req.SubMchID,
+		OutTradeNo:  req.OutTradeNo,
+		OutRefundNo: outRefundNo,
+		Amount:      req.Amount,
+		Status:      RefundStatusProcessing,
+		CreateTime:  time.Now(),
+	}, paidAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	refund, err := l.client.CreateRefund(ctx, buildLedgerRefundRequest(req, outRefundNo, paidAmount))
+	if err != nil {
+		if vwxutils.IsDefiniteFailure(err) {
+			if updateErr := l.store.UpdateStatus(ctx, req.SubMchID, outRefundNo, RefundStatusClosed); updateErr != nil {
+				vlog.Errorf("release refund ledger entry error | out_refund_no: %s | err: %v", outRefundNo, updateErr)
+			}
+		}
+		return nil, err
+	}
+
+	if status := RefundStatusOf(refund); status.IsKnown() {
+		if err := l.store.UpdateStatus(ctx, req.SubMchID, outRefundNo, status); err != nil {
+			vlog.Errorf("update refund ledger status error | out_refund_no: %s | err: %v", outRefundNo, err)
+		}
+	}
+
+	return refund, nil
+}
+
+// UpdateStatus 更新退款状态, 收到退款结果通知或 RefundWatcher 回调时调用, 退款关闭后释放登记的金额
+func (l *RefundLedger) UpdateStatus(ctx context.Context, subMchID, outRefundNo string, status RefundStatus) error {
+	return l.store.UpdateStatus(ctx, subMchID, outRefundNo, status)
+}
+
+// Refunded 查询订单累计退款金额(不含已关闭的退款)
+func (l *RefundLedger) Refunded(ctx context.Context, subMchID, outTradeNo string) (vwxmoney.Money, error) {
+	entries, err := l.store.List(ctx, subMchID, outTradeNo)
+	if err != nil {
+		return vwxmoney.Money{}, err
+	}
+
+	amounts := make([]vwxmoney.Money, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Status.IsClosed() {
+			amounts = append(amounts, entry.Amount)
+		}
+	}
+
+	return vwxmoney.Sum(amounts...)
+}
+
+func buildLedgerRefundRequest(req *LedgerRefundRequest, outRefundNo string, paidAmount vwxmoney.Money) *refunddomestic.CreateRequest {
+	createReq := &refunddomestic.CreateRequest{
+		OutRefundNo: core.String(outRefundNo),
+		Amount: &refunddomestic.AmountReq{
+			Refund:   core.Int64(req.Amount.Fen()),
+			Total:    core.Int64(paidAmount.Fen()),
+			Currency: core.String(req.Amount.Currency()),
+		},
+	}
+
+	// 优先使用微信支付订单号
+	if req.TransactionID != "" {
+		createReq.TransactionId = core.String(req.TransactionID)
+	} else {
+		createReq.OutTradeNo = core.String(req.OutTradeNo)
+	}
+
+	if req.SubMchID != "" {
+		createReq.SubMchid = core.String(req.SubMchID)
+	}
+
+	if req.Reason != "" {
+		createReq.Reason = core.String(req.Reason)
+	}
+
+	if req.NotifyURL != "" {
+		createReq.NotifyUrl = core.String(req.NotifyURL)
+	}
+
+	if req.FundsAccount != "" {
+		createReq.FundsAccount = refunddomestic.ReqFundsAccount(req.FundsAccount).Ptr()
+	}
+
+	for _, goods := range req.GoodsDetail {
+		detail := refunddomestic.GoodsDetail{
+			MerchantGoodsId: core.String(goods.MerchantGoodsID),
+			UnitPrice:       core.Int64(goods.UnitPrice.Fen()),
+			RefundAmount:    core.Int64(goods.RefundAmount.Fen()),
+			RefundQuantity:  core.Int64(goods.RefundQuantity),
+		}
+		if goods.WechatpayGoodsID != "" {
+			detail.WechatpayGoodsId = core.String(goods.WechatpayGoodsID)
+		}
+		if goods.GoodsName != "" {
+			detail.GoodsName = core.String(goods.GoodsName)
+		}
+		createReq.GoodsDetail = append(createReq.GoodsDetail, detail)
+	}
+
+	return createReq
+}
@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrefund
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+type fakeRefundCreator struct {
+	requests []*refunddomestic.CreateRequest
+	err      error
+}
+
+func (c *fakeRefundCreator) CreateRefund(_ context.Context, req *refunddomestic.CreateRequest) (*refunddomestic.Refund, error) {
+	c.requests = append(c.requests, req)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &refunddomestic.Refund{
+		OutRefundNo: req.OutRefundNo,
+		Status:      refunddomestic.STATUS_PROCESSING.Ptr(),
+	}, nil
+}
+
+func TestRefundLedger(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeRefundCreator{}
+	paid := func(context.Context, string, string) (vwxmoney.Money, error) {
+		return vwxmoney.Fen(1000), nil
+	}
+	ledger := newRefundLedger(client, paid)
+
+	newReq := func(key string, fen int64) *LedgerRefundRequest {
+		return &LedgerRefundRequest{OutTradeNo: "T001", RefundKey: key, Amount: vwxmoney.Fen(fen)}
+	}
+
+	if _, err := ledger.Refund(ctx, newReq("A1", 600)); err != nil {
+		t.Fatal(err)
+	}
+	if *client.requests[0].Amount.Total != 1000 || *client.requests[0].OutRefundNo != GenerateOutRefundNo("T001", "A1") {
+		t.Errorf("unexpected request: %+v", client.requests[0])
+	}
+
+	// 相同业务标识重试不重复计入
+	if _, err := ledger.Refund(ctx, newReq("A1", 600)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ledger.Refund(ctx, newReq("A2", 500)); !errors.Is(err, ErrOverRefund) {
+		t.Fatalf("expected ErrOverRefund, got %v", err)
+	}
+
+	if _, err := ledger.Refund(ctx, newReq("A1", 500)); err == nil {
+		t.Errorf("expected error when reusing out_refund_no with another amount")
+	}
+
+	// 退款关闭后释放金额
+	if err := ledger.UpdateStatus(ctx, "", GenerateOutRefundNo("T001", "A1"), RefundStatusClosed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledger.Refund(ctx, newReq("A2", 500)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 明确失败时释放金额
+	client.err = &core.APIError{StatusCode: http.StatusBadRequest, Code: "PARAM_ERROR"}
+	if _, err := ledger.Refund(ctx, newReq("A3", 500)); err == nil {
+		t.Fatal("expected api error")
+	}
+
+	refunded, err := ledger.Refunded(ctx, "", "T001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refunded.Fen() != 500 {
+		t.Errorf("refunded = %s", refunded)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/vogo/vwechatpay/vwxmoney"
 	"github.com/wechatpay-apiv3/wechatpay-go/core"
 	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
 )
@@ -44,14 +45,28 @@ func (c *RefundClient) CreateRefund(ctx context.Context, req *refunddomestic.Cre
 
 // CreateRefundWithAmount 申请退款（简化版）
 // 提供了一个简化版的退款接口，只需要提供必要的参数
+// refundAmount: 退款金额
+// totalAmount: 原订单金额, 货币类型须与退款金额一致
 func (c *RefundClient) CreateRefundWithAmount(ctx context.Context, outRefundNo, transactionID, outTradeNo, reason string,
-	refundAmount, totalAmount int64, subMchID string,
+	refundAmount, totalAmount vwxmoney.Money, subMchID string,
 ) (*refunddomestic.Refund, error) {
+	if !refundAmount.IsPositive() {
+		return nil, fmt.Errorf("refund amount must be greater than 0: %s", refundAmount)
+	}
+
+	cmp, err := refundAmount.Cmp(totalAmount)
+	if err != nil {
+		return nil, err
+	}
+	if cmp > 0 {
+		return nil, fmt.Errorf("refund amount %s exceeds total amount %s", refundAmount, totalAmount)
+	}
+
 	// 构建退款金额信息
 	amountReq := &refunddomestic.AmountReq{
-		Refund:   core.Int64(refundAmount),
-		Total:    core.Int64(totalAmount),
-		Currency: core.String("CNY"),
+		Refund:   core.Int64(refundAmount.Fen()),
+		Total:    core.Int64(totalAmount.Fen()),
+		Currency: core.String(refundAmount.Currency()),
 	}
 
 	// 构建退款请求
@@ -0,0 +1,96 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrefund
+
+import (
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+// RefundStatus 退款状态
+type RefundStatus string
+
+const (
+	RefundStatusSuccess    RefundStatus = "SUCCESS"    // 退款成功
+	RefundStatusClosed     RefundStatus = "CLOSED"     // 退款关闭
+	RefundStatusProcessing RefundStatus = "PROCESSING" // 退款处理中
+	RefundStatusAbnormal   RefundStatus = "ABNORMAL"   // 退款异常，退款到银行发现用户的卡作废或者冻结了，导致原路退款银行卡失败，可发起异常退款处理
+)
+
+// RefundStatusText 退款状态描述
+func RefundStatusText(status string) string {
+	return RefundStatus(status).Text()
+}
+
+// RefundStatusOf 获取退款单的退款状态
+func RefundStatusOf(refund *refunddomestic.Refund) RefundStatus {
+	if refund == nil || refund.Status == nil {
+		return ""
+	}
+	return RefundStatus(*refund.Status)
+}
+
+// Text 退款状态描述
+func (s RefundStatus) Text() string {
+	switch s {
+	case RefundStatusSuccess:
+		return "退款成功"
+	case RefundStatusClosed:
+		return "退款关闭"
+	case RefundStatusProcessing:
+		return "退款处理中"
+	case RefundStatusAbnormal:
+		return "退款异常"
+	default:
+		return "未知状态"
+	}
+}
+
+// IsKnown 是否为已知的退款状态
+func (s RefundStatus) IsKnown() bool {
+	switch s {
+	case RefundStatusSuccess, RefundStatusClosed, RefundStatusProcessing, RefundStatusAbnormal:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsSuccess 是否退款成功
+func (s RefundStatus) IsSuccess() bool {
+	return s == RefundStatusSuccess
+}
+
+// IsProcessing 是否退款处理中
+func (s RefundStatus) IsProcessing() bool {
+	return s == RefundStatusProcessing
+}
+
+// IsClosed 是否退款关闭
+func (s RefundStatus) IsClosed() bool {
+	return s == RefundStatusClosed
+}
+
+// IsAbnormal 是否退款异常, 需要发起异常退款或通过商户平台处理
+func (s RefundStatus) IsAbnormal() bool {
+	return s == RefundStatusAbnormal
+}
+
+// IsTerminal 是否为终态, 退款异常在处理前不会再变化, 也视为终态
+func (s RefundStatus) IsTerminal() bool {
+	return s == RefundStatusSuccess || s == RefundStatusClosed || s == RefundStatusAbnormal
+}
@@ -0,0 +1,181 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrefund
+
+import (
+	"context"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/vogo/vwechatpay/vwxutils"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+const (
+	defaultWatchTickInterval   = 10 * time.Second
+	defaultWatchInitialBackoff = 30 * time.Second
+	defaultWatchMaxBackoff     = 30 * time.Minute
+)
+
+// PendingRefund 待确认结果的退款单
+type PendingRefund struct {
+	SubMchID    string `json:"sub_mchid"`     // 子商户号, 服务商模式下使用
+	OutRefundNo string `json:"out_refund_no"` // 商户退款单号
+
+	vwxutils.PollSchedule
+}
+
+// Key 退款单在存储中的唯一标识
+func (r *PendingRefund) Key() string {
+	return pendingRefundKey(r.SubMchID, r.OutRefundNo)
+}
+
+func pendingRefundKey(subMchID, outRefundNo string) string {
+	if subMchID == "" {
+		return outRefundNo
+	}
+	return subMchID + "/" + outRefundNo
+}
+
+// PendingRefundStore 待确认退款单存储, 实现持久化存储可在服务重启后继续跟踪退款, Remove 的 key 为 PendingRefund.Key
+type PendingRefundStore = vwxutils.PollStore[PendingRefund]
+
+// MemoryPendingRefundStore 基于内存的待确认退款单存储
+type MemoryPendingRefundStore = vwxutils.MemoryPollStore[PendingRefund, *PendingRefund]
+
+// NewMemoryPendingRefundStore 创建基于内存的待确认退款单存储
+func NewMemoryPendingRefundStore() *MemoryPendingRefundStore {
+	return vwxutils.NewMemoryPollStore((*PendingRefund).Key)
+}
+
+// RefundWatchHandler 退款单进入终态(退款成功、退款关闭、退款异常)时的回调
+type RefundWatchHandler func(ctx context.Context, pending *PendingRefund, refund *refunddomestic.Refund)
+
+// RefundGiveUpHandler 退款单超过最大查询次数仍未确认结果, 放弃跟踪时的回调
+type RefundGiveUpHandler func(ctx context.Context, pending *PendingRefund)
+
+// refundQuerier 退款查询接口, 由 RefundClient 实现
+type refundQuerier interface {
+	QueryByOutRefundNo(ctx context.Context, subMchID, outRefundNo string) (*refunddomestic.Refund, error)
+}
+
+// RefundWatcher 退款结果跟踪器
+// 申请退款后大多返回处理中, 跟踪器按退避间隔轮询查询退款单, 进入终态时回调
+type RefundWatcher struct {
+	client  refundQuerier
+	handler RefundWatchHandler
+	poller  *vwxutils.Poller[PendingRefund, *PendingRefund]
+}
+
+// RefundWatcherOption 退款跟踪器可选项
+type RefundWatcherOption func(*RefundWatcher)
+
+// WithPendingRefundStore 设置待确认退款单存储, 默认使用内存存储
+func WithPendingRefundStore(store PendingRefundStore) RefundWatcherOption {
+	return func(w *RefundWatcher) { w.poller.Store = store }
+}
+
+// WithWatchBackoff 设置轮询查询的退避间隔
+func WithWatchBackoff(initial, max time.Duration) RefundWatcherOption {
+	return func(w *RefundWatcher) { w.poller.Backoff = vwxutils.NewBackoff(initial, max) }
+}
+
+// WithWatchTickInterval 设置扫描待确认退款单的间隔
+func WithWatchTickInterval(interval time.Duration) RefundWatcherOption {
+	return func(w *RefundWatcher) { w.poller.TickInterval = interval }
+}
+
+// WithWatchMaxAttempts 设置最大查询次数, 超过后回调 handler 并放弃跟踪, 默认不限制
+func WithWatchMaxAttempts(max int, handler RefundGiveUpHandler) RefundWatcherOption {
+	return func(w *RefundWatcher) {
+		w.poller.MaxAttempts = max
+		w.poller.GiveUp = handler
+	}
+}
+
+// NewRefundWatcher 创建退款结果跟踪器, 后台任务运行在 Manager 的 Runner 上, Manager 停止时随之停止
+func NewRefundWatcher(client *RefundClient, handler RefundWatchHandler, opts ...RefundWatcherOption) *RefundWatcher {
+	return newRefundWatcher(client, client.mgr.Runner().NewChild(), handler, opts...)
+}
+
+func newRefundWatcher(client refundQuerier, runner *vrun.Runner, handler RefundWatchHandler, opts ...RefundWatcherOption) *RefundWatcher {
+	w := &RefundWatcher{
+		client:  client,
+		handler: handler,
+	}
+
+	w.poller = vwxutils.NewPoller("pending refund", runner, (*PendingRefund).Key, w.check)
+	w.poller.Backoff = vwxutils.NewBackoff(defaultWatchInitialBackoff, defaultWatchMaxBackoff)
+	w.poller.TickInterval = defaultWatchTickInterval
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Start 启动后台轮询, 存储中已有的退款单会继续被跟踪
+func (w *RefundWatcher) Start() {
+	w.poller.Start()
+}
+
+// Stop 停止后台轮询
+func (w *RefundWatcher) Stop() {
+	w.poller.Stop()
+}
+
+// Watch 跟踪退款结果
+// subMchID: 子商户号, 服务商模式下使用
+// outRefundNo: 商户退款单号
+func (w *RefundWatcher) Watch(ctx context.Context, subMchID, outRefundNo string) error {
+	vlog.Infof("watch refund | sub_mchid: %s | out_refund_no: %s", subMchID, outRefundNo)
+
+	return w.poller.Schedule(ctx, &PendingRefund{
+		SubMchID:    subMchID,
+		OutRefundNo: outRefundNo,
+	})
+}
+
+// Unwatch 取消跟踪退款, 如已收到退款结果通知时调用
+func (w *RefundWatcher) Unwatch(ctx context.Context, subMchID, outRefundNo string) error {
+	return w.poller.Remove(ctx, pendingRefundKey(subMchID, outRefundNo))
+}
+
+// check 查询退款状态, 终态时回调, 返回退款单是否已完成
+func (w *RefundWatcher) check(ctx context.Context, pending *PendingRefund) bool {
+	refund, err := w.client.QueryByOutRefundNo(ctx, pending.SubMchID, pending.OutRefundNo)
+	if err != nil {
+		vlog.Errorf("watch refund query error | out_refund_no: %s | err: %v", pending.OutRefundNo, err)
+		return false
+	}
+
+	status := RefundStatusOf(refund)
+	if !status.IsTerminal() {
+		return false
+	}
+
+	vlog.Infof("watch refund finished | out_refund_no: %s | status: %s", pending.OutRefundNo, status)
+
+	if w.handler != nil {
+		w.handler(ctx, pending, refund)
+	}
+
+	return true
+}
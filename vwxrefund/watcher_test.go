@@ -0,0 +1,83 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxrefund
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vogo/vogo/vsync/vrun"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/refunddomestic"
+)
+
+type fakeRefundQuerier struct {
+	statuses map[string]RefundStatus
+}
+
+func (c *fakeRefundQuerier) QueryByOutRefundNo(_ context.Context, subMchID, outRefundNo string) (*refunddomestic.Refund, error) {
+	return &refunddomestic.Refund{
+		OutRefundNo: core.String(outRefundNo),
+		Status:      refunddomestic.Status(c.statuses[pendingRefundKey(subMchID, outRefundNo)]).Ptr(),
+	}, nil
+}
+
+func TestRefundWatcher(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeRefundQuerier{statuses: map[string]RefundStatus{
+		"R001":            RefundStatusSuccess,
+		"1900000109/R001": RefundStatusProcessing,
+		"R002":            RefundStatusAbnormal,
+	}}
+
+	results := map[string]RefundStatus{}
+	handler := func(_ context.Context, pending *PendingRefund, refund *refunddomestic.Refund) {
+		results[pending.Key()] = RefundStatusOf(refund)
+	}
+
+	w := newRefundWatcher(client, vrun.New(), handler, WithWatchBackoff(0, time.Minute))
+
+	_ = w.Watch(ctx, "", "R001")
+	_ = w.Watch(ctx, "1900000109", "R001")
+	_ = w.Watch(ctx, "", "R002")
+
+	w.poller.Poll()
+
+	if results["R001"] != RefundStatusSuccess || results["R002"] != RefundStatusAbnormal {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	if _, ok := results["1900000109/R001"]; ok {
+		t.Errorf("processing refund should not finish")
+	}
+
+	pending, _ := w.poller.Store.List(ctx)
+	if len(pending) != 1 || pending[0].Attempts != 1 {
+		t.Errorf("processing refund should be rescheduled: %+v", pending)
+	}
+
+	client.statuses["1900000109/R001"] = RefundStatusClosed
+	pending[0].NextPollTime = time.Now()
+	_ = w.poller.Store.Save(ctx, pending[0])
+	w.poller.Poll()
+
+	if results["1900000109/R001"] != RefundStatusClosed {
+		t.Errorf("refund should be closed: %v", results)
+	}
+}
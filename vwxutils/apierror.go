@@ -0,0 +1,50 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxutils
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+)
+
+// IsNotFound 请求的资源(如订单、转账单)是否不存在
+func IsNotFound(err error) bool {
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.Code {
+	case "RESOURCE_NOT_EXISTS", "NOT_FOUND", "ORDER_NOT_EXIST", "ORDERNOTEXIST":
+		return true
+	}
+
+	return apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsDefiniteFailure 请求是否被微信支付明确拒绝, 4xx 错误(限流除外)表示请求未被受理, 可释放预占的额度或金额
+// 5xx、限流及网络错误的结果未知, 应查询确认或使用相同的单号重试.
+func IsDefiniteFailure(err error) bool {
+	var apiErr *core.APIError
+	return errors.As(err, &apiErr) &&
+		apiErr.StatusCode >= http.StatusBadRequest &&
+		apiErr.StatusCode < http.StatusInternalServerError &&
+		apiErr.StatusCode != http.StatusTooManyRequests
+}
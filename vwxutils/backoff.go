@@ -0,0 +1,45 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxutils
+
+import "time"
+
+// Backoff 指数退避策略, 每次重试的间隔为上一次的两倍, 最大不超过 Max
+type Backoff struct {
+	Initial time.Duration // 首次重试间隔
+	Max     time.Duration // 最大重试间隔
+}
+
+// NewBackoff 创建指数退避策略
+func NewBackoff(initial, max time.Duration) Backoff {
+	return Backoff{Initial: initial, Max: max}
+}
+
+// Next 第 attempt 次(从0开始)重试前的等待间隔
+func (b Backoff) Next(attempt int) time.Duration {
+	d := b.Initial
+	for i := 0; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+
+	if b.Max > 0 && d > b.Max {
+		return b.Max
+	}
+
+	return d
+}
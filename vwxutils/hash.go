@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxutils
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// NewHash 根据微信支付返回的摘要算法创建哈希, 支持 SHA1、SHA256
+func NewHash(hashType string) (hash.Hash, error) {
+	switch strings.ToUpper(hashType) {
+	case "SHA1":
+		return sha1.New(), nil
+	case "SHA256":
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash type: %s", hashType)
+	}
+}
+
+// VerifyHash 校验文件摘要, hashValue 为十六进制字符串, 不区分大小写
+func VerifyHash(data []byte, hashType, hashValue string) error {
+	h, err := NewHash(hashType)
+	if err != nil {
+		return err
+	}
+
+	h.Write(data)
+
+	return VerifyHashSum(h, hashValue)
+}
+
+// VerifyHashSum 校验已写入数据的哈希摘要, 用于边读取边计算摘要的场景
+func VerifyHashSum(h hash.Hash, hashValue string) error {
+	actual := hex.EncodeToString(h.Sum(nil))
+	if subtle.ConstantTimeCompare([]byte(actual), []byte(strings.ToLower(hashValue))) != 1 {
+		return fmt.Errorf("hash mismatch, expected: %s, actual: %s", hashValue, actual)
+	}
+
+	return nil
+}
+
+// hashVerifyReader 边读取边计算摘要, 读取到末尾时校验摘要
+type hashVerifyReader struct {
+	r         io.Reader
+	h         hash.Hash
+	hashValue string
+	err       error
+}
+
+// NewHashVerifyReader 创建边读取边计算摘要的读取器, 读取到末尾时校验摘要, 不一致时以校验错误代替 io.EOF 返回
+func NewHashVerifyReader(r io.Reader, hashType, hashValue string) (io.Reader, error) {
+	h, err := NewHash(hashType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hashVerifyReader{r: io.TeeReader(r, h), h: h, hashValue: hashValue}, nil
+}
+
+func (v *hashVerifyReader) Read(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+
+	n, err := v.r.Read(p)
+	if err == io.EOF {
+		if verifyErr := VerifyHashSum(v.h, v.hashValue); verifyErr != nil {
+			err = verifyErr
+		}
+	}
+
+	if err != nil {
+		v.err = err
+	}
+
+	return n, err
+}
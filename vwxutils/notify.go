@@ -0,0 +1,57 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxutils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+	"github.com/wechatpay-apiv3/wechatpay-go/utils"
+)
+
+// ParseNotifyBody 解析回调通知体, 使用APIv3密钥解密通知内容并解析到 T, name 用于日志及错误信息中区分通知类型
+// 调用方需先验证回调通知签名.
+func ParseNotifyBody[T any](apiV3Key, name string, body []byte) (*notify.Request, *T, error) {
+	ret := new(notify.Request)
+	if err := json.Unmarshal(body, ret); err != nil {
+		return nil, nil, fmt.Errorf("parse request body error: %w", err)
+	}
+
+	if ret.Resource == nil {
+		return ret, nil, fmt.Errorf("notify resource is empty")
+	}
+
+	// 解密通知内容
+	plaintext, err := utils.DecryptAES256GCM(apiV3Key, ret.Resource.AssociatedData, ret.Resource.Nonce, ret.Resource.Ciphertext)
+	if err != nil {
+		return ret, nil, fmt.Errorf("decrypt request error: %w", err)
+	}
+
+	ret.Resource.Plaintext = plaintext
+
+	vlog.Infof("received %s notify | event_type: %s | plaintext: %s", name, ret.EventType, plaintext)
+
+	content := new(T)
+	if err := json.Unmarshal([]byte(plaintext), content); err != nil {
+		return ret, nil, fmt.Errorf("unmarshal %s notify error: %w", name, err)
+	}
+
+	return ret, content, nil
+}
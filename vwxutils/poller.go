@@ -0,0 +1,336 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package vwxutils
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vogo/vsync/vrun"
+)
+
+// PollSchedule 轮询任务的查询进度, 嵌入到具体的轮询任务结构体中
+type PollSchedule struct {
+	Attempts     int       `json:"attempts"`       // 已查询次数
+	NextPollTime time.Time `json:"next_poll_time"` // 下次查询时间, 已完成的任务为删除时间
+	Done         bool      `json:"done,omitempty"` // 任务已完成, 仅保留至 NextPollTime 供查询
+}
+
+// Schedule 返回任务的查询进度
+func (s *PollSchedule) Schedule() *PollSchedule {
+	return s
+}
+
+// PollTask 轮询任务类型约束, P 为嵌入了 PollSchedule 的任务结构体 T 的指针
+type PollTask[T any] interface {
+	*T
+	Schedule() *PollSchedule
+}
+
+// PollStore 轮询任务存储, 实现持久化存储可在服务重启后继续跟踪任务
+type PollStore[T any] interface {
+	// Save 保存或更新任务
+	Save(ctx context.Context, task *T) error
+	// Get 获取任务, 不存在时返回 nil
+	Get(ctx context.Context, key string) (*T, error)
+	// Remove 删除任务
+	Remove(ctx context.Context, key string) error
+	// List 列出全部任务, 按下次查询时间排序
+	List(ctx context.Context) ([]*T, error)
+}
+
+// MemoryPollStore 基于内存的轮询任务存储
+type MemoryPollStore[T any, P PollTask[T]] struct {
+	mu    sync.Mutex
+	key   func(P) string
+	tasks map[string]*T
+}
+
+// NewMemoryPollStore 创建基于内存的轮询任务存储, key 返回任务的唯一标识
+func NewMemoryPollStore[T any, P PollTask[T]](key func(P) string) *MemoryPollStore[T, P] {
+	return &MemoryPollStore[T, P]{
+		key:   key,
+		tasks: make(map[string]*T),
+	}
+}
+
+func (s *MemoryPollStore[T, P]) Save(_ context.Context, task *T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := *task
+	s.tasks[s.key(task)] = &t
+
+	return nil
+}
+
+func (s *MemoryPollStore[T, P]) Get(_ context.Context, key string) (*T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[key]
+	if !ok {
+		return nil, nil
+	}
+
+	t := *task
+	return &t, nil
+}
+
+func (s *MemoryPollStore[T, P]) Remove(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tasks, key)
+
+	return nil
+}
+
+func (s *MemoryPollStore[T, P]) List(_ context.Context) ([]*T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*T, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		t := *task
+		list = append(list, &t)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return P(list[i]).Schedule().NextPollTime.Before(P(list[j]).Schedule().NextPollTime)
+	})
+
+	return list, nil
+}
+
+// PollCheckFunc 查询任务的最新状态, 返回 true 表示任务已完成, 否则按退避间隔安排下次查询
+type PollCheckFunc[P any] func(ctx context.Context, task P) bool
+
+// Poller 轮询任务调度器
+// 定期扫描存储中到达查询时间的任务并调用检查函数, 未完成的任务按退避间隔安排下次查询,
+// 设置了最大查询次数时超过次数的任务回调 GiveUp 后不再跟踪.
+// 检查期间通过 Remove 删除的任务在检查结束后不会被重新保存.
+// 设置了 Retention 时已完成的任务标记为完成后保留一段时间, 便于识别重复提交的任务.
+type Poller[T any, P PollTask[T]] struct {
+	Store        PollStore[T]                      // 任务存储, 默认使用内存存储
+	Backoff      Backoff                           // 查询退避间隔
+	TickInterval time.Duration                     // 扫描任务的间隔
+	MaxAttempts  int                               // 最大查询次数, 0 表示不限制
+	GiveUp       func(ctx context.Context, task P) // 超过最大查询次数时的回调
+	Deadline     func(task P) time.Time            // 任务的截止时间, 下次查询时间不晚于未到的截止时间, 为空或返回零值时不限制
+	Retention    time.Duration                     // 已完成任务的保留时长, 0 表示完成后立即删除
+
+	name      string
+	runner    *vrun.Runner
+	key       func(P) string
+	check     PollCheckFunc[P]
+	startOnce sync.Once
+
+	mu       sync.Mutex
+	checking map[string]bool // 正在检查的任务
+	removed  map[string]bool // 检查期间被删除的任务
+}
+
+// NewPoller 创建轮询任务调度器
+// name: 任务名称, 用于日志
+// key: 返回任务在存储中的唯一标识
+// check: 查询任务的最新状态
+func NewPoller[T any, P PollTask[T]](name string, runner *vrun.Runner, key func(P) string, check PollCheckFunc[P]) *Poller[T, P] {
+	return &Poller[T, P]{
+		Store:    NewMemoryPollStore[T, P](key),
+		name:     name,
+		runner:   runner,
+		key:      key,
+		check:    check,
+		checking: make(map[string]bool),
+		removed:  make(map[string]bool),
+	}
+}
+
+// Start 启动后台轮询, 存储中已有的任务会继续被跟踪
+func (p *Poller[T, P]) Start() {
+	p.startOnce.Do(func() {
+		p.runner.Interval(p.Poll, p.TickInterval)
+	})
+}
+
+// Stop 停止后台轮询
+func (p *Poller[T, P]) Stop() {
+	p.runner.Stop()
+}
+
+// Schedule 按任务当前的查询次数计算下次查询时间并保存任务
+func (p *Poller[T, P]) Schedule(ctx context.Context, task P) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.schedule(ctx, task)
+}
+
+func (p *Poller[T, P]) schedule(ctx context.Context, task P) error {
+	s := task.Schedule()
+	s.NextPollTime = p.nextPollTime(task, time.Now().Add(p.Backoff.Next(s.Attempts)))
+
+	return p.Store.Save(ctx, task)
+}
+
+// Get 获取任务, 包括保留期内已完成的任务, 不存在时返回 nil
+func (p *Poller[T, P]) Get(ctx context.Context, key string) (P, error) {
+	return p.Store.Get(ctx, key)
+}
+
+// Complete 完成任务, 不再查询
+func (p *Poller[T, P]) Complete(ctx context.Context, task P) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.complete(ctx, task)
+}
+
+func (p *Poller[T, P]) complete(ctx context.Context, task P) error {
+	if p.Retention <= 0 {
+		return p.Store.Remove(ctx, p.key(task))
+	}
+
+	s := task.Schedule()
+	s.Done = true
+	s.NextPollTime = time.Now().Add(p.Retention)
+
+	return p.Store.Save(ctx, task)
+}
+
+// Remove 删除任务, 不再跟踪
+// 任务正在检查时, 检查结束后不再保存或回调该任务.
+func (p *Poller[T, P]) Remove(ctx context.Context, key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.checking[key] {
+		p.removed[key] = true
+	}
+
+	return p.Store.Remove(ctx, key)
+}
+
+// Poll 检查所有到达查询时间的任务, 由后台轮询定期调用
+func (p *Poller[T, P]) Poll() {
+	ctx := context.Background()
+
+	tasks, err := p.Store.List(ctx)
+	if err != nil {
+		vlog.Errorf("list %s error | err: %v", p.name, err)
+		return
+	}
+
+	now := time.Now()
+	for _, task := range tasks {
+		s := P(task).Schedule()
+		if s.NextPollTime.After(now) {
+			continue
+		}
+
+		select {
+		case <-p.runner.C:
+			return
+		default:
+		}
+
+		// 已完成的任务保留期满后删除
+		if s.Done {
+			if err := p.Remove(ctx, p.key(task)); err != nil {
+				vlog.Errorf("remove %s error | key: %s | err: %v", p.name, p.key(task), err)
+			}
+			continue
+		}
+
+		p.checkTask(ctx, P(task))
+	}
+}
+
+func (p *Poller[T, P]) checkTask(ctx context.Context, task P) {
+	key := p.key(task)
+
+	p.mu.Lock()
+	p.checking[key] = true
+	p.mu.Unlock()
+
+	done := p.check(ctx, task)
+
+	if giveUp := p.finishCheck(ctx, key, task, done); giveUp && p.GiveUp != nil {
+		p.GiveUp(ctx, task)
+	}
+}
+
+// finishCheck 根据检查结果删除或重新安排任务, 返回是否因超过最大查询次数放弃跟踪
+func (p *Poller[T, P]) finishCheck(ctx context.Context, key string, task P, done bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	removed := p.removed[key]
+	delete(p.checking, key)
+	delete(p.removed, key)
+
+	// 检查期间任务已被删除(如收到回调通知后取消跟踪), 不再保存, 避免重新跟踪已处理的任务
+	if removed {
+		return false
+	}
+
+	if done {
+		if err := p.complete(ctx, task); err != nil {
+			vlog.Errorf("complete %s error | key: %s | err: %v", p.name, key, err)
+		}
+		return false
+	}
+
+	s := task.Schedule()
+	s.Attempts++
+
+	if p.MaxAttempts > 0 && s.Attempts >= p.MaxAttempts {
+		vlog.Errorf("%s exceeded max attempts, give up | key: %s | attempts: %d", p.name, key, s.Attempts)
+		p.remove(ctx, task)
+		return true
+	}
+
+	if err := p.schedule(ctx, task); err != nil {
+		vlog.Errorf("save %s error | key: %s | err: %v", p.name, key, err)
+	}
+
+	return false
+}
+
+func (p *Poller[T, P]) remove(ctx context.Context, task P) {
+	if err := p.Store.Remove(ctx, p.key(task)); err != nil {
+		vlog.Errorf("remove %s error | key: %s | err: %v", p.name, p.key(task), err)
+	}
+}
+
+// nextPollTime 退避间隔较长时, 在截止时间及时查询
+func (p *Poller[T, P]) nextPollTime(task P, next time.Time) time.Time {
+	if p.Deadline == nil {
+		return next
+	}
+
+	deadline := p.Deadline(task)
+	if !deadline.IsZero() && deadline.After(time.Now()) && next.After(deadline) {
+		return deadline
+	}
+
+	return next
+}
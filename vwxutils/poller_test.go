@@ -0,0 +1,68 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxutils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vogo/vogo/vsync/vrun"
+)
+
+type testPollTask struct {
+	PollSchedule
+	ID string `json:"id"`
+}
+
+func TestPollerRemoveDuringCheck(t *testing.T) {
+	ctx := context.Background()
+
+	var p *Poller[testPollTask, *testPollTask]
+	p = NewPoller[testPollTask]("test task", vrun.New(),
+		func(task *testPollTask) string { return task.ID },
+		func(ctx context.Context, task *testPollTask) bool {
+			// 模拟检查期间收到回调通知后取消跟踪
+			if task.ID == "T001" {
+				if err := p.Remove(ctx, task.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return false
+		})
+
+	for _, id := range []string{"T001", "T002"} {
+		if err := p.Schedule(ctx, &testPollTask{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p.Poll()
+
+	tasks, _ := p.Store.List(ctx)
+	if len(tasks) != 1 || tasks[0].ID != "T002" || tasks[0].Attempts != 1 {
+		t.Errorf("removed task should not be saved back: %+v", tasks)
+	}
+
+	// 删除标记仅作用于当次检查, 重新跟踪的任务照常轮询
+	if err := p.Schedule(ctx, &testPollTask{ID: "T001"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.checking) != 0 || len(p.removed) != 0 {
+		t.Errorf("check marks should be cleared: %v, %v", p.checking, p.removed)
+	}
+}
@@ -34,6 +34,9 @@ import (
 	"github.com/wechatpay-apiv3/wechatpay-go/utils"
 )
 
+// ChinaLocation 北京时间, 微信支付接口及账单中的日期和时间均按北京时间
+var ChinaLocation = time.FixedZone("CST", 8*60*60)
+
 // GetCertificateSerialNumber 获取证书序列号
 func GetCertificateSerialNumber(cert *x509.Certificate) string {
 	return utils.GetCertificateSerialNumber(*cert)
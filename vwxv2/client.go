@@ -0,0 +1,219 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxv2
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/vogo/vwechatpay"
+	"github.com/wechatpay-apiv3/wechatpay-go/utils"
+)
+
+const (
+	// DefaultBaseURL v2接口域名
+	DefaultBaseURL = "https://api.mch.weixin.qq.com"
+
+	micropayPath   = "/pay/micropay"
+	orderQueryPath = "/pay/orderquery"
+	reversePath    = "/secapi/pay/reverse"
+
+	defaultPollInterval   = 5 * time.Second
+	defaultPollTimeout    = 30 * time.Second
+	defaultReverseRetries = 3
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// Error v2接口返回的通信或业务错误
+type Error struct {
+	ReturnCode string `json:"return_code"`  // 通信标识
+	ReturnMsg  string `json:"return_msg"`   // 通信错误信息
+	ErrCode    string `json:"err_code"`     // 业务错误码
+	ErrCodeDes string `json:"err_code_des"` // 业务错误描述
+}
+
+func (e *Error) Error() string {
+	if e.ErrCode != "" {
+		return fmt.Sprintf("wechat pay v2 error: %s, %s", e.ErrCode, e.ErrCodeDes)
+	}
+	return fmt.Sprintf("wechat pay v2 error: %s, %s", e.ReturnCode, e.ReturnMsg)
+}
+
+// IsErrCode 判断错误是否为指定的v2业务错误码
+func IsErrCode(err error, code string) bool {
+	var e *Error
+	return errors.As(err, &e) && e.ErrCode == code
+}
+
+// MicropayClient 付款码支付客户端, 基于v2 XML接口
+type MicropayClient struct {
+	appID          string
+	mchID          string
+	apiKey         string
+	signType       SignType
+	baseURL        string
+	httpClient     *http.Client
+	certHTTPClient *http.Client // 携带商户API证书, 用于撤销订单等需要双向TLS认证的接口
+	pollInterval   time.Duration
+	pollTimeout    time.Duration
+	reverseRetries int
+}
+
+// MicropayOption 付款码支付客户端可选项
+type MicropayOption func(*MicropayClient)
+
+// WithSignType 设置签名类型, 默认为 HMAC-SHA256
+func WithSignType(signType SignType) MicropayOption {
+	return func(c *MicropayClient) { c.signType = signType }
+}
+
+// WithBaseURL 设置接口域名, 如备用域名 https://api2.mch.weixin.qq.com
+func WithBaseURL(baseURL string) MicropayOption {
+	return func(c *MicropayClient) { c.baseURL = baseURL }
+}
+
+// WithPollInterval 设置用户支付中时查询订单的间隔, 默认5秒
+func WithPollInterval(d time.Duration) MicropayOption {
+	return func(c *MicropayClient) { c.pollInterval = d }
+}
+
+// WithPollTimeout 设置等待用户支付的最长时间, 超时后撤销订单, 默认30秒
+func WithPollTimeout(d time.Duration) MicropayOption {
+	return func(c *MicropayClient) { c.pollTimeout = d }
+}
+
+// NewMicropayClient 创建付款码支付客户端
+// 使用配置中的商户号、默认AppID及APIv2密钥, 撤销订单时使用商户API证书进行双向TLS认证.
+func NewMicropayClient(mgr *vwechatpay.Manager, opts ...MicropayOption) (*MicropayClient, error) {
+	if mgr.Config.MerchantAPIv2Key == "" {
+		return nil, fmt.Errorf("merchant api v2 key is empty")
+	}
+
+	certHTTPClient := &http.Client{
+		Timeout: defaultRequestTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				Certificates: []tls.Certificate{mgr.TLSCertificate()},
+				MinVersion:   tls.VersionTLS12,
+			},
+		},
+	}
+
+	return newMicropayClient(mgr.Config.AppID, mgr.Config.MerchantID, mgr.Config.MerchantAPIv2Key,
+		&http.Client{Timeout: defaultRequestTimeout}, certHTTPClient, opts...), nil
+}
+
+func newMicropayClient(appID, mchID, apiKey string, httpClient, certHTTPClient *http.Client, opts ...MicropayOption) *MicropayClient {
+	c := &MicropayClient{
+		appID:          appID,
+		mchID:          mchID,
+		apiKey:         apiKey,
+		signType:       SignTypeHMACSHA256,
+		baseURL:        DefaultBaseURL,
+		httpClient:     httpClient,
+		certHTTPClient: certHTTPClient,
+		pollInterval:   defaultPollInterval,
+		pollTimeout:    defaultPollTimeout,
+		reverseRetries: defaultReverseRetries,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// post 签名并发送请求, 校验通信结果及响应签名, 业务结果由调用方处理
+func (c *MicropayClient) post(ctx context.Context, path string, params Params, withCert bool) (Params, error) {
+	nonce, err := utils.GenerateNonce()
+	if err != nil {
+		return nil, fmt.Errorf("generate nonce error: %w", err)
+	}
+
+	params.Set("mch_id", c.mchID)
+	params.Set("nonce_str", nonce)
+	params.Set("sign_type", string(c.signType))
+
+	sign, err := Sign(params, c.apiKey, c.signType)
+	if err != nil {
+		return nil, err
+	}
+	params["sign"] = sign
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(EncodeXML(params)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+
+	httpClient := c.httpClient
+	if withCert {
+		httpClient = c.certHTTPClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response error: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request %s failed, status code: %d", path, resp.StatusCode)
+	}
+
+	result, err := DecodeXML(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Get("return_code") != "SUCCESS" {
+		return nil, &Error{ReturnCode: result.Get("return_code"), ReturnMsg: result.Get("return_msg")}
+	}
+
+	if err := VerifySign(result, c.apiKey, c.signType); err != nil {
+		return nil, fmt.Errorf("verify response sign error: %w", err)
+	}
+
+	return result, nil
+}
+
+// resultError 业务结果失败时返回错误
+func resultError(result Params) error {
+	if result.Get("result_code") == "SUCCESS" {
+		return nil
+	}
+
+	return &Error{
+		ReturnCode: result.Get("return_code"),
+		ReturnMsg:  result.Get("return_msg"),
+		ErrCode:    result.Get("err_code"),
+		ErrCodeDes: result.Get("err_code_des"),
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxv2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+	"github.com/vogo/vwechatpay/vwxpayments"
+)
+
+// Micropay 付款码支付
+// 收银员扫描用户付款码后调用, 支付成功时返回订单信息;
+// 返回 USERPAYING、SYSTEMERROR、BANKERROR 错误时支付结果未知, 需查询订单确认, 可使用 MicropayAndWait 自动处理.
+func (c *MicropayClient) Micropay(ctx context.Context, req *MicropayRequest) (*Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.post(ctx, micropayPath, req.params(c.appID), false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resultError(result); err != nil {
+		return nil, err
+	}
+
+	order, err := orderFromParams(result)
+	if err != nil {
+		return nil, err
+	}
+
+	// 付款码支付成功时不返回交易状态
+	order.TradeState = vwxpayments.TradeStateSuccess
+
+	return order, nil
+}
+
+// MicropayAndWait 付款码支付并等待支付结果
+// 支付结果未知(如用户需要输入密码)时按间隔查询订单, 直至支付成功;
+// 支付失败或超过等待时间仍未支付时撤销订单, 并返回 ErrPayNotCompleted.
+func (c *MicropayClient) MicropayAndWait(ctx context.Context, req *MicropayRequest) (*Order, error) {
+	order, err := c.Micropay(ctx, req)
+	if err == nil {
+		return order, nil
+	}
+
+	if !payResultUnknown(err) {
+		return nil, err
+	}
+
+	vlog.Infof("micropay result unknown, waiting for user | out_trade_no: %s | err: %v", req.OutTradeNo, err)
+
+	deadline := time.Now().Add(c.pollTimeout)
+	timer := time.NewTimer(c.pollInterval)
+	defer timer.Stop()
+
+	state := vwxpayments.TradeStateUserPaying
+
+poll:
+	for {
+		select {
+		case <-ctx.Done():
+			break poll
+		case <-timer.C:
+		}
+
+		order, err = c.QueryOrder(ctx, req.OutTradeNo, req.SubMchID)
+		if err != nil {
+			vlog.Errorf("query micropay order error | out_trade_no: %s | err: %v", req.OutTradeNo, err)
+		} else {
+			state = order.TradeState
+			if state == vwxpayments.TradeStateSuccess {
+				return order, nil
+			}
+
+			if state != vwxpayments.TradeStateUserPaying {
+				break poll
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			break
+		}
+
+		timer.Reset(c.pollInterval)
+	}
+
+	// 调用方取消时也需要撤销订单, 避免用户随后完成支付
+	if err := c.reverseWithRetry(context.WithoutCancel(ctx), req.OutTradeNo, req.SubMchID); err != nil {
+		return nil, fmt.Errorf("reverse micropay order %s error: %w", req.OutTradeNo, err)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrPayNotCompleted, state)
+}
+
+// payResultUnknown 支付结果是否未知, 需要查询订单确认
+func payResultUnknown(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		// 网络异常等情况下无法确认支付结果
+		return true
+	}
+
+	switch e.ErrCode {
+	case ErrCodeUserPaying, ErrCodeSystemError, ErrCodeBankError:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxv2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpayments"
+)
+
+const testAPIKey = "192006250b4c09247ec02edce69f6a2d"
+
+func TestSign(t *testing.T) {
+	// 微信支付签名算法文档中的示例
+	params := Params{
+		"appid":       "wxd930ea5d5a258f4f",
+		"mch_id":      "10000100",
+		"device_info": "1000",
+		"body":        "test",
+		"nonce_str":   "ibuaiVcKdpRxkhJA",
+	}
+
+	sign, err := Sign(params, testAPIKey, SignTypeMD5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sign != "9A0A8659F005D6984697E2CA0A9CF3B7" {
+		t.Errorf("md5 sign = %s", sign)
+	}
+
+	sign, err = Sign(params, testAPIKey, SignTypeHMACSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sign != "6A9AE1657590FD6257D693A078E1C3E4BB6BA4DC30B23E0EE2496E54170DACD6" {
+		t.Errorf("hmac-sha256 sign = %s", sign)
+	}
+
+	params["sign"] = sign
+	if err := VerifySign(params, testAPIKey, SignTypeHMACSHA256); err != nil {
+		t.Errorf("verify sign error: %v", err)
+	}
+
+	params["body"] = "tampered"
+	if err := VerifySign(params, testAPIKey, SignTypeHMACSHA256); err == nil {
+		t.Errorf("expected sign mismatch")
+	}
+
+	// 应答声明的 sign_type 不能将签名类型降级为MD5
+	params["sign_type"] = string(SignTypeMD5)
+	if params["sign"], err = Sign(params, testAPIKey, SignTypeMD5); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySign(params, testAPIKey, SignTypeHMACSHA256); err == nil {
+		t.Errorf("expected md5 sign to be rejected")
+	}
+}
+
+func TestXML(t *testing.T) {
+	params := Params{"body": "a<b]]>c", "total_fee": "1"}
+
+	decoded, err := DecodeXML(EncodeXML(params))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded["body"] != "a<b]]>c" || decoded["total_fee"] != "1" {
+		t.Errorf("unexpected decoded params: %v", decoded)
+	}
+}
+
+// fakeServer 模拟v2接口, 按路径返回预设的业务参数
+type fakeServer struct {
+	mu        sync.Mutex
+	responses map[string][]Params
+	calls     map[string]int
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req, err := DecodeXML(body)
+	if err != nil || VerifySign(req, testAPIKey, SignTypeHMACSHA256) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[r.URL.Path]++
+	list := s.responses[r.URL.Path]
+	resp := list[0]
+	if len(list) > 1 {
+		s.responses[r.URL.Path] = list[1:]
+	}
+
+	result := Params{"return_code": "SUCCESS", "out_trade_no": req["out_trade_no"]}
+	for k, v := range resp {
+		result[k] = v
+	}
+	result["sign"], _ = Sign(result, testAPIKey, SignTypeHMACSHA256)
+
+	_, _ = w.Write(EncodeXML(result))
+}
+
+func newTestClient(t *testing.T, s *fakeServer) *MicropayClient {
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+
+	return newMicropayClient("wx-app", "1900000001", testAPIKey, server.Client(), server.Client(),
+		WithBaseURL(server.URL),
+		WithPollInterval(time.Millisecond),
+		WithPollTimeout(50*time.Millisecond),
+	)
+}
+
+func newTestRequest() *MicropayRequest {
+	return &MicropayRequest{
+		Body:           "store-goods",
+		OutTradeNo:     "T001",
+		TotalFee:       vwxmoney.Fen(100),
+		SpbillCreateIP: "127.0.0.1",
+		AuthCode:       "134567890123456789",
+	}
+}
+
+func TestMicropayAndWaitUserPaying(t *testing.T) {
+	s := &fakeServer{
+		calls: map[string]int{},
+		responses: map[string][]Params{
+			micropayPath: {{"result_code": "FAIL", "err_code": ErrCodeUserPaying}},
+			orderQueryPath: {
+				{"result_code": "SUCCESS", "trade_state": "USERPAYING"},
+				{"result_code": "SUCCESS", "trade_state": "SUCCESS", "transaction_id": "4200001", "total_fee": "100"},
+			},
+		},
+	}
+
+	order, err := newTestClient(t, s).MicropayAndWait(context.Background(), newTestRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.TradeState != vwxpayments.TradeStateSuccess || order.TransactionID != "4200001" || order.TotalFee.Fen() != 100 {
+		t.Errorf("unexpected order: %+v", order)
+	}
+	if s.calls[orderQueryPath] != 2 || s.calls[reversePath] != 0 {
+		t.Errorf("unexpected calls: %v", s.calls)
+	}
+}
+
+func TestMicropayAndWaitTimeoutReverse(t *testing.T) {
+	s := &fakeServer{
+		calls: map[string]int{},
+		responses: map[string][]Params{
+			micropayPath:   {{"result_code": "FAIL", "err_code": ErrCodeUserPaying}},
+			orderQueryPath: {{"result_code": "SUCCESS", "trade_state": "USERPAYING"}},
+			reversePath:    {{"result_code": "SUCCESS", "recall": "N"}},
+		},
+	}
+
+	_, err := newTestClient(t, s).MicropayAndWait(context.Background(), newTestRequest())
+	if !errors.Is(err, ErrPayNotCompleted) {
+		t.Fatalf("expected ErrPayNotCompleted, got %v", err)
+	}
+	if s.calls[reversePath] != 1 {
+		t.Errorf("expected order reversed once, calls: %v", s.calls)
+	}
+}
+
+func TestMicropayDefiniteFailure(t *testing.T) {
+	s := &fakeServer{
+		calls: map[string]int{},
+		responses: map[string][]Params{
+			micropayPath: {{"result_code": "FAIL", "err_code": "AUTHCODEEXPIRE", "err_code_des": "二维码已过期"}},
+		},
+	}
+
+	_, err := newTestClient(t, s).MicropayAndWait(context.Background(), newTestRequest())
+	if !IsErrCode(err, "AUTHCODEEXPIRE") || !strings.Contains(err.Error(), "二维码已过期") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.calls[orderQueryPath] != 0 {
+		t.Errorf("should not query order on definite failure, calls: %v", s.calls)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxv2
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+	"github.com/vogo/vwechatpay/vwxpayments"
+	"github.com/vogo/vwechatpay/vwxutils"
+)
+
+const (
+	// ErrCodeUserPaying 用户支付中, 需要输入密码
+	ErrCodeUserPaying = "USERPAYING"
+	// ErrCodeSystemError 系统超时, 支付结果未知
+	ErrCodeSystemError = "SYSTEMERROR"
+	// ErrCodeBankError 银行系统异常, 支付结果未知
+	ErrCodeBankError = "BANKERROR"
+	// ErrCodeOrderNotExist 订单不存在
+	ErrCodeOrderNotExist = "ORDERNOTEXIST"
+
+	// MaxBodyBytes 商品描述最大字节数
+	MaxBodyBytes = 128
+
+	timeLayout = "20060102150405"
+)
+
+// ErrPayNotCompleted 等待用户支付超时或支付失败, 订单已撤销
+var ErrPayNotCompleted = errors.New("付款码支付未完成, 订单已撤销")
+
+// MicropayRequest 付款码支付请求
+type MicropayRequest struct {
+	AppID          string         `json:"appid"`            // 应用ID, 为空时使用配置中的默认AppID
+	SubAppID       string         `json:"sub_appid"`        // 子商户应用ID, 仅服务商模式
+	SubMchID       string         `json:"sub_mch_id"`       // 子商户号, 仅服务商模式
+	DeviceInfo     string         `json:"device_info"`      // 终端设备号
+	Body           string         `json:"body"`             // 商品描述
+	Detail         string         `json:"detail"`           // 商品详情, JSON格式
+	Attach         string         `json:"attach"`           // 附加数据
+	OutTradeNo     string         `json:"out_trade_no"`     // 商户订单号
+	TotalFee       vwxmoney.Money `json:"total_fee"`        // 订单金额
+	SpbillCreateIP string         `json:"spbill_create_ip"` // 终端IP
+	GoodsTag       string         `json:"goods_tag"`        // 订单优惠标记
+	AuthCode       string         `json:"auth_code"`        // 付款码
+	TimeExpire     time.Time      `json:"time_expire"`      // 交易结束时间
+	ProfitSharing  bool           `json:"profit_sharing"`   // 是否需要分账
+}
+
+// Validate 在发起请求前校验付款码支付请求
+func (r *MicropayRequest) Validate() error {
+	if r.OutTradeNo == "" {
+		return fmt.Errorf("out_trade_no is empty")
+	}
+
+	if r.AuthCode == "" {
+		return fmt.Errorf("auth_code is empty")
+	}
+
+	if r.Body == "" {
+		return fmt.Errorf("body is empty")
+	}
+
+	if len(r.Body) > MaxBodyBytes {
+		return fmt.Errorf("body exceeds %d bytes: %d", MaxBodyBytes, len(r.Body))
+	}
+
+	if r.SpbillCreateIP == "" {
+		return fmt.Errorf("spbill_create_ip is empty")
+	}
+
+	if !r.TotalFee.IsPositive() {
+		return fmt.Errorf("total_fee must be greater than 0: %s", r.TotalFee)
+	}
+
+	return nil
+}
+
+// params 转换为v2接口请求参数
+func (r *MicropayRequest) params(defaultAppID string) Params {
+	p := make(Params)
+
+	appID := r.AppID
+	if appID == "" {
+		appID = defaultAppID
+	}
+
+	p.Set("appid", appID)
+	p.Set("sub_appid", r.SubAppID)
+	p.Set("sub_mch_id", r.SubMchID)
+	p.Set("device_info", r.DeviceInfo)
+	p.Set("body", r.Body)
+	p.Set("detail", r.Detail)
+	p.Set("attach", r.Attach)
+	p.Set("out_trade_no", r.OutTradeNo)
+	p.Set("total_fee", fmt.Sprint(r.TotalFee.Fen()))
+	p.Set("fee_type", r.TotalFee.Currency())
+	p.Set("spbill_create_ip", r.SpbillCreateIP)
+	p.Set("goods_tag", r.GoodsTag)
+	p.Set("auth_code", r.AuthCode)
+
+	if !r.TimeExpire.IsZero() {
+		p.Set("time_expire", r.TimeExpire.In(vwxutils.ChinaLocation).Format(timeLayout))
+	}
+
+	if r.ProfitSharing {
+		p.Set("profit_sharing", "Y")
+	}
+
+	return p
+}
+
+// Order 付款码支付订单信息
+type Order struct {
+	AppID          string                 `json:"appid"`            // 应用ID
+	MchID          string                 `json:"mch_id"`           // 商户号
+	SubAppID       string                 `json:"sub_appid"`        // 子商户应用ID
+	SubMchID       string                 `json:"sub_mch_id"`       // 子商户号
+	DeviceInfo     string                 `json:"device_info"`      // 终端设备号
+	OpenID         string                 `json:"openid"`           // 用户标识
+	SubOpenID      string                 `json:"sub_openid"`       // 子商户应用下的用户标识
+	TradeType      string                 `json:"trade_type"`       // 交易类型, 付款码支付为 MICROPAY
+	TradeState     vwxpayments.TradeState `json:"trade_state"`      // 交易状态
+	TradeStateDesc string                 `json:"trade_state_desc"` // 交易状态描述
+	BankType       string                 `json:"bank_type"`        // 付款银行
+	TotalFee       vwxmoney.Money         `json:"total_fee"`        // 订单金额
+	CashFee        vwxmoney.Money         `json:"cash_fee"`         // 现金支付金额
+	TransactionID  string                 `json:"transaction_id"`   // 微信支付订单号
+	OutTradeNo     string                 `json:"out_trade_no"`     // 商户订单号
+	Attach         string                 `json:"attach"`           // 附加数据
+	TimeEnd        string                 `json:"time_end"`         // 支付完成时间, 格式为 yyyyMMddHHmmss
+}
+
+// orderFromParams 从v2接口响应中解析订单信息
+func orderFromParams(p Params) (*Order, error) {
+	totalFee, err := p.Money("total_fee", "fee_type")
+	if err != nil {
+		return nil, err
+	}
+
+	cashFee, err := p.Money("cash_fee", "cash_fee_type")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Order{
+		AppID:          p.Get("appid"),
+		MchID:          p.Get("mch_id"),
+		SubAppID:       p.Get("sub_appid"),
+		SubMchID:       p.Get("sub_mch_id"),
+		DeviceInfo:     p.Get("device_info"),
+		OpenID:         p.Get("openid"),
+		SubOpenID:      p.Get("sub_openid"),
+		TradeType:      p.Get("trade_type"),
+		TradeState:     vwxpayments.TradeState(p.Get("trade_state")),
+		TradeStateDesc: p.Get("trade_state_desc"),
+		BankType:       p.Get("bank_type"),
+		TotalFee:       totalFee,
+		CashFee:        cashFee,
+		TransactionID:  p.Get("transaction_id"),
+		OutTradeNo:     p.Get("out_trade_no"),
+		Attach:         p.Get("attach"),
+		TimeEnd:        p.Get("time_end"),
+	}, nil
+}
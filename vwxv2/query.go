@@ -0,0 +1,57 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxv2
+
+import (
+	"context"
+	"fmt"
+)
+
+// QueryOrder 根据商户订单号查询付款码支付订单
+// subMchID: 子商户号, 服务商模式下使用
+func (c *MicropayClient) QueryOrder(ctx context.Context, outTradeNo, subMchID string) (*Order, error) {
+	return c.queryOrder(ctx, "out_trade_no", outTradeNo, subMchID)
+}
+
+// QueryOrderByTransactionID 根据微信支付订单号查询付款码支付订单
+// subMchID: 子商户号, 服务商模式下使用
+func (c *MicropayClient) QueryOrderByTransactionID(ctx context.Context, transactionID, subMchID string) (*Order, error) {
+	return c.queryOrder(ctx, "transaction_id", transactionID, subMchID)
+}
+
+func (c *MicropayClient) queryOrder(ctx context.Context, key, value, subMchID string) (*Order, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%s is empty", key)
+	}
+
+	params := make(Params)
+	params.Set("appid", c.appID)
+	params.Set("sub_mch_id", subMchID)
+	params.Set(key, value)
+
+	result, err := c.post(ctx, orderQueryPath, params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resultError(result); err != nil {
+		return nil, err
+	}
+
+	return orderFromParams(result)
+}
@@ -0,0 +1,75 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxv2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vogo/vogo/vlog"
+)
+
+// Reverse 撤销订单
+// 支付超时或失败时调用, 已支付的订单会被退款, 未支付的订单会被关闭, 需要商户API证书.
+// 返回的 recall 为 true 时表示需要重新调用撤销.
+// subMchID: 子商户号, 服务商模式下使用
+func (c *MicropayClient) Reverse(ctx context.Context, outTradeNo, subMchID string) (recall bool, err error) {
+	if outTradeNo == "" {
+		return false, fmt.Errorf("out_trade_no is empty")
+	}
+
+	params := make(Params)
+	params.Set("appid", c.appID)
+	params.Set("sub_mch_id", subMchID)
+	params.Set("out_trade_no", outTradeNo)
+
+	result, err := c.post(ctx, reversePath, params, true)
+	if err != nil {
+		return false, err
+	}
+
+	return result.Get("recall") == "Y", resultError(result)
+}
+
+// reverseWithRetry 撤销订单, 需要重新调用时按查询间隔重试
+func (c *MicropayClient) reverseWithRetry(ctx context.Context, outTradeNo, subMchID string) error {
+	var (
+		recall bool
+		err    error
+	)
+
+	for i := 0; i < c.reverseRetries; i++ {
+		if i > 0 {
+			time.Sleep(c.pollInterval)
+		}
+
+		recall, err = c.Reverse(ctx, outTradeNo, subMchID)
+		if !recall {
+			return err
+		}
+
+		vlog.Warnf("reverse micropay order need recall | out_trade_no: %s | err: %v", outTradeNo, err)
+	}
+
+	if err == nil {
+		err = fmt.Errorf("reverse still need recall after %d retries", c.reverseRetries)
+	}
+
+	return err
+}
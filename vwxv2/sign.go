@@ -0,0 +1,95 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vwxv2
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+)
+
+// SignType v2接口签名类型
+type SignType string
+
+const (
+	SignTypeMD5        SignType = "MD5"
+	SignTypeHMACSHA256 SignType = "HMAC-SHA256"
+)
+
+// Sign 计算v2接口签名
+// 参数按名称字典序排列, 跳过空值和sign字段, 拼接为 key1=value1&key2=value2 后追加 &key=APIv2密钥,
+// 再使用MD5或HMAC-SHA256计算摘要并转为大写.
+func Sign(params Params, apiKey string, signType SignType) (string, error) {
+	keys := make([]string, 0, len(params))
+	for k, v := range params {
+		if k == "sign" || v == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(params[k])
+		sb.WriteByte('&')
+	}
+	sb.WriteString("key=")
+	sb.WriteString(apiKey)
+
+	var h hash.Hash
+	switch signType {
+	case SignTypeMD5, "":
+		h = md5.New()
+	case SignTypeHMACSHA256:
+		h = hmac.New(sha256.New, []byte(apiKey))
+	default:
+		return "", fmt.Errorf("unsupported sign type: %s", signType)
+	}
+
+	h.Write([]byte(sb.String()))
+
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// VerifySign 校验v2接口签名, 始终使用调用方指定的 signType, 不采用参数中的 sign_type, 避免应答将签名类型降级为MD5
+func VerifySign(params Params, apiKey string, signType SignType) error {
+	sign := params.Get("sign")
+	if sign == "" {
+		return fmt.Errorf("sign is empty")
+	}
+
+	expected, err := Sign(params, apiKey, signType)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToUpper(sign))) != 1 {
+		return fmt.Errorf("sign mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package vwxv2 微信支付v2(XML)接口, 目前用于v3尚未提供的付款码支付.
+package vwxv2
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/vogo/vwechatpay/vwxmoney"
+)
+
+// Params v2接口的请求/响应参数
+type Params map[string]string
+
+// Get 获取参数值, 不存在时返回空字符串
+func (p Params) Get(key string) string {
+	return p[key]
+}
+
+// Set 设置参数值, 值为空时忽略
+func (p Params) Set(key, value string) {
+	if value != "" {
+		p[key] = value
+	}
+}
+
+// Money 以分为单位解析金额参数, fee_type 为空时默认为人民币
+func (p Params) Money(key, feeTypeKey string) (vwxmoney.Money, error) {
+	v := p[key]
+	if v == "" {
+		return vwxmoney.Money{}, nil
+	}
+
+	fen, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return vwxmoney.Money{}, fmt.Errorf("invalid %s: %s", key, v)
+	}
+
+	return vwxmoney.New(fen, p[feeTypeKey]), nil
+}
+
+// EncodeXML 将参数编码为v2接口的XML格式, 字段按名称排序, 值使用CDATA包裹
+func EncodeXML(params Params) []byte {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("<xml>")
+	for _, k := range keys {
+		buf.WriteString("<" + k + "><![CDATA[")
+		// CDATA 中不能包含结束标记, 拆分为两个CDATA段
+		buf.Write(bytes.ReplaceAll([]byte(params[k]), []byte("]]>"), []byte("]]]]><![CDATA[>")))
+		buf.WriteString("]]></" + k + ">")
+	}
+	buf.WriteString("</xml>")
+
+	return buf.Bytes()
+}
+
+// DecodeXML 解析v2接口返回的XML, 仅读取根节点下的一级字段
+func DecodeXML(data []byte) (Params, error) {
+	params := make(Params)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var (
+		depth int
+		key   string
+		value bytes.Buffer
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode xml error: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 {
+				key = t.Name.Local
+				value.Reset()
+			}
+		case xml.CharData:
+			if depth == 2 {
+				value.Write(t)
+			}
+		case xml.EndElement:
+			if depth == 2 {
+				params[key] = value.String()
+			}
+			depth--
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("decode xml error: unexpected end of document")
+	}
+
+	return params, nil
+}